@@ -3,7 +3,6 @@ package app
 import (
 	"bufio"
 	"crypto/rand"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,11 +13,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
-	"cc-notify/internal/config"
 	"cc-notify/internal/event"
+	"cc-notify/internal/i18n"
 	"cc-notify/internal/notifier"
+	"cc-notify/internal/source"
 )
 
 // Options controls runtime dependencies for App.
@@ -30,11 +32,15 @@ type Options struct {
 	Stderr           io.Writer
 	ConfigPath       func() (string, error)
 	ClaudeConfigPath func() (string, error)
-	SettingsPath     func() (string, error)
-	Executable       func() (string, error)
-	ReadFile         func(string) ([]byte, error)
-	WriteFile        func(string, []byte, fs.FileMode) error
-	MkdirAll         func(string, fs.FileMode) error
+	// SourceConfigPaths overrides the config path of agents by adapter
+	// name; ConfigPath and ClaudeConfigPath take precedence for Codex and
+	// Claude Code.
+	SourceConfigPaths map[string]func() (string, error)
+	SettingsPath      func() (string, error)
+	Executable        func() (string, error)
+	ReadFile          func(string) ([]byte, error)
+	WriteFile         func(string, []byte, fs.FileMode) error
+	MkdirAll          func(string, fs.FileMode) error
 }
 
 // App is the CLI command dispatcher.
@@ -45,13 +51,15 @@ type App struct {
 	stdin            io.Reader
 	stdout           io.Writer
 	stderr           io.Writer
-	configPath       func() (string, error)
-	claudeConfigPath func() (string, error)
+	configPaths      map[string]func() (string, error)
 	settingsPath     func() (string, error)
 	executable       func() (string, error)
 	readFile         func(string) ([]byte, error)
 	writeFile        func(string, []byte, fs.FileMode) error
 	mkdirAll         func(string, fs.FileMode) error
+
+	// respondMu serializes approval responses arriving from remote channels.
+	respondMu sync.Mutex
 }
 
 // New builds an App with defaults.
@@ -73,11 +81,15 @@ func New(opts Options) *App {
 	if opts.Stderr == nil {
 		opts.Stderr = os.Stderr
 	}
-	if opts.ConfigPath == nil {
-		opts.ConfigPath = config.DefaultPath
+	configPaths := map[string]func() (string, error){}
+	for name, path := range opts.SourceConfigPaths {
+		configPaths[name] = path
+	}
+	if opts.ConfigPath != nil {
+		configPaths["codex"] = opts.ConfigPath
 	}
-	if opts.ClaudeConfigPath == nil {
-		opts.ClaudeConfigPath = config.ClaudeDefaultPath
+	if opts.ClaudeConfigPath != nil {
+		configPaths["claude"] = opts.ClaudeConfigPath
 	}
 	if opts.SettingsPath == nil {
 		opts.SettingsPath = defaultSettingsPath
@@ -102,8 +114,7 @@ func New(opts Options) *App {
 		stdin:            opts.Stdin,
 		stdout:           opts.Stdout,
 		stderr:           opts.Stderr,
-		configPath:       opts.ConfigPath,
-		claudeConfigPath: opts.ClaudeConfigPath,
+		configPaths:      configPaths,
 		settingsPath:     opts.SettingsPath,
 		executable:       opts.Executable,
 		readFile:         opts.ReadFile,
@@ -131,6 +142,15 @@ func (a *App) Run(args []string) int {
 		return 0
 	}
 
+	if args[0] == "run" {
+		// run exits with the code of the command it wrapped.
+		code, err := a.runCommand(args[1:])
+		if err != nil {
+			fmt.Fprintf(a.stderr, "error: %v\n", err)
+		}
+		return code
+	}
+
 	var err error
 	switch args[0] {
 	case "install":
@@ -141,10 +161,15 @@ func (a *App) Run(args []string) int {
 		err = a.runNotify(args[1:])
 	case "respond":
 		err = a.runRespond(args[1:])
+	case "serve":
+		err = a.runServe(args[1:])
 	case "test-notify":
 		err = a.runTestNotify(args[1:])
 	case "test-toast":
 		err = a.runTestToast(args[1:])
+	case notifier.ListenArg:
+		// The listener of a Linux desktop prompt answers it from here.
+		err = notifier.RunActionListener(a.stdin, a.stdout, a.runProtocolURI)
 	case "help", "-h", "--help":
 		a.printUsage()
 		return 0
@@ -165,7 +190,7 @@ func (a *App) runInstall(args []string) error {
 		target = args[0]
 	}
 	if len(args) > 1 {
-		return fmt.Errorf("install accepts at most 1 argument (codex, claude, or empty for both)")
+		return fmt.Errorf("install accepts at most 1 argument (%s, or empty for all)", strings.Join(source.Names(), ", "))
 	}
 
 	exePath, err := a.executable()
@@ -181,22 +206,24 @@ func (a *App) runInstall(args []string) error {
 
 	switch target {
 	case "", "all":
-		if err := a.installCodex(exePath); err != nil {
-			fmt.Fprintf(a.stderr, "  codex install: %v\n", err)
-		}
-		if err := a.installClaude(exePath); err != nil {
-			fmt.Fprintf(a.stderr, "  claude install: %v\n", err)
+		// Agents that may not be installed are only set up when their
+		// config shows they are.
+		for _, src := range source.All() {
+			if !a.sourceDetected(src) {
+				continue
+			}
+			if err := a.installSource(src, exePath); err != nil {
+				fmt.Fprintf(a.stderr, "  %s install: %v\n", src.Name(), err)
+			}
 		}
-	case "codex":
-		if err := a.installCodex(exePath); err != nil {
-			return err
+	default:
+		src, ok := source.Lookup(target)
+		if !ok {
+			return fmt.Errorf("unknown install target: %s (use %s, or leave empty for all)", target, strings.Join(source.Names(), ", "))
 		}
-	case "claude":
-		if err := a.installClaude(exePath); err != nil {
+		if err := a.installSource(src, exePath); err != nil {
 			return err
 		}
-	default:
-		return fmt.Errorf("unknown install target: %s (use codex, claude, or leave empty for both)", target)
 	}
 
 	// Ensure Windows toast shortcut and URI protocol are set up so that
@@ -217,63 +244,58 @@ func (a *App) runInstall(args []string) error {
 	return nil
 }
 
-func (a *App) installCodex(exePath string) error {
-	cfgPath, err := a.configPath()
-	if err != nil {
-		return err
-	}
-
-	content, err := a.readFile(cfgPath)
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("read config: %w", err)
-	}
-
-	updated, changed, err := config.UpsertNotify(string(content), []string{exePath, "notify"})
-	if err != nil {
-		return err
-	}
-	if !changed {
-		fmt.Fprintln(a.stdout, "codex: notify command already configured")
-		return nil
+// sourceConfigPath returns where the hook of src is configured.
+func (a *App) sourceConfigPath(src source.Adapter) (string, error) {
+	if path, ok := a.configPaths[src.Name()]; ok {
+		return path()
 	}
+	return src.ConfigPath()
+}
 
-	if err := a.mkdirAll(filepath.Dir(cfgPath), 0o755); err != nil {
-		return fmt.Errorf("create config directory: %w", err)
-	}
-	if err := a.writeFile(cfgPath, []byte(updated), 0o644); err != nil {
-		return fmt.Errorf("write config: %w", err)
+// sourceDetected reports whether a bare install or uninstall covers src.
+func (a *App) sourceDetected(src source.Adapter) bool {
+	detector, ok := src.(source.Detector)
+	if !ok {
+		return true
 	}
-	fmt.Fprintf(a.stdout, "codex: installed notify command in %s\n", cfgPath)
-	return nil
+	cfgPath, err := a.sourceConfigPath(src)
+	return err == nil && detector.Detected(cfgPath)
 }
 
-func (a *App) installClaude(exePath string) error {
-	cfgPath, err := a.claudeConfigPath()
+func (a *App) installSource(src source.Adapter, exePath string) error {
+	name := src.Name()
+	cfgPath, err := a.sourceConfigPath(src)
 	if err != nil {
 		return err
 	}
 
 	content, err := a.readFile(cfgPath)
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("read claude settings: %w", err)
+		return fmt.Errorf("read %s config: %w", name, err)
 	}
 
-	updated, changed, err := config.ClaudeUpsertHook(string(content), exePath)
+	prefs, _, _ := a.loadPreferences()
+	updated, changed, err := src.Install(string(content), source.InstallOptions{
+		ExePath:         exePath,
+		Events:          prefs.Tools[name].Events,
+		TurnStart:       prefs.IncludeChanges,
+		ApprovalTimeout: prefs.ClaudeApproval.timeout() + approvalHookMargin,
+	})
 	if err != nil {
 		return err
 	}
 	if !changed {
-		fmt.Fprintln(a.stdout, "claude: hook already configured")
+		fmt.Fprintf(a.stdout, "%s: %s already configured\n", name, src.HookName())
 		return nil
 	}
 
 	if err := a.mkdirAll(filepath.Dir(cfgPath), 0o755); err != nil {
-		return fmt.Errorf("create claude config directory: %w", err)
+		return fmt.Errorf("create %s config directory: %w", name, err)
 	}
 	if err := a.writeFile(cfgPath, []byte(updated), 0o644); err != nil {
-		return fmt.Errorf("write claude settings: %w", err)
+		return fmt.Errorf("write %s config: %w", name, err)
 	}
-	fmt.Fprintf(a.stdout, "claude: installed hook in %s\n", cfgPath)
+	fmt.Fprintf(a.stdout, "%s: installed %s in %s\n", name, src.HookName(), cfgPath)
 	return nil
 }
 
@@ -283,29 +305,32 @@ func (a *App) runUninstall(args []string) error {
 		target = args[0]
 	}
 	if len(args) > 1 {
-		return fmt.Errorf("uninstall accepts at most 1 argument (codex, claude, or empty for both)")
+		return fmt.Errorf("uninstall accepts at most 1 argument (%s, or empty for all)", strings.Join(source.Names(), ", "))
 	}
 
 	switch target {
 	case "", "all":
-		if err := a.uninstallCodex(); err != nil {
-			fmt.Fprintf(a.stderr, "  codex uninstall: %v\n", err)
-		}
-		if err := a.uninstallClaude(); err != nil {
-			fmt.Fprintf(a.stderr, "  claude uninstall: %v\n", err)
+		for _, src := range source.All() {
+			if !a.sourceDetected(src) {
+				continue
+			}
+			if err := a.uninstallSource(src); err != nil {
+				fmt.Fprintf(a.stderr, "  %s uninstall: %v\n", src.Name(), err)
+			}
 		}
 		return nil
-	case "codex":
-		return a.uninstallCodex()
-	case "claude":
-		return a.uninstallClaude()
 	default:
-		return fmt.Errorf("unknown uninstall target: %s (use codex, claude, or leave empty for both)", target)
+		src, ok := source.Lookup(target)
+		if !ok {
+			return fmt.Errorf("unknown uninstall target: %s (use %s, or leave empty for all)", target, strings.Join(source.Names(), ", "))
+		}
+		return a.uninstallSource(src)
 	}
 }
 
-func (a *App) uninstallCodex() error {
-	cfgPath, err := a.configPath()
+func (a *App) uninstallSource(src source.Adapter) error {
+	name := src.Name()
+	cfgPath, err := a.sourceConfigPath(src)
 	if err != nil {
 		return err
 	}
@@ -313,171 +338,293 @@ func (a *App) uninstallCodex() error {
 	content, err := a.readFile(cfgPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			fmt.Fprintln(a.stdout, "codex: config file not found, nothing to uninstall")
+			fmt.Fprintf(a.stdout, "%s: config file not found, nothing to uninstall\n", name)
 			return nil
 		}
-		return fmt.Errorf("read config: %w", err)
+		return fmt.Errorf("read %s config: %w", name, err)
 	}
 
-	updated, changed, err := config.RemoveNotify(string(content))
+	updated, changed, err := src.Uninstall(string(content))
 	if err != nil {
 		return err
 	}
 	if !changed {
-		fmt.Fprintln(a.stdout, "codex: notify command not configured")
+		fmt.Fprintf(a.stdout, "%s: %s not configured\n", name, src.HookName())
 		return nil
 	}
 
 	if err := a.writeFile(cfgPath, []byte(updated), 0o644); err != nil {
-		return fmt.Errorf("write config: %w", err)
+		return fmt.Errorf("write %s config: %w", name, err)
 	}
-	fmt.Fprintf(a.stdout, "codex: removed notify command from %s\n", cfgPath)
+	fmt.Fprintf(a.stdout, "%s: removed %s from %s\n", name, src.HookName(), cfgPath)
 	return nil
 }
 
-func (a *App) uninstallClaude() error {
-	cfgPath, err := a.claudeConfigPath()
-	if err != nil {
-		return err
+func (a *App) runNotify(args []string) error {
+	// notify --<agent> names the agent; without it the payload is Codex's.
+	src := source.Default()
+	if len(args) > 0 {
+		if name, ok := strings.CutPrefix(args[0], "--"); ok {
+			if named, ok := source.Lookup(name); ok {
+				src, args = named, args[1:]
+			}
+		}
 	}
 
-	content, err := a.readFile(cfgPath)
+	payload, err := src.ParsePayload(source.Input{
+		Args:     args,
+		Stdin:    a.stdin,
+		ReadFile: a.readFile,
+		Getwd:    os.Getwd,
+		Warnings: a.stderr,
+	})
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			fmt.Fprintln(a.stdout, "claude: settings file not found, nothing to uninstall")
-			return nil
-		}
-		return fmt.Errorf("read claude settings: %w", err)
+		return err
 	}
 
-	updated, changed, err := config.ClaudeRemoveHook(string(content))
+	prefs, _, err := a.loadPreferences()
 	if err != nil {
 		return err
 	}
-	if !changed {
-		fmt.Fprintln(a.stdout, "claude: hook not configured")
-		return nil
-	}
 
-	if err := a.writeFile(cfgPath, []byte(updated), 0o644); err != nil {
-		return fmt.Errorf("write claude settings: %w", err)
+	payload.Type = src.NormalizeEvent(payload.Type)
+	// A PreToolUse hook that waits for the decision asks like a
+	// permission request.
+	if payload.HookEvent == event.ClaudePreToolUse && prefs.ClaudeApproval.waitsFor(payload.Tool) {
+		payload.Type = "agent-turn-paused"
 	}
-	fmt.Fprintf(a.stdout, "claude: removed hook from %s\n", cfgPath)
-	return nil
-}
 
-func (a *App) runNotify(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("notify payload argument is required")
+	sourceName := src.Name()
+	diag := a.diagnostics(sourceName)
+	enabled, mode, content := prefs.ToolPrefs(sourceName)
+	if !enabled {
+		fmt.Fprintf(diag, "notifications disabled for %s\n", sourceName)
+		return nil
 	}
 
-	var raw string
-	var err error
-	source := "codex"
-
-	if args[0] == "--claude" {
-		source = "claude"
-		raw, err = a.readClaudeHookInput()
-	} else {
-		raw, err = a.resolveNotifyPayload(args)
-	}
+	opts, err := prefs.withTemplates(prefs.renderOptions(content), sourceName, payload.Type)
 	if err != nil {
-		return err
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
 	}
-
-	payload, err := event.ParsePayload(raw)
-	if err != nil {
-		return err
+	opts.Title = source.Title(src, payload.Type)
+	if opts.IncludeChanges && !a.trackChanges(prefs, src, &payload) {
+		fmt.Fprintf(diag, "turn start recorded for %s\n", sourceName)
+		return nil
 	}
-
-	prefs, _, err := a.loadPreferences()
-	if err != nil {
-		return err
+	if opts.WantsStats() && payload.Type == "agent-turn-complete" && payload.TranscriptPath != "" {
+		a.attachTurnStats(&payload, prefs.Prices)
 	}
+	return a.deliver(prefs, sourceName, payload, opts, mode, content, diag)
+}
 
-	payload.Type = normalizeClaudePaused(source, payload.Type)
-
-	enabled, mode, content := prefs.ToolPrefs(source)
-	if !enabled {
-		fmt.Fprintf(a.stdout, "notifications disabled for %s\n", source)
-		return nil
+// deliver renders payload and sends it to the desktop and the channels
+// routed to it, or shows the approval prompt of a paused agent.
+func (a *App) deliver(prefs Preferences, sourceName string, payload event.Payload, opts event.RenderOptions, mode, content string, diag io.Writer) error {
+	if opts.NeedsGit() {
+		a.attachGitInfo(&payload, opts.NeedsGitStatus())
 	}
-
-	title, body, ok := event.RenderNotificationWithOptions(payload, event.RenderOptions{
-		ContentMode:  event.ContentMode(content),
-		IncludeDir:   prefs.IncludeDir,
-		IncludeModel: prefs.IncludeModel,
-		IncludeEvent: prefs.IncludeEvent,
-	})
+	title, body, ok := event.RenderNotificationWithOptions(payload, opts)
 	if !ok {
-		fmt.Fprintf(a.stdout, "ignored event type: %s\n", payload.Type)
+		fmt.Fprintf(diag, "ignored event type: %s\n", payload.Type)
 		return nil
 	}
 
-	service := a.notifier
-	if a.defaultNotifier {
-		service = notifier.NewWithConfig(notifier.Config{
-			Mode:       mode,
-			ToastAppID: prefs.ToastAppID,
-		})
+	desktopCfg, desktopOn, desktopErr := prefs.desktopConfig(notifier.Config{
+		Mode:          mode,
+		ToastAppID:    prefs.ToastAppID,
+		TerminalStyle: prefs.TerminalStyle,
+		TerminalPID:   os.Getppid(),
+		Toast:         prefs.toastOptions(),
+	})
+	var service notifier.Service
+	if desktopOn {
+		service = a.notifier
+		if a.defaultNotifier {
+			service = notifier.NewWithConfig(desktopCfg)
+		}
 	}
 
-	switch payload.Type {
-	case "agent-turn-paused":
-		return a.handlePauseEvent(payload, title, body, prefs, source)
+	// Rich channels get the full agent message unless the user asked for
+	// minimal content.
+	fullText := ""
+	if event.ContentMode(content) != event.ContentModeComplete {
+		fullText = firstNonEmptyString(payload.LastAssistantMessage, payload.Summary)
+	}
+	msg := notifier.Message{
+		Title:          title,
+		Body:           body,
+		Text:           fullText,
+		Source:         sourceName,
+		EventType:      payload.Type,
+		CWD:            payload.CWD,
+		Model:          payload.Model,
+		TranscriptPath: payload.TranscriptPath,
+		Tag:            sessionTag(sourceName, payload),
+		Group:          notifier.ProjectGroup(payload.CWD),
+		Language:       prefs.lang(),
+	}
+
+	render := func(maxLength int, scrub func(string) string) (string, string) {
+		opts := opts
+		if maxLength > 0 {
+			opts.MaxLength = maxLength
+		}
+		payload := payload
+		payload.Summary = scrub(payload.Summary)
+		payload.LastAssistantMessage = scrub(payload.LastAssistantMessage)
+		payload.LastUserPrompt = scrub(payload.LastUserPrompt)
+		payload.Command = scrub(payload.Command)
+		title, body, _ := event.RenderNotificationWithOptions(payload, opts)
+		return title, body
+	}
+
+	switch {
+	case waitsForDecision(payload):
+		return a.handleHookApproval(payload, msg, prefs, render)
+	case payload.Type == "agent-turn-paused":
+		return a.handlePauseEvent(payload, msg, prefs, render)
 	default:
-		return a.defaultNotify(title, body, service, payload.Type, source)
+		_, err := a.dispatch(prefs, service, msg, render)
+		return errors.Join(desktopErr, err)
 	}
 }
 
-func (a *App) handlePauseEvent(payload event.Payload, title, body string, prefs Preferences, source string) error {
-	parentPID := os.Getppid()
+// attachTurnStats reads the statistics of the finished turn from the
+// transcript of payload. A transcript that cannot be read only costs the
+// statistics line.
+func (a *App) attachTurnStats(payload *event.Payload, prices event.PriceTable) {
+	stats, err := event.ReadTurnStats(payload.TranscriptPath)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+		return
+	}
+	if cost, ok := prices.Cost(firstNonEmptyString(stats.Model, payload.Model), stats.Tokens); ok {
+		stats.Cost = cost
+	}
+	payload.Stats = &stats
+}
 
-	notifierMode := notifier.Config{Mode: "popup", ToastAppID: prefs.ToastAppID}
-	switch prefs.PausePrompt {
-	case "toast":
-		notifierMode.Mode = "toast"
-	case "terminal":
-		return a.promptPauseInTerminal(payload, parentPID)
+// attachGitInfo reads the repository at the directory of payload. The
+// working tree is only scanned with status; a repository that cannot be
+// read only costs the git fields.
+func (a *App) attachGitInfo(payload *event.Payload, status bool) {
+	info, err := event.ReadGitInfo(payload.CWD, status)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
 	}
+	payload.Git = info
+}
 
-	actionService := a.notifier
-	if a.defaultNotifier {
-		actionService = notifier.NewWithConfig(notifierMode)
+// diagnostics returns where notify reports what it did for the named
+// source. Agents that read the stdout of their hooks, such as Claude Code,
+// get the report on stderr, and so does `cc-notify run`, whose stdout
+// belongs to the command.
+func (a *App) diagnostics(name string) io.Writer {
+	if name == runSource {
+		return a.stderr
+	}
+	if src, ok := source.Lookup(name); ok && source.ReadsStdout(src) {
+		return a.stderr
 	}
+	return a.stdout
+}
 
-	svc, ok := actionService.(notifier.ActionService)
-	if !ok {
-		return a.promptPauseInTerminal(payload, parentPID)
+// handlePauseEvent shows the approval prompt on the desktop and every routed
+// remote channel at once. With the desktop turned off in the channel list,
+// the terminal prompt is the fallback when no remote channel took it.
+func (a *App) handlePauseEvent(payload event.Payload, msg notifier.Message, prefs Preferences, render bodyRenderer) error {
+	parentPID := os.Getppid()
+
+	desktop, actions, desktopErr := a.pauseNotifier(prefs)
+	if !a.canDeliverApproval(parentPID) {
+		// Buttons that cannot answer the agent are not offered.
+		_, err := a.dispatch(prefs, desktop, msg, render)
+		return errors.Join(desktopErr, err)
+	}
+	if prefs.PausePrompt == "terminal" || (desktop != nil && !actions) {
+		return errors.Join(desktopErr, a.promptPauseInTerminalWithRemote(payload, msg, prefs, render, parentPID))
 	}
 
-	pending, err := a.createPendingApproval(parentPID)
+	pending, err := a.createPendingApproval(parentPID, msg.Tag, msg.Group, 0)
 	if err != nil {
 		return fmt.Errorf("create pending approval: %w", err)
 	}
 
-	actions := buildPausedActions(payload.Summary, pending.ID)
-	if err := svc.NotifyWithActions(title, body, actions); err != nil {
+	// Action labels quote the command, shortened; the secrets in it are
+	// hidden first, for the strictest channel as every one shows them.
+	redactor, localLevel, remoteLevel, _ := prefs.redaction()
+	summary := redactor.Redact(payload.Summary, max(localLevel, remoteLevel))
+	msg.Actions = buildPausedActions(prefs.lang(), summary, pending.ID)
+	msg.Pending, _ = a.pendingApprovalPath(pending.ID)
+	sent, err := a.dispatch(prefs, desktop, msg, render)
+	if sent == 0 {
 		_ = a.deletePendingApproval(pending.ID)
-		return err
+		if desktop == nil {
+			err = errors.Join(err, a.promptPauseInTerminal(prefs.lang(), payload, parentPID))
+		}
 	}
-	fmt.Fprintf(a.stdout, "approval prompt sent: %s (%s)\n", payload.Type, source)
-	return nil
+	return errors.Join(desktopErr, err)
+}
+
+// pauseNotifier returns the desktop notifier of approval prompts, nil with
+// the desktop turned off, and whether it can show actions.
+func (a *App) pauseNotifier(prefs Preferences) (service notifier.Service, actions bool, err error) {
+	cfg, desktopOn, err := prefs.desktopConfig(notifier.Config{
+		ToastAppID:    prefs.ToastAppID,
+		ActionHandler: a.runProtocolURI,
+		Toast:         prefs.toastOptions(),
+	})
+	cfg.Mode = "popup"
+	if prefs.PausePrompt == "toast" {
+		cfg.Mode = "toast"
+	}
+	if !desktopOn {
+		return nil, false, err
+	}
+	service = a.notifier
+	if a.defaultNotifier {
+		service = notifier.NewWithConfig(cfg)
+	}
+	_, actions = service.(notifier.ActionService)
+	// Buttons whose clicks end up with another install are left out.
+	if actions && a.defaultNotifier {
+		actions = desktopActionsReachUs()
+	}
+	return service, actions, err
+}
+
+// sessionTag keys notifications by agent session so a session keeps a single
+// toast. Without a session ID or transcript the project directory stands in.
+func sessionTag(source string, payload event.Payload) string {
+	if key := firstNonEmptyString(payload.SessionID, payload.TranscriptPath); key != "" {
+		return notifier.SessionTag(key)
+	}
+	if strings.TrimSpace(payload.CWD) == "" {
+		return ""
+	}
+	return notifier.SessionTag(source + "|" + strings.ToLower(strings.TrimSpace(payload.CWD)))
 }
 
-func (a *App) promptPauseInTerminal(payload event.Payload, parentPID int) error {
+// promptPauseInTerminalWithRemote notifies remote channels without actions
+// and then asks in the terminal.
+func (a *App) promptPauseInTerminalWithRemote(payload event.Payload, msg notifier.Message, prefs Preferences, render bodyRenderer, parentPID int) error {
+	_, remoteErr := a.dispatch(prefs, nil, msg, render)
+	return errors.Join(a.promptPauseInTerminal(prefs.lang(), payload, parentPID), remoteErr)
+}
+
+func (a *App) promptPauseInTerminal(l i18n.Language, payload event.Payload, parentPID int) error {
 	commandHint := firstBacktickValue(payload.Summary)
 
 	fmt.Fprintln(a.stdout)
 	if commandHint != "" {
-		fmt.Fprintln(a.stdout, "Would you like to run the following command?")
+		fmt.Fprintln(a.stdout, l.T("Would you like to run the following command?"))
 		fmt.Fprintln(a.stdout)
 		fmt.Fprintf(a.stdout, "$ %s\n\n", commandHint)
 	}
-	fmt.Fprintln(a.stdout, "Choose an option:")
-	fmt.Fprintln(a.stdout, "1. Yes, proceed (y)")
-	fmt.Fprintln(a.stdout, "2. Yes, and don't ask again for this pattern (p)")
-	fmt.Fprintln(a.stdout, "3. No, and tell Codex what to do differently (esc)")
+	fmt.Fprintln(a.stdout, l.T("Choose an option:"))
+	fmt.Fprintln(a.stdout, l.T("1. Yes, proceed (y)"))
+	fmt.Fprintln(a.stdout, l.T("2. Yes, and don't ask again for this pattern (p)"))
+	fmt.Fprintln(a.stdout, l.T("3. No, and tell Codex what to do differently (esc)"))
 	fmt.Fprint(a.stdout, "> ")
 
 	reader := bufio.NewReader(a.stdin)
@@ -490,7 +637,7 @@ func (a *App) promptPauseInTerminal(payload event.Payload, parentPID int) error
 		choice := strings.TrimSpace(line)
 		decision, parseErr := parseTerminalPauseChoice(choice)
 		if parseErr == nil {
-			if deliverErr := a.approvalExecutor.Deliver(parentPID, decision); deliverErr != nil {
+			if deliverErr := a.approvalExecutor.Deliver(parentPID, decision, ""); deliverErr != nil {
 				return deliverErr
 			}
 			fmt.Fprintf(a.stdout, "approval response delivered: %s\n", decision)
@@ -500,7 +647,7 @@ func (a *App) promptPauseInTerminal(payload event.Payload, parentPID int) error
 		if errors.Is(err, io.EOF) {
 			return fmt.Errorf("invalid terminal approval input: %q", choice)
 		}
-		fmt.Fprintln(a.stdout, "Select 1/2/3 (or y/p/esc).")
+		fmt.Fprintln(a.stdout, l.T("Select 1/2/3 (or y/p/esc)."))
 		fmt.Fprint(a.stdout, "> ")
 	}
 }
@@ -516,134 +663,6 @@ func parseTerminalPauseChoice(raw string) (approvalDecision, error) {
 	return parseApprovalDecision(normalized)
 }
 
-func normalizeClaudePaused(source, eventType string) string {
-	if strings.ToLower(strings.TrimSpace(source)) != "claude" {
-		return eventType
-	}
-	switch strings.ToLower(strings.TrimSpace(eventType)) {
-	case "paused", "agent-paused", "permission-request", "approval-required":
-		return "agent-turn-paused"
-	default:
-		return eventType
-	}
-}
-
-func (a *App) defaultNotify(title, body string, service notifier.Service, eventType, source string) error {
-	if err := service.Notify(title, body); err != nil {
-		return err
-	}
-	fmt.Fprintf(a.stdout, "notification sent: %s (%s)\n", eventType, source)
-	return nil
-}
-
-func (a *App) resolveNotifyPayload(args []string) (string, error) {
-	if len(args) == 0 {
-		return "", fmt.Errorf("notify payload argument is required")
-	}
-
-	switch args[0] {
-	case "--file":
-		if len(args) < 2 {
-			return "", fmt.Errorf("notify --file requires a path argument")
-		}
-		data, err := a.readFile(args[1])
-		if err != nil {
-			return "", fmt.Errorf("read notify payload file: %w", err)
-		}
-		return strings.TrimSpace(string(data)), nil
-	case "--b64":
-		if len(args) < 2 {
-			return "", fmt.Errorf("notify --b64 requires a base64 payload argument")
-		}
-		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(args[1]))
-		if err != nil {
-			return "", fmt.Errorf("decode notify base64 payload: %w", err)
-		}
-		return strings.TrimSpace(string(decoded)), nil
-	default:
-		return strings.TrimSpace(strings.Join(args, " ")), nil
-	}
-}
-
-// readClaudeHookInput reads Claude Code hook input from stdin and converts it
-// to a cc-notify event payload JSON string.
-// Claude Code "Stop" hook sends JSON via stdin with structure like:
-//
-//	{
-//	  "hook_type": "Stop",
-//	  "stop_hook_active": true,
-//	  "transcript_path": "...",
-//	  "session_id": "...",
-//	  ...
-//	}
-func (a *App) readClaudeHookInput() (string, error) {
-	data, err := io.ReadAll(a.stdin)
-	if err != nil {
-		return "", fmt.Errorf("read claude hook stdin: %w", err)
-	}
-	raw := strings.TrimSpace(string(data))
-	if raw == "" {
-		return "", fmt.Errorf("empty claude hook input")
-	}
-
-	// Parse the Claude hook JSON
-	var claudeInput map[string]interface{}
-	if err := json.Unmarshal([]byte(raw), &claudeInput); err != nil {
-		return "", fmt.Errorf("parse claude hook input: %w", err)
-	}
-
-	// Map Claude hook type to our event type.
-	hookType := strings.TrimSpace(stringValue(claudeInput["hook_type"]))
-	eventType := "agent-turn-complete"
-	switch strings.ToLower(hookType) {
-	case "stop":
-		eventType = "agent-turn-complete"
-	case "notification":
-		if isClaudeApprovalPrompt(claudeInput) {
-			eventType = "agent-turn-paused"
-		} else {
-			// Non-approval notifications are ignored by the renderer.
-			eventType = "claude-notification"
-		}
-	default:
-		eventType = "agent-turn-complete"
-	}
-
-	// Extract useful fields.
-	summary := strings.TrimSpace(firstNonEmptyString(
-		stringValue(claudeInput["summary"]),
-		stringValue(claudeInput["message"]),
-		stringValue(claudeInput["prompt"]),
-		stringValue(claudeInput["reason"]),
-	))
-	cwd := strings.TrimSpace(stringValue(claudeInput["cwd"]))
-	model := strings.TrimSpace(stringValue(claudeInput["model"]))
-	transcriptPath := strings.TrimSpace(stringValue(claudeInput["transcript_path"]))
-	if sessionID := strings.TrimSpace(stringValue(claudeInput["session_id"])); sessionID != "" && summary == "" {
-		summary = "Claude Code session " + sessionID + " completed"
-	}
-
-	// Build our standard payload
-	payload := event.Payload{
-		Type:           eventType,
-		Summary:        summary,
-		CWD:            cwd,
-		Model:          model,
-		TranscriptPath: transcriptPath,
-	}
-
-	result, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("marshal converted payload: %w", err)
-	}
-	return string(result), nil
-}
-
-func stringValue(raw interface{}) string {
-	value, _ := raw.(string)
-	return value
-}
-
 func firstNonEmptyString(values ...string) string {
 	for _, value := range values {
 		if strings.TrimSpace(value) != "" {
@@ -653,33 +672,14 @@ func firstNonEmptyString(values ...string) string {
 	return ""
 }
 
-func isClaudeApprovalPrompt(payload map[string]interface{}) bool {
-	candidates := []string{
-		stringValue(payload["summary"]),
-		stringValue(payload["message"]),
-		stringValue(payload["prompt"]),
-		stringValue(payload["reason"]),
-	}
-	fullText := strings.ToLower(strings.Join(candidates, "\n"))
-	if strings.Contains(fullText, "would you like to run") {
-		return true
-	}
-	if strings.Contains(fullText, "requires your approval") || strings.Contains(fullText, "needs your approval") {
-		return true
-	}
-	if strings.Contains(fullText, "permission") && strings.Contains(fullText, "command") {
-		return true
-	}
-	if strings.Contains(fullText, "allow") && strings.Contains(fullText, "command") {
-		return true
-	}
-	if strings.Contains(fullText, "是否执行") || strings.Contains(fullText, "需要你的批准") || strings.Contains(fullText, "允许执行") {
-		return true
-	}
-	return false
-}
-
 func (a *App) runTestNotify(args []string) error {
+	channelURL := ""
+	if len(args) > 0 && args[0] == "--channel" {
+		if len(args) < 2 || strings.TrimSpace(args[1]) == "" {
+			return fmt.Errorf("test-notify --channel requires a channel URL")
+		}
+		channelURL, args = args[1], args[2:]
+	}
 	title := "Codex Notification Test"
 	body := "cc-notify is ready"
 	if len(args) > 0 {
@@ -697,9 +697,30 @@ func (a *App) runTestNotify(args []string) error {
 	if body == "" {
 		body = "cc-notify is ready"
 	}
+	if channelURL != "" {
+		return a.testNotifyChannel(channelURL, title, body)
+	}
 	return a.notifier.Notify(title, body)
 }
 
+// testNotifyChannel sends a test message through the single channel described
+// by raw, using the callback settings from preferences.
+func (a *App) testNotifyChannel(raw, title, body string) error {
+	prefs, _, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	ch, err := notifier.NewChannelFromURL(raw, prefs.urlDefaults())
+	if err != nil {
+		return err
+	}
+	if err := notifier.Send(ch.Service, notifier.Message{Title: title, Body: body, Language: prefs.lang()}); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "test notification sent via %s\n", ch.Name)
+	return nil
+}
+
 func (a *App) runTestToast(args []string) error {
 	title := "Codex Toast Test"
 	body := "toast mode test from cc-notify"
@@ -729,6 +750,7 @@ func (a *App) runTestToast(args []string) error {
 		service = notifier.NewWithConfig(notifier.Config{
 			Mode:       "toast",
 			ToastAppID: prefs.ToastAppID,
+			Toast:      prefs.toastOptions(),
 		})
 	}
 	if err := service.Notify(title, body); err != nil {
@@ -741,6 +763,7 @@ func (a *App) runTestToast(args []string) error {
 func (a *App) runRespond(args []string) error {
 	id := ""
 	decision := approvalDecision("")
+	feedback := ""
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
@@ -760,6 +783,12 @@ func (a *App) runRespond(args []string) error {
 			}
 			decision = d
 			i++
+		case "--feedback":
+			if i+1 >= len(args) {
+				return fmt.Errorf("respond --feedback requires a value")
+			}
+			feedback = normalizeFeedback(args[i+1])
+			i++
 		case "--approve":
 			decision = approvalApprove
 		case "--reject":
@@ -775,35 +804,78 @@ func (a *App) runRespond(args []string) error {
 	if decision == "" {
 		return fmt.Errorf("respond requires --decision (approve|reject) or --approve/--reject")
 	}
+	if feedback != "" && decision != approvalReject {
+		return fmt.Errorf("respond --feedback requires the reject decision")
+	}
 
+	// The response notifications are best effort; without settings
+	// they are in the system language.
+	prefs, _, _ := a.loadPreferences()
+	l := prefs.lang()
 	pending, err := a.loadPendingApproval(id)
 	if err != nil {
-		_ = a.notifier.Notify("Codex Approval", "Unable to apply response: request not found or expired.")
+		_ = a.notifier.Notify(l.T("Codex Approval"), l.T("Unable to apply response: request not found or expired."))
 		return err
 	}
 	if time.Now().Unix() > pending.ExpiresAtUnix {
 		_ = a.deletePendingApproval(id)
-		_ = a.notifier.Notify("Codex Approval", "Unable to apply response: request expired.")
+		_ = a.notifier.Notify(l.T("Codex Approval"), l.T("Unable to apply response: request expired."))
 		return fmt.Errorf("approval request expired: %s", id)
 	}
 
-	if err := a.approvalExecutor.Deliver(pending.ParentPID, decision); err != nil {
-		_ = a.notifier.Notify("Codex Approval", "Unable to apply response automatically. Open terminal and answer manually.")
+	if pending.Hook {
+		// The hook waiting for the decision reads it from the file; the
+		// first response wins.
+		if err := a.recordHookResponse(id, hookResponse{Decision: decision, Feedback: feedback}); err != nil {
+			return err
+		}
+		a.dismissApprovalPrompt(pending)
+		_ = a.notifier.Notify(l.T("Claude Approval"), l.Sprintf("Response sent: %s", decision))
+		fmt.Fprintf(a.stdout, "approval response delivered: %s\n", decision)
+		return nil
+	}
+
+	if err := a.approvalExecutor.Deliver(pending.ParentPID, decision, feedback); err != nil {
+		_ = a.notifier.Notify(l.T("Codex Approval"), l.T("Unable to apply response automatically. Open terminal and answer manually."))
 		return err
 	}
 	_ = a.deletePendingApproval(id)
+	a.dismissApprovalPrompt(pending)
 
-	_ = a.notifier.Notify("Codex Approval", "Response sent: "+string(decision))
+	_ = a.notifier.Notify(l.T("Codex Approval"), l.Sprintf("Response sent: %s", decision))
 	fmt.Fprintf(a.stdout, "approval response delivered: %s\n", decision)
 	return nil
 }
 
 func (a *App) runProtocolURI(raw string) error {
-	id, decision, err := parseApprovalProtocolURI(raw)
+	id, decision, feedback, err := parseApprovalProtocolURI(raw)
 	if err != nil {
 		return err
 	}
-	return a.runRespond([]string{"--id", id, "--decision", string(decision)})
+	args := []string{"--id", id, "--decision", string(decision)}
+	if feedback != "" {
+		args = append(args, "--feedback", feedback)
+	}
+	return a.runRespond(args)
+}
+
+// dismissApprovalPrompt removes the desktop prompt of a resolved approval, for
+// example one answered from a phone while the toast was still showing.
+func (a *App) dismissApprovalPrompt(pending pendingApproval) {
+	if pending.Tag == "" {
+		return
+	}
+	service := a.notifier
+	if a.defaultNotifier {
+		prefs, _, err := a.loadPreferences()
+		if err != nil || prefs.PausePrompt != "toast" {
+			return
+		}
+		service = notifier.NewWithConfig(notifier.Config{Mode: "toast", ToastAppID: prefs.ToastAppID})
+	}
+	if svc, ok := service.(notifier.ActionService); ok {
+		_ = svc.Dismiss(pending.Tag, pending.Group)
+	}
 }
 
 type pendingApproval struct {
@@ -811,35 +883,58 @@ type pendingApproval struct {
 	ParentPID     int    `json:"parent_pid"`
 	CreatedAtUnix int64  `json:"created_at_unix"`
 	ExpiresAtUnix int64  `json:"expires_at_unix"`
+	// Tag and Group identify the prompt notification so it can be removed
+	// once the approval is resolved.
+	Tag   string `json:"tag,omitempty"`
+	Group string `json:"group,omitempty"`
+	// Hook marks an approval a hook waits for: the response is recorded
+	// for it, see recordHookResponse, rather than typed into the terminal.
+	Hook bool `json:"hook,omitempty"`
 }
 
-func (a *App) createPendingApproval(parentPID int) (pendingApproval, error) {
+// createPendingApproval records an approval awaiting its response. A
+// hookTimeout above zero makes it one a hook waits that long for.
+func (a *App) createPendingApproval(parentPID int, tag, group string, hookTimeout time.Duration) (pendingApproval, error) {
 	id, err := randomApprovalID()
 	if err != nil {
 		return pendingApproval{}, err
 	}
+	ttl := 15 * time.Minute
+	if hookTimeout > 0 {
+		ttl = hookTimeout
+	}
 	now := time.Now().Unix()
 	item := pendingApproval{
 		ID:            id,
 		ParentPID:     parentPID,
 		CreatedAtUnix: now,
-		ExpiresAtUnix: now + int64((15 * time.Minute).Seconds()),
+		ExpiresAtUnix: now + int64(ttl.Seconds()),
+		Tag:           tag,
+		Group:         group,
+		Hook:          hookTimeout > 0,
 	}
+	if err := a.writePendingApproval(item); err != nil {
+		return pendingApproval{}, err
+	}
+	return item, nil
+}
+
+func (a *App) writePendingApproval(item pendingApproval) error {
 	data, err := json.Marshal(item)
 	if err != nil {
-		return pendingApproval{}, fmt.Errorf("marshal pending approval: %w", err)
+		return fmt.Errorf("marshal pending approval: %w", err)
 	}
-	path, err := a.pendingApprovalPath(id)
+	path, err := a.pendingApprovalPath(item.ID)
 	if err != nil {
-		return pendingApproval{}, err
+		return err
 	}
-	if err := a.mkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return pendingApproval{}, fmt.Errorf("create approvals directory: %w", err)
+	if err := a.mkdirPrivate(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("create approvals directory: %w", err)
 	}
-	if err := a.writeFile(path, data, 0o644); err != nil {
-		return pendingApproval{}, fmt.Errorf("write pending approval: %w", err)
+	if err := a.writeFile(path, data, privateFileMode); err != nil {
+		return fmt.Errorf("write pending approval: %w", err)
 	}
-	return item, nil
+	return nil
 }
 
 func (a *App) loadPendingApproval(id string) (pendingApproval, error) {
@@ -866,8 +961,10 @@ func (a *App) deletePendingApproval(id string) error {
 	if err != nil {
 		return err
 	}
-	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
-		return err
+	for _, name := range []string{path, hookResponsePath(path)} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
 	}
 	return nil
 }
@@ -910,27 +1007,37 @@ func approvalActionURI(id string, decision approvalDecision) string {
 	return "cc-notify://respond?" + q.Encode()
 }
 
-func parseApprovalProtocolURI(raw string) (string, approvalDecision, error) {
+// parseApprovalProtocolURI reads a cc-notify://respond URI. feedback is the
+// optional text typed for a rejection, percent-encoded as UTF-8.
+func parseApprovalProtocolURI(raw string) (id string, decision approvalDecision, feedback string, err error) {
 	u, err := url.Parse(strings.TrimSpace(raw))
 	if err != nil {
-		return "", "", fmt.Errorf("parse protocol uri: %w", err)
+		return "", "", "", fmt.Errorf("parse protocol uri: %w", err)
 	}
 	if strings.ToLower(u.Scheme) != "cc-notify" {
-		return "", "", fmt.Errorf("unsupported protocol scheme: %s", u.Scheme)
+		return "", "", "", fmt.Errorf("unsupported protocol scheme: %s", u.Scheme)
 	}
 	target := strings.Trim(strings.ToLower(u.Host+u.Path), "/")
 	if target != "respond" {
-		return "", "", fmt.Errorf("unsupported protocol action: %s", target)
+		return "", "", "", fmt.Errorf("unsupported protocol action: %s", target)
 	}
-	id := strings.TrimSpace(u.Query().Get("id"))
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", "", "", fmt.Errorf("parse protocol uri: %w", err)
+	}
+	id = strings.TrimSpace(query.Get("id"))
 	if id == "" {
-		return "", "", fmt.Errorf("protocol uri missing id")
+		return "", "", "", fmt.Errorf("protocol uri missing id")
 	}
-	decision, err := parseApprovalDecision(u.Query().Get("decision"))
+	decision, err = parseApprovalDecision(query.Get("decision"))
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
+	}
+	feedback = query.Get(notifier.FeedbackParam)
+	if !utf8.ValidString(feedback) {
+		return "", "", "", fmt.Errorf("protocol uri feedback is not valid UTF-8")
 	}
-	return id, decision, nil
+	return id, decision, normalizeFeedback(feedback), nil
 }
 
 func parseApprovalDecision(raw string) (approvalDecision, error) {
@@ -952,15 +1059,15 @@ func parseApprovalDecision(raw string) (approvalDecision, error) {
 	}
 }
 
-func buildPausedActions(summary, id string) []notifier.Action {
-	secondLabel := "Yes, and don't ask again for this command pattern"
+func buildPausedActions(l i18n.Language, summary, id string) []notifier.Action {
+	secondLabel := l.T("Yes, and don't ask again for this command pattern")
 	if cmd := firstBacktickValue(summary); cmd != "" {
-		secondLabel = "Yes, don't ask again for `" + cmd + "`"
+		secondLabel = l.Sprintf("Yes, don't ask again for `%s`", cmd)
 	}
 	return []notifier.Action{
-		{Label: "Yes, proceed", URI: approvalActionURI(id, approvalProceed)},
+		{Label: l.T("Yes, proceed"), URI: approvalActionURI(id, approvalProceed)},
 		{Label: secondLabel, URI: approvalActionURI(id, approvalProceedAlways)},
-		{Label: "No, tell Codex to do differently", URI: approvalActionURI(id, approvalReject)},
+		{Label: l.T("No, tell Codex to do differently"), URI: approvalActionURI(id, approvalReject), Input: l.T("Tell Codex what to do differently")},
 	}
 }
 
@@ -987,17 +1094,21 @@ func firstBacktickValue(input string) string {
 
 func (a *App) printUsage() {
 	fmt.Fprintf(a.stdout, "\n  %s%s⚡ cc-notify%s %s%s%s\n", colorBold, colorCyan, colorReset, colorDim, version, colorReset)
-	fmt.Fprintf(a.stdout, "  %sWindows notifications for Codex CLI & Claude Code%s\n\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "  %sNotifications for Codex, Claude Code, Gemini CLI & Aider%s\n\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "  %s%sUsage:%s\n", colorBold, colorYellow, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify                              %sinteractive settings%s\n", colorDim, colorReset)
-	fmt.Fprintf(a.stdout, "    cc-notify install [codex|claude]       %sregister hooks (both if omitted)%s\n", colorDim, colorReset)
-	fmt.Fprintf(a.stdout, "    cc-notify uninstall [codex|claude]     %sremove hooks (both if omitted)%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify install [agent]              %sregister hooks (all detected agents if omitted)%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify uninstall [agent]            %sremove hooks (all if omitted)%s\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify notify <json>                %shandle Codex event payload%s\n", colorDim, colorReset)
-	fmt.Fprintf(a.stdout, "    cc-notify notify --claude              %shandle Claude Code hook (stdin)%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify notify --<agent>             %shandle another agent's hook, e.g. --claude (stdin)%s\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify notify --file <path>         %sread payload from file%s\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify notify --b64 <base64>        %sbase64 encoded payload%s\n", colorDim, colorReset)
-	fmt.Fprintf(a.stdout, "    cc-notify respond --id <id> --decision <proceed|proceed-always|reject> %sapply pause response%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify run [--min-seconds N] -- <cmd> %snotify when a long-running command ends%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify respond --id <id> --decision <proceed|proceed-always|reject> [--feedback <text>] %sapply pause response%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify serve [--addr host:port]     %saccept approval callbacks over HTTP%s\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify test-notify [title] [body]   %ssend test notification%s\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "    cc-notify test-notify --channel <url> [title] [body] %ssend test notification to one channel%s\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify test-toast [title] [body]    %stest toast mode%s\n", colorDim, colorReset)
 	fmt.Fprintf(a.stdout, "    cc-notify help                         %sshow this help%s\n\n", colorDim, colorReset)
+	fmt.Fprintf(a.stdout, "  %sAgents:%s %s\n\n", colorDim, colorReset, strings.Join(source.Names(), ", "))
 }
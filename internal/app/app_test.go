@@ -4,16 +4,26 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"cc-notify/internal/event"
 	"cc-notify/internal/notifier"
 )
 
+// TestMain pins the language so expectations do not depend on the locale
+// of the machine running the tests.
+func TestMain(m *testing.M) {
+	os.Setenv("LANGUAGE", "en")
+	os.Exit(m.Run())
+}
+
 type fakeNotifier struct {
 	count int
 	title string
@@ -31,6 +41,7 @@ type fakeActionNotifier struct {
 	fakeNotifier
 	actionCount int
 	actions     []notifier.Action
+	dismissed   []string
 }
 
 func (f *fakeActionNotifier) NotifyWithActions(title, body string, actions []notifier.Action) error {
@@ -41,6 +52,11 @@ func (f *fakeActionNotifier) NotifyWithActions(title, body string, actions []not
 	return nil
 }
 
+func (f *fakeActionNotifier) Dismiss(tag, group string) error {
+	f.dismissed = append(f.dismissed, tag+"/"+group)
+	return nil
+}
+
 type fakeApprovalExecutor struct {
 	calls []approvalInput
 	err   error
@@ -49,13 +65,14 @@ type fakeApprovalExecutor struct {
 type approvalInput struct {
 	parentPID int
 	decision  approvalDecision
+	feedback  string
 }
 
-func (f *fakeApprovalExecutor) Deliver(parentPID int, decision approvalDecision) error {
+func (f *fakeApprovalExecutor) Deliver(parentPID int, decision approvalDecision, feedback string) error {
 	if f.err != nil {
 		return f.err
 	}
-	f.calls = append(f.calls, approvalInput{parentPID: parentPID, decision: decision})
+	f.calls = append(f.calls, approvalInput{parentPID: parentPID, decision: decision, feedback: feedback})
 	return nil
 }
 
@@ -469,6 +486,12 @@ func TestRun_NoArgsOpensInteractiveAndAutoInstalls(t *testing.T) {
 	settingsPath := filepath.Join(temp, "settings.json")
 	configPath := filepath.Join(temp, ".codex", "config.toml")
 	claudeConfigPath := filepath.Join(temp, ".claude", "settings.json")
+	geminiConfigPath := filepath.Join(temp, ".gemini", "settings.json")
+	aiderConfigPath := filepath.Join(temp, ".aider.conf.yml")
+	// Gemini CLI has been run, Aider has not.
+	if err := os.MkdirAll(filepath.Dir(geminiConfigPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
 
 	var stdout, stderr bytes.Buffer
 	tool := New(Options{
@@ -478,7 +501,11 @@ func TestRun_NoArgsOpensInteractiveAndAutoInstalls(t *testing.T) {
 		SettingsPath:     func() (string, error) { return settingsPath, nil },
 		ConfigPath:       func() (string, error) { return configPath, nil },
 		ClaudeConfigPath: func() (string, error) { return claudeConfigPath, nil },
-		Executable:       func() (string, error) { return `C:\tool\cc-notify.exe`, nil },
+		SourceConfigPaths: map[string]func() (string, error){
+			"gemini": func() (string, error) { return geminiConfigPath, nil },
+			"aider":  func() (string, error) { return aiderConfigPath, nil },
+		},
+		Executable: func() (string, error) { return `C:\tool\cc-notify.exe`, nil },
 	})
 
 	code := tool.Run(nil)
@@ -494,6 +521,12 @@ func TestRun_NoArgsOpensInteractiveAndAutoInstalls(t *testing.T) {
 	if _, err := os.Stat(configPath); err != nil {
 		t.Fatalf("expected codex config to be auto-installed: %v", err)
 	}
+	if _, err := os.Stat(geminiConfigPath); err != nil {
+		t.Fatalf("expected detected gemini to be auto-installed: %v", err)
+	}
+	if _, err := os.Stat(aiderConfigPath); !os.IsNotExist(err) {
+		t.Fatalf("expected aider to be left alone, got %v", err)
+	}
 }
 
 func TestRun_NoArgsWithBOMSettings(t *testing.T) {
@@ -525,10 +558,11 @@ func TestRun_NotifyPausedUsesActionableNotification(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	actionNotifier := &fakeActionNotifier{}
 	tool := New(Options{
-		Notifier:     actionNotifier,
-		Stdout:       &stdout,
-		Stderr:       &stderr,
-		SettingsPath: func() (string, error) { return settingsPath, nil },
+		Notifier:         actionNotifier,
+		ApprovalExecutor: &fakeApprovalExecutor{},
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
 	})
 
 	code := tool.Run([]string{"notify", `{"type":"agent-turn-paused","summary":"need approval"}`})
@@ -566,6 +600,41 @@ func TestRun_NotifyPausedUsesActionableNotification(t *testing.T) {
 	}
 }
 
+func TestRun_NotifyPausedInChinese(t *testing.T) {
+	prefs := DefaultPreferences()
+	prefs.Language = "zh_CN.UTF-8"
+	settingsPath := writeTestPreferences(t, prefs)
+
+	var stdout, stderr bytes.Buffer
+	actionNotifier := &fakeActionNotifier{}
+	tool := New(Options{
+		Notifier:         actionNotifier,
+		ApprovalExecutor: &fakeApprovalExecutor{},
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
+	})
+
+	code := tool.Run([]string{"notify", "{\"type\":\"agent-turn-paused\",\"summary\":\"Run `go test ./...`?\"}"})
+	if code != 0 {
+		t.Fatalf("expected zero exit code, stderr=%q", stderr.String())
+	}
+	if actionNotifier.title != "Codex 需要你的输入" {
+		t.Fatalf("unexpected title: %q", actionNotifier.title)
+	}
+	labels := []string{}
+	for _, action := range actionNotifier.actions {
+		labels = append(labels, action.Label)
+	}
+	want := []string{"是，继续", "是，`go test ./...` 不再询问", "否，告诉 Codex 换种做法"}
+	if strings.Join(labels, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected action labels: %q", labels)
+	}
+	if actionNotifier.actions[2].Input != "告诉 Codex 应该怎么做" {
+		t.Fatalf("unexpected input placeholder: %q", actionNotifier.actions[2].Input)
+	}
+}
+
 func TestRun_RespondDeliversPendingApproval(t *testing.T) {
 	temp := t.TempDir()
 	settingsPath := filepath.Join(temp, "settings.json")
@@ -610,6 +679,37 @@ func TestRun_RespondDeliversPendingApproval(t *testing.T) {
 	}
 }
 
+func TestRun_RespondDismissesPromptNotification(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+
+	var stdout, stderr bytes.Buffer
+	actionNotifier := &fakeActionNotifier{}
+	tool := New(Options{
+		Notifier:         actionNotifier,
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
+		ApprovalExecutor: &fakeApprovalExecutor{},
+	})
+
+	code := tool.Run([]string{"notify", `{"type":"agent-turn-paused","summary":"need approval","thread-id":"t-1","cwd":"/work/api"}`})
+	if code != 0 {
+		t.Fatalf("notify paused failed: stderr=%q", stderr.String())
+	}
+	uri, err := url.Parse(actionNotifier.actions[0].URI)
+	if err != nil {
+		t.Fatalf("parse action uri: %v", err)
+	}
+	if code := tool.Run([]string{"respond", "--id", uri.Query().Get("id"), "--decision", "approve"}); code != 0 {
+		t.Fatalf("respond failed: stderr=%q", stderr.String())
+	}
+	want := notifier.SessionTag("t-1") + "/" + notifier.ProjectGroup("/work/api")
+	if len(actionNotifier.dismissed) != 1 || actionNotifier.dismissed[0] != want {
+		t.Fatalf("expected prompt %q to be dismissed, got %v", want, actionNotifier.dismissed)
+	}
+}
+
 func TestRun_ProtocolURIRespond(t *testing.T) {
 	temp := t.TempDir()
 	settingsPath := filepath.Join(temp, "settings.json")
@@ -641,6 +741,76 @@ func TestRun_ProtocolURIRespond(t *testing.T) {
 	}
 }
 
+func TestRun_ProtocolURIRespondWithFeedback(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+
+	var stdout, stderr bytes.Buffer
+	actionNotifier := &fakeActionNotifier{}
+	executor := &fakeApprovalExecutor{}
+	tool := New(Options{
+		Notifier:         actionNotifier,
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
+		ApprovalExecutor: executor,
+	})
+
+	code := tool.Run([]string{"notify", `{"type":"agent-turn-paused","summary":"need approval"}`})
+	if code != 0 {
+		t.Fatalf("notify paused failed: stderr=%q", stderr.String())
+	}
+	reject := actionNotifier.actions[2]
+	if reject.Input == "" {
+		t.Fatalf("expected the reject action to ask for feedback")
+	}
+
+	feedback := "用 `go test ./...` 代替 & skip 🚀\n+50% faster"
+	code = tool.Run([]string{reject.URI + "&" + notifier.FeedbackParam + "=" + url.QueryEscape(feedback)})
+	if code != 0 {
+		t.Fatalf("protocol respond failed: stderr=%q", stderr.String())
+	}
+	want := approvalInput{parentPID: os.Getppid(), decision: approvalReject, feedback: "用 `go test ./...` 代替 & skip 🚀 +50% faster"}
+	if len(executor.calls) != 1 || executor.calls[0] != want {
+		t.Fatalf("expected rejection with feedback %+v, got %+v", want, executor.calls)
+	}
+}
+
+func TestParseApprovalProtocolURI_Feedback(t *testing.T) {
+	cases := []struct {
+		raw      string
+		feedback string
+		err      bool
+	}{
+		{raw: "cc-notify://respond?id=0123456789abcdef&decision=reject&feedback=%E2%9C%85%20use%20%2Bx%20%26%20y", feedback: "✅ use +x & y"},
+		{raw: "cc-notify://respond?id=0123456789abcdef&decision=reject&feedback=a+b%0D%0Ac%09%07d", feedback: "a b  c d"},
+		{raw: "cc-notify://respond?id=0123456789abcdef&decision=reject&feedback=%F0%9F%91%8D", feedback: "👍"},
+		{raw: "cc-notify://respond?id=0123456789abcdef&decision=reject&feedback=%FF%FE", err: true},
+		{raw: "cc-notify://respond?id=0123456789abcdef&decision=reject&feedback=%zz", err: true},
+	}
+	for _, tc := range cases {
+		_, decision, feedback, err := parseApprovalProtocolURI(tc.raw)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("%s: expected error", tc.raw)
+			}
+			continue
+		}
+		if err != nil || decision != approvalReject || feedback != tc.feedback {
+			t.Fatalf("%s: got %q, %q, %v; want feedback %q", tc.raw, decision, feedback, err, tc.feedback)
+		}
+	}
+}
+
+func TestRun_RespondRejectsFeedbackWithApproval(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{Stdout: &stdout, Stderr: &stderr, ApprovalExecutor: &fakeApprovalExecutor{}})
+	code := tool.Run([]string{"respond", "--id", "0123456789abcdef", "--decision", "proceed", "--feedback", "no"})
+	if code == 0 || !strings.Contains(stderr.String(), "reject") {
+		t.Fatalf("expected feedback with approval to fail, got code=%d stderr=%q", code, stderr.String())
+	}
+}
+
 func TestRun_NotifyPausedTerminalPromptDeliversDecision(t *testing.T) {
 	temp := t.TempDir()
 	settingsPath := filepath.Join(temp, "settings.json")
@@ -702,6 +872,265 @@ func TestRun_NotifyPausedTerminalPromptDeliversDecision(t *testing.T) {
 	}
 }
 
+func TestRun_InstallClaudeUsesEnabledEvents(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	claudeConfigPath := filepath.Join(temp, ".claude", "settings.json")
+	settings := DefaultPreferences()
+	settings.ClaudeEvents = []string{"Stop", "subagentstop", "PreCompact"}
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{
+		Notifier:         &fakeNotifier{},
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
+		ClaudeConfigPath: func() (string, error) { return claudeConfigPath, nil },
+		Executable:       func() (string, error) { return filepath.Join(temp, "cc-notify"), nil },
+	})
+	if code := tool.Run([]string{"install", "claude"}); code != 0 {
+		t.Fatalf("install claude failed: %q", stderr.String())
+	}
+	data, err := os.ReadFile(claudeConfigPath)
+	if err != nil {
+		t.Fatalf("read claude settings: %v", err)
+	}
+	var parsed struct {
+		Hooks map[string]json.RawMessage `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("parse claude settings: %v", err)
+	}
+	if len(parsed.Hooks) != 3 || parsed.Hooks["SubagentStop"] == nil || parsed.Hooks["PreCompact"] == nil || parsed.Hooks["Notification"] != nil {
+		t.Fatalf("expected Stop, SubagentStop and PreCompact hooks, got %s", data)
+	}
+}
+
+func TestRun_NotifyClaudeHookEvents(t *testing.T) {
+	cases := []struct {
+		input   string
+		title   string
+		actions bool
+	}{
+		{input: `{"hook_event_name":"Notification","notification_type":"idle_prompt","message":"Claude is waiting for your input","cwd":"/work/api"}`, title: "Claude Is Waiting for You"},
+		{input: `{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs your permission to use Bash","cwd":"/work/api"}`, title: "Codex Needs Input", actions: true},
+		{input: `{"hook_event_name":"SubagentStop","session_id":"s-1","cwd":"/work/api"}`, title: "Claude Subagent Finished"},
+		{input: `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"go test ./..."},"cwd":"/work/api"}`, title: "Claude Is Using a Tool"},
+	}
+	for _, tc := range cases {
+		temp := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		actionNotifier := &fakeActionNotifier{}
+		tool := New(Options{
+			Notifier:         actionNotifier,
+			Stdin:            strings.NewReader(tc.input),
+			Stdout:           &stdout,
+			Stderr:           &stderr,
+			SettingsPath:     func() (string, error) { return filepath.Join(temp, "settings.json"), nil },
+			ApprovalExecutor: &fakeApprovalExecutor{},
+		})
+		if code := tool.Run([]string{"notify", "--claude"}); code != 0 {
+			t.Fatalf("%s: notify --claude failed: stderr=%q", tc.input, stderr.String())
+		}
+		if actionNotifier.title != tc.title || (actionNotifier.actionCount > 0) != tc.actions {
+			t.Fatalf("%s: got title %q with %d action prompts", tc.input, actionNotifier.title, actionNotifier.actionCount)
+		}
+		if stdout.Len() != 0 {
+			t.Fatalf("%s: hook stdout must stay empty, got %q", tc.input, stdout.String())
+		}
+	}
+}
+
+func TestRun_NotifyClaudeStopReadsTranscript(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	settings := DefaultPreferences()
+	settings.ClaudeContent = "full"
+	settings.IncludeDir = false
+	settings.IncludeModel = true
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	transcriptPath := filepath.Join(temp, "s-1.jsonl")
+	transcript := `{"type":"user","message":{"role":"user","content":"fix the flaky test"}}
+{"type":"assistant","message":{"model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Fixed the race in the cache test."}]}}
+`
+	if err := os.WriteFile(transcriptPath, []byte(transcript), 0o644); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+	input, _ := json.Marshal(map[string]string{
+		"hook_event_name": "Stop",
+		"session_id":      "s-1",
+		"transcript_path": transcriptPath,
+	})
+
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdin:        bytes.NewReader(input),
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	if code := tool.Run([]string{"notify", "--claude"}); code != 0 {
+		t.Fatalf("notify --claude failed: stderr=%q", stderr.String())
+	}
+	if desktop.body != "Fixed the race in the cache test.\nModel: claude-sonnet-4-5" {
+		t.Fatalf("expected the reply from the transcript, got %q", desktop.body)
+	}
+}
+
+func TestRun_NotifyIncludesTurnStats(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	settings := DefaultPreferences()
+	settings.IncludeDir = false
+	settings.IncludeTokens = true
+	settings.IncludeCost = true
+	settings.Prices = event.PriceTable{"gpt-5": {Input: 1.25, Output: 10, CacheRead: 0.125}}
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	rollout := filepath.Join(temp, "rollout.jsonl")
+	lines := `{"timestamp":"2025-10-01T10:00:00.000Z","type":"event_msg","payload":{"type":"user_message","message":"fix it"}}
+{"timestamp":"2025-10-01T10:00:01.000Z","type":"turn_context","payload":{"model":"gpt-5-codex"}}
+{"timestamp":"2025-10-01T10:00:40.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":200000,"cached_input_tokens":100000,"output_tokens":20000}}}}
+`
+	if err := os.WriteFile(rollout, []byte(lines), 0o644); err != nil {
+		t.Fatalf("write rollout: %v", err)
+	}
+	payload, _ := json.Marshal(map[string]string{"type": "agent-turn-complete", "summary": "done", "transcript-path": rollout})
+
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	if code := tool.Run([]string{"notify", string(payload)}); code != 0 {
+		t.Fatalf("notify failed: stderr=%q", stderr.String())
+	}
+	// 100k input at $1.25, 100k cached at $0.125 and 20k output at $10.
+	if desktop.body != "done\n220k tokens · $0.34" {
+		t.Fatalf("unexpected body: %q", desktop.body)
+	}
+}
+
+func TestRun_NotifyIncludesGitContext(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	settings := DefaultPreferences()
+	settings.IncludeDir = false
+	settings.IncludeGitBranch = true
+	settings.IncludeGitWorktree = true
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	// A linked worktree: its .git file points into the main repository.
+	gitDir := filepath.Join(temp, "repo", ".git", "worktrees", "api-2")
+	worktree := filepath.Join(temp, "api-2")
+	for _, dir := range []string{gitDir, worktree} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/fix/retry\n"), 0o644); err != nil {
+		t.Fatalf("write HEAD: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644); err != nil {
+		t.Fatalf("write .git: %v", err)
+	}
+	payload, _ := json.Marshal(map[string]string{"type": "agent-turn-complete", "summary": "done", "cwd": worktree})
+
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	if code := tool.Run([]string{"notify", string(payload)}); code != 0 {
+		t.Fatalf("notify failed: stderr=%q", stderr.String())
+	}
+	if desktop.body != "done\nGit: fix/retry · worktree api-2" {
+		t.Fatalf("unexpected body: %q", desktop.body)
+	}
+}
+
+func TestRun_NotifyCountsTurnChanges(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	settings := DefaultPreferences()
+	settings.IncludeDir = false
+	settings.IncludeChanges = true
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	// A repository without an index, so every file is untracked.
+	project := filepath.Join(temp, "project")
+	if err := os.MkdirAll(filepath.Join(project, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	files := map[string]string{".git/HEAD": "ref: refs/heads/main\n", "main.go": "package main\n", "notes.md": "a\nb\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(project, filepath.FromSlash(name)), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	desktop := &fakeNotifier{}
+	notify := func(hookEvent string) {
+		t.Helper()
+		input, _ := json.Marshal(map[string]string{"hook_event_name": hookEvent, "session_id": "s-1", "cwd": project, "prompt": "add a flag"})
+		var stdout, stderr bytes.Buffer
+		tool := New(Options{
+			Notifier:     desktop,
+			Stdin:        bytes.NewReader(input),
+			Stdout:       &stdout,
+			Stderr:       &stderr,
+			SettingsPath: func() (string, error) { return settingsPath, nil },
+		})
+		if code := tool.Run([]string{"notify", "--claude"}); code != 0 || strings.Contains(stderr.String(), "warning") {
+			t.Fatalf("notify %s failed: stderr=%q", hookEvent, stderr.String())
+		}
+	}
+	snapshots := filepath.Join(temp, "snapshots")
+
+	notify("UserPromptSubmit")
+	if desktop.count != 0 {
+		t.Fatalf("expected the turn start to only take a snapshot, got %q", desktop.title)
+	}
+	if entries, _ := os.ReadDir(snapshots); len(entries) != 1 {
+		t.Fatalf("expected one snapshot, got %d", len(entries))
+	}
+
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	if err := os.Remove(filepath.Join(project, "notes.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	notify("Stop")
+	if desktop.count != 1 || desktop.body != "Claude Code session s-1 completed\nEdited 2 files, +2/−2" {
+		t.Fatalf("unexpected notification %d: %q", desktop.count, desktop.body)
+	}
+	if entries, _ := os.ReadDir(snapshots); len(entries) != 0 {
+		t.Fatalf("expected the snapshot to be removed, got %d", len(entries))
+	}
+}
+
 func TestRun_NotifyClaudePermissionNotificationRoutesToPaused(t *testing.T) {
 	temp := t.TempDir()
 	settingsPath := filepath.Join(temp, "settings.json")
@@ -709,7 +1138,8 @@ func TestRun_NotifyClaudePermissionNotificationRoutesToPaused(t *testing.T) {
 	var stdout, stderr bytes.Buffer
 	actionNotifier := &fakeActionNotifier{}
 	tool := New(Options{
-		Notifier: actionNotifier,
+		Notifier:         actionNotifier,
+		ApprovalExecutor: &fakeApprovalExecutor{},
 		Stdin: strings.NewReader(`{
   "hook_type":"Notification",
   "message":"Would you like to run the following command? ping 127.0.0.1 -n 1",
@@ -731,3 +1161,381 @@ func TestRun_NotifyClaudePermissionNotificationRoutesToPaused(t *testing.T) {
 		t.Fatalf("expected 3 actions, got %d", len(actionNotifier.actions))
 	}
 }
+
+func TestRun_InstallAndUninstallAider(t *testing.T) {
+	temp := t.TempDir()
+	aiderConfigPath := filepath.Join(temp, ".aider.conf.yml")
+	if err := os.WriteFile(aiderConfigPath, []byte("model: sonnet\n"), 0o644); err != nil {
+		t.Fatalf("write aider config: %v", err)
+	}
+	exePath := filepath.Join(temp, "cc-notify")
+
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{
+		Notifier:          &fakeNotifier{},
+		Stdout:            &stdout,
+		Stderr:            &stderr,
+		SettingsPath:      func() (string, error) { return filepath.Join(temp, "settings.json"), nil },
+		SourceConfigPaths: map[string]func() (string, error){"aider": func() (string, error) { return aiderConfigPath, nil }},
+		Executable:        func() (string, error) { return exePath, nil },
+	})
+	if code := tool.Run([]string{"install", "aider"}); code != 0 {
+		t.Fatalf("install aider failed: %q", stderr.String())
+	}
+	data, err := os.ReadFile(aiderConfigPath)
+	if err != nil {
+		t.Fatalf("read aider config: %v", err)
+	}
+	if !strings.Contains(string(data), "model: sonnet\nnotifications: true\nnotifications-command: '"+exePath+" notify --aider'") {
+		t.Fatalf("unexpected aider config: %q", data)
+	}
+	if !strings.Contains(stdout.String(), "aider: installed notifications command in "+aiderConfigPath) {
+		t.Fatalf("unexpected install output: %q", stdout.String())
+	}
+
+	if code := tool.Run([]string{"uninstall", "aider"}); code != 0 {
+		t.Fatalf("uninstall aider failed: %q", stderr.String())
+	}
+	data, _ = os.ReadFile(aiderConfigPath)
+	if string(data) != "model: sonnet\n" {
+		t.Fatalf("expected aider config restored, got %q", data)
+	}
+
+	if code := tool.Run([]string{"install", "cursor"}); code == 0 || !strings.Contains(stderr.String(), "codex, claude, gemini, aider") {
+		t.Fatalf("expected unknown target error, got %q", stderr.String())
+	}
+}
+
+func TestRun_NotifyGeminiHook(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	settings := DefaultPreferences()
+	settings.IncludeDir = false
+	settings.Tools = map[string]ToolPreferences{"gemini": {Content: "full"}}
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	input := `{"hook_event_name":"AfterAgent","session_id":"g-1","cwd":"/work/api","prompt":"fix the flaky test","prompt_response":"Fixed the race in the cache test."}`
+
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdin:        strings.NewReader(input),
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	if code := tool.Run([]string{"notify", "--gemini"}); code != 0 {
+		t.Fatalf("notify --gemini failed: %q", stderr.String())
+	}
+	if desktop.title != "Gemini Task Complete" || desktop.body != "Fixed the race in the cache test." {
+		t.Fatalf("unexpected notification %q / %q", desktop.title, desktop.body)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("gemini hook stdout must stay empty, got %q", stdout.String())
+	}
+}
+
+func TestRun_NotifyDisabledPerTool(t *testing.T) {
+	temp := t.TempDir()
+	settingsPath := filepath.Join(temp, "settings.json")
+	settings := DefaultPreferences()
+	settings.Tools = map[string]ToolPreferences{"aider": {Enabled: boolPtr(false)}}
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	if code := tool.Run([]string{"notify", "--aider"}); code != 0 {
+		t.Fatalf("notify --aider failed: %q", stderr.String())
+	}
+	if desktop.count != 0 || !strings.Contains(stdout.String(), "notifications disabled for aider") {
+		t.Fatalf("expected aider to be muted, got %d notifications, stdout=%q", desktop.count, stdout.String())
+	}
+}
+
+// respondingNotifier answers the approval prompt it shows, like a click on
+// one of its actions.
+type respondingNotifier struct {
+	fakeActionNotifier
+	respond func(actions []notifier.Action)
+}
+
+func (f *respondingNotifier) NotifyWithActions(title, body string, actions []notifier.Action) error {
+	_ = f.fakeActionNotifier.NotifyWithActions(title, body, actions)
+	if f.respond != nil {
+		f.respond(actions)
+	}
+	return nil
+}
+
+func TestRun_NotifyClaudeApprovalHook(t *testing.T) {
+	cases := []struct {
+		name      string
+		hookEvent string
+		tool      string
+		approval  *ClaudeApprovalPreferences
+		action    int
+		feedback  string
+		want      string
+	}{
+		{
+			name:      "permission request denied with feedback",
+			hookEvent: "PermissionRequest",
+			tool:      "Bash",
+			action:    1,
+			feedback:  "use make instead",
+			want:      `{"hookSpecificOutput":{"hookEventName":"PermissionRequest","decision":{"behavior":"deny","message":"use make instead"}}}`,
+		},
+		{
+			name:      "pre tool use allowed",
+			hookEvent: "PreToolUse",
+			tool:      "Bash",
+			approval:  &ClaudeApprovalPreferences{Tools: []string{"bash"}},
+			action:    0,
+			want:      `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"allow","permissionDecisionReason":"Approved from cc-notify"}}`,
+		},
+		{
+			name:      "pre tool use of a tool that does not wait",
+			hookEvent: "PreToolUse",
+			tool:      "Read",
+			approval:  &ClaudeApprovalPreferences{Tools: []string{"Bash"}},
+			action:    -1,
+		},
+		{
+			name:      "default decision on timeout",
+			hookEvent: "PermissionRequest",
+			tool:      "Bash",
+			approval:  &ClaudeApprovalPreferences{TimeoutSeconds: 1, Default: "deny"},
+			action:    -1,
+			want:      `{"hookSpecificOutput":{"hookEventName":"PermissionRequest","decision":{"behavior":"deny","message":"No response to the notification within 1s"}}}`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			temp := t.TempDir()
+			settingsPath := filepath.Join(temp, "settings.json")
+			settings := DefaultPreferences()
+			settings.ClaudeApproval = tc.approval
+			raw, _ := json.Marshal(settings)
+			if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+				t.Fatalf("write settings: %v", err)
+			}
+			input, _ := json.Marshal(map[string]any{
+				"hook_event_name": tc.hookEvent,
+				"session_id":      "s-1",
+				"tool_name":       tc.tool,
+				"tool_input":      map[string]string{"command": "go build ./..."},
+			})
+
+			var stdout, stderr bytes.Buffer
+			desktop := &respondingNotifier{}
+			tool := New(Options{
+				Notifier:     desktop,
+				Stdin:        bytes.NewReader(input),
+				Stdout:       &stdout,
+				Stderr:       &stderr,
+				SettingsPath: func() (string, error) { return settingsPath, nil },
+			})
+			if tc.action >= 0 {
+				desktop.respond = func(actions []notifier.Action) {
+					uri := actions[tc.action].URI
+					if tc.feedback != "" {
+						uri += "&" + notifier.FeedbackParam + "=" + url.QueryEscape(tc.feedback)
+					}
+					if err := tool.runProtocolURI(uri); err != nil {
+						t.Errorf("respond: %v", err)
+					}
+				}
+			}
+
+			if code := tool.Run([]string{"notify", "--claude"}); code != 0 || strings.Contains(stderr.String(), "warning") {
+				t.Fatalf("notify failed: code=%d stderr=%q", code, stderr.String())
+			}
+			output := ""
+			for _, line := range strings.Split(stdout.String(), "\n") {
+				if strings.HasPrefix(line, "{") {
+					output = line
+				}
+			}
+			if output != tc.want {
+				t.Fatalf("unexpected hook output:\n got %q\nwant %q", output, tc.want)
+			}
+			if tc.want == "" {
+				if desktop.actionCount != 0 || desktop.count != 1 {
+					t.Fatalf("expected a plain notification, got %d prompts and %d notifications", desktop.actionCount, desktop.count)
+				}
+				return
+			}
+			if len(desktop.actions) != 2 || desktop.actions[0].Label != "Allow" || desktop.actions[1].Input == "" {
+				t.Fatalf("unexpected actions: %+v", desktop.actions)
+			}
+			if len(desktop.dismissed) != 1 {
+				t.Fatalf("expected the prompt to be dismissed, got %v", desktop.dismissed)
+			}
+			if entries, _ := os.ReadDir(filepath.Join(temp, "approvals")); len(entries) != 0 {
+				t.Fatalf("expected the pending approval to be removed, got %d", len(entries))
+			}
+		})
+	}
+}
+
+// blockingNotifier shows the prompt and then holds on to it, like a desktop
+// backend that waits for the click.
+type blockingNotifier struct {
+	fakeActionNotifier
+	shown   chan []notifier.Action
+	release chan struct{}
+}
+
+func (f *blockingNotifier) NotifyWithActions(title, body string, actions []notifier.Action) error {
+	f.shown <- actions
+	<-f.release
+	return nil
+}
+
+func TestRun_NotifyClaudeApprovalHookAnswersWhileDesktopBlocks(t *testing.T) {
+	grace := hookDispatchGrace
+	hookDispatchGrace = 10 * time.Millisecond
+	t.Cleanup(func() { hookDispatchGrace = grace })
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	settings := DefaultPreferences()
+	settings.ClaudeApproval = &ClaudeApprovalPreferences{TimeoutSeconds: 10, Default: "deny"}
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	input, _ := json.Marshal(map[string]any{
+		"hook_event_name": "PermissionRequest",
+		"session_id":      "s-1",
+		"tool_name":       "Bash",
+		"tool_input":      map[string]string{"command": "go build ./..."},
+	})
+
+	desktop := &blockingNotifier{shown: make(chan []notifier.Action, 1), release: make(chan struct{})}
+	// The desktop lets go well after the response, for a hook that only
+	// polls once the prompt is sent.
+	time.AfterFunc(3*time.Second, func() { close(desktop.release) })
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdin:        bytes.NewReader(input),
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	// The response comes from another process, such as `cc-notify serve`
+	// answering a remote channel.
+	responder := New(Options{
+		Notifier:     &fakeActionNotifier{},
+		Stdout:       io.Discard,
+		Stderr:       io.Discard,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	go func() {
+		actions := <-desktop.shown
+		if err := responder.runProtocolURI(actions[0].URI); err != nil {
+			t.Errorf("respond: %v", err)
+		}
+	}()
+
+	start := time.Now()
+	if code := tool.Run([]string{"notify", "--claude"}); code != 0 {
+		t.Fatalf("notify failed: code=%d stderr=%q", code, stderr.String())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the hook to answer while the desktop blocks, took %s", elapsed)
+	}
+	want := `{"hookSpecificOutput":{"hookEventName":"PermissionRequest","decision":{"behavior":"allow"}}}`
+	if got := strings.TrimSpace(stdout.String()); got != want {
+		t.Fatalf("unexpected hook output:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestRunRespond_HookApprovalFirstResponseWins(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	owner := New(Options{SettingsPath: func() (string, error) { return settingsPath, nil }})
+	pending, err := owner.createPendingApproval(1, "", "", time.Minute)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Responders in separate processes, such as serve and the protocol
+	// handler, answer at the same time.
+	decisions := []approvalDecision{approvalProceed, approvalReject, approvalProceed, approvalReject}
+	errs := make(chan error, len(decisions))
+	for _, decision := range decisions {
+		responder := New(Options{
+			Notifier:     &fakeActionNotifier{},
+			Stdout:       io.Discard,
+			Stderr:       io.Discard,
+			SettingsPath: func() (string, error) { return settingsPath, nil },
+		})
+		go func() {
+			errs <- responder.runRespond([]string{"--id", pending.ID, "--decision", string(decision)})
+		}()
+	}
+	answered := 0
+	for range decisions {
+		err := <-errs
+		switch {
+		case err == nil:
+			answered++
+		case !strings.Contains(err.Error(), "already answered"):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if answered != 1 {
+		t.Fatalf("expected exactly one response to be recorded, got %d", answered)
+	}
+	if _, err := owner.loadHookResponse(pending.ID); err != nil {
+		t.Fatalf("load response: %v", err)
+	}
+
+	if err := owner.deletePendingApproval(pending.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(filepath.Dir(settingsPath), "approvals")); len(entries) != 0 {
+		t.Fatalf("expected the approval and its response to be removed, got %d files", len(entries))
+	}
+}
+
+// unreachableExecutor cannot answer any session, like the tmux executor
+// for an agent outside tmux.
+type unreachableExecutor struct{ fakeApprovalExecutor }
+
+func (unreachableExecutor) CanDeliver(int) bool { return false }
+
+func TestRun_NotifyPausedWithoutDeliveryHasNoButtons(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	var stdout, stderr bytes.Buffer
+	actionNotifier := &fakeActionNotifier{}
+	tool := New(Options{
+		Notifier:         actionNotifier,
+		ApprovalExecutor: &unreachableExecutor{},
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
+	})
+
+	if code := tool.Run([]string{"notify", `{"type":"agent-turn-paused","summary":"need approval"}`}); code != 0 {
+		t.Fatalf("expected zero exit code, stderr=%q", stderr.String())
+	}
+	if actionNotifier.actionCount != 0 || actionNotifier.count != 1 {
+		t.Fatalf("expected a plain notification, got %d prompts and %d notifications", actionNotifier.actionCount, actionNotifier.count)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(filepath.Dir(settingsPath), "approvals")); len(entries) != 0 {
+		t.Fatalf("expected no pending approval, got %d", len(entries))
+	}
+}
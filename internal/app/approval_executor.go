@@ -1,5 +1,10 @@
 package app
 
+import (
+	"strings"
+	"unicode"
+)
+
 // approvalDecision is the user choice for a paused run.
 type approvalDecision string
 
@@ -13,6 +18,47 @@ const (
 // ApprovalExecutor applies a decision to the paused interactive session.
 // Current implementation uses foreground terminal key injection.
 // A future broker-based flow can implement this interface without changing app command handling.
+// feedback is only used with approvalReject: it is typed into the session
+// after rejecting, telling the agent what to do instead.
 type ApprovalExecutor interface {
-	Deliver(parentPID int, decision approvalDecision) error
+	Deliver(parentPID int, decision approvalDecision, feedback string) error
+}
+
+// approvalTarget is implemented by executors that reach only some
+// sessions, such as those running in tmux.
+type approvalTarget interface {
+	// CanDeliver reports whether a decision can be delivered to the
+	// session of parentPID.
+	CanDeliver(parentPID int) bool
+}
+
+// canDeliverApproval reports whether the approval executor can answer the
+// session of parentPID; approvals it cannot answer get no buttons.
+func (a *App) canDeliverApproval(parentPID int) bool {
+	target, ok := a.approvalExecutor.(approvalTarget)
+	return !ok || target.CanDeliver(parentPID)
+}
+
+// maxFeedbackRunes caps the feedback typed into the session.
+const maxFeedbackRunes = 2000
+
+// normalizeFeedback flattens feedback to one line of printable text, so
+// typing it can neither submit early nor send control keys.
+func normalizeFeedback(raw string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range raw {
+		if n == maxFeedbackRunes {
+			break
+		}
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			r = ' '
+		case unicode.IsControl(r) || r == unicode.ReplacementChar:
+			continue
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return strings.TrimSpace(b.String())
 }
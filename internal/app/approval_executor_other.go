@@ -2,14 +2,124 @@
 
 package app
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
 
-type noopApprovalExecutor struct{}
+// tmuxApprovalExecutor answers an agent running in a tmux pane by sending
+// the keys of the decision to that pane. Outside tmux there is no way to
+// type into another terminal, so approvals there get no buttons.
+type tmuxApprovalExecutor struct {
+	// run runs a command and returns its standard output.
+	run func(name string, args ...string) ([]byte, error)
+	// parentOf returns the parent process id of pid, or 0.
+	parentOf func(pid int) int
+}
 
 func newDefaultApprovalExecutor() ApprovalExecutor {
-	return noopApprovalExecutor{}
+	return tmuxApprovalExecutor{run: runOutput, parentOf: parentPID}
+}
+
+func runOutput(name string, args ...string) ([]byte, error) {
+	output, err := exec.Command(name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return output, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return output, err
+}
+
+// parentPID reads the parent of pid from /proc, or asks ps where there is
+// no /proc.
+func parentPID(pid int) int {
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat"); err == nil {
+		// The command name in parentheses may hold spaces; the fields
+		// after it are state and parent id.
+		if i := strings.LastIndexByte(string(data), ')'); i >= 0 {
+			if fields := strings.Fields(string(data[i+1:])); len(fields) > 1 {
+				ppid, _ := strconv.Atoi(fields[1])
+				return ppid
+			}
+		}
+		return 0
+	}
+	output, err := exec.Command("ps", "-o", "ppid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return ppid
+}
+
+func (e tmuxApprovalExecutor) CanDeliver(parentPID int) bool {
+	_, err := e.findPane(parentPID)
+	return err == nil
+}
+
+func (e tmuxApprovalExecutor) Deliver(parentPID int, decision approvalDecision, feedback string) error {
+	if parentPID <= 0 {
+		return fmt.Errorf("cannot deliver approval: invalid parent process id")
+	}
+
+	var key string
+	switch decision {
+	case approvalProceed, approvalApprove:
+		key = "y"
+	case approvalProceedAlways:
+		key = "p"
+	case approvalReject:
+		key = "Escape"
+	default:
+		return fmt.Errorf("cannot deliver approval: unsupported decision %q", decision)
+	}
+	feedback = normalizeFeedback(feedback)
+	if decision != approvalReject {
+		feedback = ""
+	}
+
+	pane, err := e.findPane(parentPID)
+	if err != nil {
+		return err
+	}
+	if _, err := e.run("tmux", "send-keys", "-t", pane, key); err != nil {
+		return fmt.Errorf("deliver approval decision: %w", err)
+	}
+	if feedback != "" {
+		time.Sleep(300 * time.Millisecond)
+		if _, err := e.run("tmux", "send-keys", "-t", pane, "-l", feedback); err != nil {
+			return fmt.Errorf("deliver approval feedback: %w", err)
+		}
+		if _, err := e.run("tmux", "send-keys", "-t", pane, "Enter"); err != nil {
+			return fmt.Errorf("deliver approval feedback: %w", err)
+		}
+	}
+	return nil
 }
 
-func (noopApprovalExecutor) Deliver(_ int, _ approvalDecision) error {
-	return fmt.Errorf("approval delivery is only supported on windows")
+// findPane returns the tmux pane whose shell is an ancestor of pid.
+func (e tmuxApprovalExecutor) findPane(pid int) (string, error) {
+	output, err := e.run("tmux", "list-panes", "-a", "-F", "#{pane_pid} #{pane_id}")
+	if err != nil {
+		return "", fmt.Errorf("cannot deliver approval: the agent does not run in tmux")
+	}
+	panes := map[int]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		panePID, paneID, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if n, err := strconv.Atoi(panePID); ok && err == nil {
+			panes[n] = paneID
+		}
+	}
+	for i := 0; i < 16 && pid > 1; i++ {
+		if pane, ok := panes[pid]; ok {
+			return pane, nil
+		}
+		pid = e.parentOf(pid)
+	}
+	return "", fmt.Errorf("cannot deliver approval: the agent does not run in tmux")
 }
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTmuxApprovalExecutor_SendsKeysToAgentPane(t *testing.T) {
+	var sent []string
+	executor := tmuxApprovalExecutor{
+		run: func(name string, args ...string) ([]byte, error) {
+			if args[0] == "list-panes" {
+				return []byte("100 %0\n200 %3\n"), nil
+			}
+			sent = append(sent, strings.Join(args, " "))
+			return nil, nil
+		},
+		// The agent 210 runs under the shell 200 of pane %3.
+		parentOf: func(pid int) int { return map[int]int{210: 200, 200: 1}[pid] },
+	}
+
+	if !executor.CanDeliver(210) || executor.CanDeliver(300) {
+		t.Fatal("expected only the agent inside a pane to be reachable")
+	}
+	if err := executor.Deliver(210, approvalReject, "use make"); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+	want := []string{"send-keys -t %3 Escape", "send-keys -t %3 -l use make", "send-keys -t %3 Enter"}
+	if strings.Join(sent, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected keys:\n got %q\nwant %q", sent, want)
+	}
+}
+
+func TestTmuxApprovalExecutor_OutsideTmux(t *testing.T) {
+	executor := tmuxApprovalExecutor{
+		run:      func(string, ...string) ([]byte, error) { return nil, errors.New("no server running") },
+		parentOf: func(int) int { return 0 },
+	}
+	if executor.CanDeliver(210) {
+		t.Fatal("expected no delivery without a tmux server")
+	}
+	if err := executor.Deliver(210, approvalProceed, ""); err == nil || !strings.Contains(err.Error(), "tmux") {
+		t.Fatalf("expected an error naming tmux, got %v", err)
+	}
+}
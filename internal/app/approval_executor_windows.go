@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
-	"unicode/utf16"
 )
 
 type windowsApprovalExecutor struct{}
@@ -16,7 +15,41 @@ func newDefaultApprovalExecutor() ApprovalExecutor {
 	return windowsApprovalExecutor{}
 }
 
-func (windowsApprovalExecutor) Deliver(parentPID int, decision approvalDecision) error {
+// typeFeedbackScript defines Send-Text, which types arbitrary Unicode through
+// SendInput. WScript.Shell.SendKeys treats +^%~(){} as modifiers and cannot
+// type characters missing from the keyboard layout.
+const typeFeedbackScript = `Add-Type -TypeDefinition @"
+using System;
+using System.Runtime.InteropServices;
+public static class CcNotifyKeyboard {
+  [StructLayout(LayoutKind.Sequential)]
+  struct KEYBDINPUT { public ushort wVk; public ushort wScan; public uint dwFlags; public uint time; public IntPtr dwExtraInfo; }
+  [StructLayout(LayoutKind.Sequential)]
+  struct MOUSEINPUT { public int dx; public int dy; public uint mouseData; public uint dwFlags; public uint time; public IntPtr dwExtraInfo; }
+  [StructLayout(LayoutKind.Explicit)]
+  struct INPUTUNION { [FieldOffset(0)] public KEYBDINPUT ki; [FieldOffset(0)] public MOUSEINPUT mi; }
+  [StructLayout(LayoutKind.Sequential)]
+  struct INPUT { public uint type; public INPUTUNION u; }
+  [DllImport("user32.dll", SetLastError = true)]
+  static extern uint SendInput(uint count, INPUT[] inputs, int size);
+  public static void Type(string text) {
+    foreach (char c in text) {
+      INPUT[] inputs = new INPUT[2];
+      inputs[0].type = 1;
+      inputs[0].u.ki.wScan = c;
+      inputs[0].u.ki.dwFlags = 0x0004;
+      inputs[1].type = 1;
+      inputs[1].u.ki.wScan = c;
+      inputs[1].u.ki.dwFlags = 0x0004 | 0x0002;
+      SendInput(2, inputs, Marshal.SizeOf(typeof(INPUT)));
+    }
+  }
+}
+"@
+function Send-Text([string]$text) { [CcNotifyKeyboard]::Type($text) }
+`
+
+func (windowsApprovalExecutor) Deliver(parentPID int, decision approvalDecision, feedback string) error {
 	if parentPID <= 0 {
 		return fmt.Errorf("cannot deliver approval: invalid parent process id")
 	}
@@ -32,12 +65,18 @@ func (windowsApprovalExecutor) Deliver(parentPID int, decision approvalDecision)
 	default:
 		return fmt.Errorf("cannot deliver approval: unsupported decision %q", decision)
 	}
+	feedback = normalizeFeedback(feedback)
+	if decision != approvalReject {
+		feedback = ""
+	}
 
 	script := fmt.Sprintf(
 		`$ErrorActionPreference = 'Stop'
 $seed = %d
 $keys = '%s'
+$feedback = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
 $wshell = New-Object -ComObject WScript.Shell
+%s
 
 function Get-ParentPid([int]$pid) {
   try {
@@ -60,6 +99,12 @@ foreach ($p in $pids) {
   if ($wshell.AppActivate([int]$p)) {
     Start-Sleep -Milliseconds 120
     $wshell.SendKeys($keys)
+    if ($feedback -ne '') {
+      Start-Sleep -Milliseconds 300
+      Send-Text $feedback
+      Start-Sleep -Milliseconds 100
+      $wshell.SendKeys('{ENTER}')
+    }
     exit 0
   }
 }
@@ -68,6 +113,8 @@ throw 'no interactive terminal session found for pending approval'
 `,
 		parentPID,
 		keys,
+		base64.StdEncoding.EncodeToString([]byte(feedback)),
+		feedbackHelper(feedback),
 	)
 
 	cmd := exec.Command("powershell.exe",
@@ -87,12 +134,11 @@ throw 'no interactive terminal session found for pending approval'
 	return nil
 }
 
-func encodePowerShellCommand(command string) string {
-	utf16Text := utf16.Encode([]rune(command))
-	utf16LEBytes := make([]byte, len(utf16Text)*2)
-	for i, code := range utf16Text {
-		utf16LEBytes[i*2] = byte(code)
-		utf16LEBytes[i*2+1] = byte(code >> 8)
+// feedbackHelper returns the typing helper only when there is text to type,
+// since compiling it adds a noticeable delay.
+func feedbackHelper(feedback string) string {
+	if feedback == "" {
+		return ""
 	}
-	return base64.StdEncoding.EncodeToString(utf16LEBytes)
+	return typeFeedbackScript
 }
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"cc-notify/internal/event"
+	"cc-notify/internal/source"
+)
+
+// snapshotMaxAge is how long the snapshot of a turn that never completed,
+// or of an idle Codex session, is kept.
+const snapshotMaxAge = 24 * time.Hour
+
+// trackChanges keeps the snapshot of the working tree that the change
+// summary of a turn is counted from, and attaches the summary to a
+// completed turn. Agents with a turn start event snapshot on it; for the
+// others the first event after a completed turn starts the next one.
+//
+// It reports whether the event is still to be notified: the start of a
+// turn is not, unless its hook event was chosen in the agent's events.
+func (a *App) trackChanges(prefs Preferences, src source.Adapter, payload *event.Payload) bool {
+	path, err := a.snapshotPath(src.Name(), *payload)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+		return true
+	}
+	if path == "" {
+		return true
+	}
+	starter, hasStart := src.(source.TurnStarter)
+
+	switch payload.Type {
+	case event.TypePromptSubmit:
+		a.saveSnapshot(path, payload.CWD)
+		return !hasStart || slices.ContainsFunc(prefs.Tools[src.Name()].Events, func(name string) bool {
+			return strings.EqualFold(name, starter.TurnStartEvent())
+		})
+	case "agent-turn-complete":
+		if snap := a.loadSnapshot(path); snap != nil {
+			changes, err := snap.Changes()
+			if err != nil {
+				fmt.Fprintf(a.stderr, "warning: %v\n", err)
+			} else {
+				payload.Changes = &changes
+			}
+		}
+		if hasStart {
+			a.removeSnapshot(path)
+		} else {
+			a.saveSnapshot(path, payload.CWD)
+		}
+	case event.TypeSessionEnd:
+		a.removeSnapshot(path)
+	default:
+		if !hasStart {
+			if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+				a.saveSnapshot(path, payload.CWD)
+			}
+		}
+	}
+	return true
+}
+
+// snapshotPath returns where the snapshot of the session of payload is
+// kept, next to the pending approvals, or "" for an event without a
+// directory.
+func (a *App) snapshotPath(sourceName string, payload event.Payload) (string, error) {
+	if strings.TrimSpace(payload.CWD) == "" {
+		return "", nil
+	}
+	settingsPath, err := a.settingsPath()
+	if err != nil {
+		return "", fmt.Errorf("resolve settings path: %w", err)
+	}
+	key := sourceName + "|" + firstNonEmptyString(payload.SessionID, payload.TranscriptPath, strings.ToLower(strings.TrimSpace(payload.CWD)))
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(filepath.Dir(settingsPath), "snapshots", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// saveSnapshot snapshots the repository at dir into path and removes the
+// snapshots left behind by turns that never completed. A directory outside
+// a repository has no snapshot.
+func (a *App) saveSnapshot(path, dir string) {
+	a.pruneSnapshots(filepath.Dir(path))
+	snap, err := event.SnapshotTree(dir)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	}
+	if snap == nil {
+		a.removeSnapshot(path)
+		return
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: marshal snapshot: %v\n", err)
+		return
+	}
+	if err := a.mkdirPrivate(filepath.Dir(path)); err != nil {
+		fmt.Fprintf(a.stderr, "warning: create snapshots directory: %v\n", err)
+		return
+	}
+	if err := a.writeFile(path, data, privateFileMode); err != nil {
+		fmt.Fprintf(a.stderr, "warning: write snapshot: %v\n", err)
+	}
+}
+
+// loadSnapshot reads the snapshot at path, or returns nil when there is
+// none or it cannot be read.
+func (a *App) loadSnapshot(path string) *event.TreeSnapshot {
+	data, err := a.readFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(a.stderr, "warning: read snapshot: %v\n", err)
+		}
+		return nil
+	}
+	var snap event.TreeSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil || snap.Root == "" {
+		fmt.Fprintf(a.stderr, "warning: snapshot %s is invalid\n", filepath.Base(path))
+		a.removeSnapshot(path)
+		return nil
+	}
+	return &snap
+}
+
+func (a *App) removeSnapshot(path string) {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(a.stderr, "warning: remove snapshot: %v\n", err)
+	}
+}
+
+// pruneSnapshots removes the snapshots in dir older than snapshotMaxAge.
+func (a *App) pruneSnapshots(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if time.Since(info.ModTime()) > snapshotMaxAge {
+			_ = os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
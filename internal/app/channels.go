@@ -0,0 +1,228 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"cc-notify/internal/i18n"
+	"cc-notify/internal/notifier"
+	"cc-notify/internal/redact"
+)
+
+// desktopChannel is the channel name of the local notifier in routes.
+const desktopChannel = "desktop"
+
+// remoteChannels builds the remote channels enabled in preferences.
+func remoteChannels(p Preferences) ([]notifier.Channel, error) {
+	var channels []notifier.Channel
+	var errs []error
+
+	if p.Webhook != nil {
+		svc, err := notifier.NewWebhook(notifier.WebhookConfig{
+			URL:     p.Webhook.URL,
+			Headers: p.Webhook.Headers,
+			Timeout: time.Duration(p.Webhook.TimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			channels = append(channels, notifier.Channel{Name: "webhook", Service: svc})
+		}
+	}
+
+	defaults := p.urlDefaults()
+	if p.Ntfy != nil {
+		svc, err := notifier.NewNtfy(notifier.NtfyConfig{
+			Server:        p.Ntfy.Server,
+			Topic:         p.Ntfy.Topic,
+			Token:         p.Ntfy.Token,
+			Priority:      p.Ntfy.Priority,
+			Tags:          p.Ntfy.Tags,
+			CallbackURL:   defaults.CallbackURL,
+			CallbackToken: defaults.CallbackToken,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			channels = append(channels, notifier.Channel{Name: "ntfy", Service: svc})
+		}
+	}
+
+	if p.Telegram != nil {
+		svc, err := newTelegramChannel(p.Telegram, p.lang())
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			channels = append(channels, notifier.Channel{Name: "telegram", Service: svc})
+		}
+	}
+
+	if p.Email != nil {
+		svc, err := notifier.NewEmail(notifier.EmailConfig{
+			Host:     p.Email.Host,
+			Port:     p.Email.Port,
+			Username: p.Email.Username,
+			Password: p.Email.Password,
+			From:     p.Email.From,
+			To:       p.Email.To,
+			Security: p.Email.Security,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			channels = append(channels, notifier.Channel{Name: "email", Service: svc})
+		}
+	}
+
+	chatWebhooks := []struct {
+		name  string
+		prefs *ChatWebhookPreferences
+		build func(notifier.ChatWebhookConfig) (notifier.Service, error)
+	}{
+		{name: "slack", prefs: p.Slack, build: notifier.NewSlack},
+		{name: "discord", prefs: p.Discord, build: notifier.NewDiscord},
+		{name: "teams", prefs: p.Teams, build: notifier.NewTeams},
+	}
+	for _, chat := range chatWebhooks {
+		if chat.prefs == nil {
+			continue
+		}
+		svc, err := chat.build(notifier.ChatWebhookConfig{URL: chat.prefs.URL})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		channels = append(channels, notifier.Channel{Name: chat.name, Service: svc})
+	}
+
+	for _, raw := range p.Channels {
+		if notifier.IsDesktopURL(raw) {
+			continue
+		}
+		ch, err := notifier.NewChannelFromURL(raw, defaults)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		channels = append(channels, ch)
+	}
+
+	return channels, errors.Join(errs...)
+}
+
+// urlDefaults returns the settings channel URLs inherit from preferences.
+func (p Preferences) urlDefaults() notifier.URLDefaults {
+	callbackURL := ""
+	if p.Responder != nil {
+		callbackURL = p.Responder.callbackURL()
+	}
+	return notifier.URLDefaults{
+		Desktop: notifier.Config{
+			Mode:          p.Mode,
+			ToastAppID:    p.ToastAppID,
+			TerminalStyle: p.TerminalStyle,
+			TerminalPID:   os.Getppid(),
+			Toast:         p.toastOptions(),
+		},
+		CallbackURL:   callbackURL,
+		CallbackToken: p.Responder.token(),
+		Language:      p.lang(),
+	}
+}
+
+// desktopConfig applies the desktop:// entry of Channels to base. enabled is
+// false when Channels lists only other channels, which turns the desktop
+// notifier off. An invalid entry leaves base in place and is reported.
+func (p Preferences) desktopConfig(base notifier.Config) (cfg notifier.Config, enabled bool, err error) {
+	if len(p.Channels) == 0 {
+		return base, true, nil
+	}
+	for _, raw := range p.Channels {
+		if notifier.IsDesktopURL(raw) {
+			cfg, err := notifier.ParseDesktopURL(raw, base)
+			return cfg, true, err
+		}
+	}
+	return base, false, nil
+}
+
+func newTelegramChannel(p *TelegramPreferences, lang i18n.Language) (*notifier.Telegram, error) {
+	return notifier.NewTelegram(notifier.TelegramConfig{
+		APIBase:  p.APIBase,
+		Token:    p.Token,
+		ChatID:   p.ChatID,
+		Language: lang,
+	})
+}
+
+// bodyRenderer renders the title and body of a message again with another
+// length limit in runes, 0 keeping the configured one, after scrub has
+// been applied to the agent text. Scrubbing before the text is shortened
+// keeps secrets from being cut past recognition.
+type bodyRenderer func(maxLength int, scrub func(string) string) (title, body string)
+
+// dispatch sends msg through the routed fan-out of desktop plus every
+// configured remote channel and returns how many channels accepted it. A nil
+// desktop leaves the local notifier out, e.g. while the terminal prompt is
+// used instead. Secrets are redacted per channel, more strictly on remote
+// ones, and channels with their own length limit get the body from render.
+// Failing channels do not stop the others; all failures are reported
+// together.
+func (a *App) dispatch(p Preferences, desktop notifier.Service, msg notifier.Message, render bodyRenderer) (int, error) {
+	var channels []notifier.Channel
+	if desktop != nil {
+		channels = append(channels, notifier.Channel{Name: desktopChannel, Service: desktop})
+	}
+	remote, configErr := remoteChannels(p)
+	channels = append(channels, remote...)
+	redactor, localLevel, remoteLevel, redactErr := p.redaction()
+	for i := range channels {
+		level := remoteLevel
+		if channels[i].Name == desktopChannel {
+			level = localLevel
+		}
+		limit := p.channelMaxLength(channels[i].Name)
+		if level == redact.Off && (limit == 0 || render == nil) {
+			continue
+		}
+		scrub := func(text string) string { return redactor.Redact(text, level) }
+		channels[i].Format = func(m notifier.Message) notifier.Message {
+			if render != nil {
+				m.Title, m.Body = render(limit, scrub)
+			}
+			return redactMessage(m, scrub)
+		}
+	}
+
+	sent, err := notifier.NewFanout(channels, p.notifierRoutes()).Dispatch(msg)
+	out := a.diagnostics(msg.Source)
+	for _, name := range sent {
+		switch {
+		case name != desktopChannel:
+			fmt.Fprintf(out, "notification sent: %s (%s via %s)\n", msg.EventType, msg.Source, name)
+		case len(msg.Actions) > 0:
+			fmt.Fprintf(out, "approval prompt sent: %s (%s)\n", msg.EventType, msg.Source)
+		default:
+			fmt.Fprintf(out, "notification sent: %s (%s)\n", msg.EventType, msg.Source)
+		}
+	}
+	return len(sent), errors.Join(configErr, redactErr, err)
+}
+
+// redactMessage applies scrub to every text of m that a channel may show.
+func redactMessage(m notifier.Message, scrub func(string) string) notifier.Message {
+	m.Title = scrub(m.Title)
+	m.Body = scrub(m.Body)
+	m.Text = scrub(m.Text)
+	if len(m.Actions) > 0 {
+		actions := make([]notifier.Action, len(m.Actions))
+		for i, action := range m.Actions {
+			action.Label = scrub(action.Label)
+			actions[i] = action
+		}
+		m.Actions = actions
+	}
+	return m
+}
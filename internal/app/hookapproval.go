@@ -0,0 +1,215 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"cc-notify/internal/event"
+	"cc-notify/internal/i18n"
+	"cc-notify/internal/notifier"
+)
+
+// approvalHookMargin is added to the approval timeout for the timeout of
+// the installed hook, so Claude Code does not kill a hook still answering.
+const approvalHookMargin = 30 * time.Second
+
+// hookApprovalPoll is how often a waiting hook looks for the response.
+var hookApprovalPoll = 250 * time.Millisecond
+
+// hookDispatchGrace is how long an answered hook waits for the channels
+// still sending the prompt, so their report is not cut off.
+var hookDispatchGrace = time.Second
+
+// dispatchResult is the outcome of sending a prompt, see App.dispatch.
+type dispatchResult struct {
+	sent int
+	err  error
+}
+
+// waitsForDecision reports whether payload comes from a hook that waits
+// for the user's decision and answers it on stdout.
+func waitsForDecision(payload event.Payload) bool {
+	if payload.Type != "agent-turn-paused" {
+		return false
+	}
+	return payload.HookEvent == event.ClaudePreToolUse || payload.HookEvent == event.ClaudePermissionRequest
+}
+
+// handleHookApproval asks for an approval on the desktop and the remote
+// channels, waits for the response and writes it for Claude Code. Without
+// a response in time the configured default decision is given.
+//
+// The prompt is sent in the background while the hook already waits, as a
+// desktop notifier may block until its prompt is gone and the response can
+// come from any channel. Claude Code ignores the output of a hook that
+// fails, so errors are reported as warnings and the hook still answers.
+func (a *App) handleHookApproval(payload event.Payload, msg notifier.Message, prefs Preferences, render bodyRenderer) error {
+	settings := prefs.ClaudeApproval
+	timeout := settings.timeout()
+	decision, reason := settings.defaultDecision(), fmt.Sprintf("No response to the notification within %s", timeout)
+
+	// The terminal belongs to Claude Code, so there is no asking there.
+	desktop, actions, err := a.pauseNotifier(prefs)
+	a.warn(err)
+	if prefs.PausePrompt == "terminal" || !actions {
+		desktop = nil
+	}
+
+	pending, err := a.createPendingApproval(os.Getppid(), msg.Tag, msg.Group, timeout)
+	if err != nil {
+		a.warn(fmt.Errorf("create pending approval: %w", err))
+		return a.writeHookDecision(payload.HookEvent, decision, reason)
+	}
+	msg.Actions = buildHookActions(prefs.lang(), pending.ID)
+	msg.Pending, _ = a.pendingApprovalPath(pending.ID)
+	dispatched := make(chan dispatchResult, 1)
+	go func() {
+		sent, err := a.dispatch(prefs, desktop, msg, render)
+		dispatched <- dispatchResult{sent: sent, err: err}
+		close(dispatched)
+	}()
+	response, answered, prompted := a.awaitHookResponse(pending.ID, timeout, dispatched)
+	if answered {
+		decision, reason = event.ClaudeAllow, "Approved from cc-notify"
+		if response.Decision == approvalReject {
+			decision, reason = event.ClaudeDeny, firstNonEmptyString(response.Feedback, "Rejected from cc-notify")
+		}
+	} else if prompted {
+		a.dismissApprovalPrompt(pending)
+	}
+	select {
+	case result, ok := <-dispatched:
+		if ok {
+			a.warn(result.err)
+		}
+	case <-time.After(hookDispatchGrace):
+	}
+	a.warn(a.deletePendingApproval(pending.ID))
+	return a.writeHookDecision(payload.HookEvent, decision, reason)
+}
+
+// awaitHookResponse waits for the response to the pending approval id
+// while dispatched reports on sending the prompt. It gives up at the
+// timeout, when the approval is gone, or as soon as no channel took the
+// prompt, which is when prompted is false.
+func (a *App) awaitHookResponse(id string, timeout time.Duration, dispatched <-chan dispatchResult) (response hookResponse, answered, prompted bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case result, ok := <-dispatched:
+			if ok {
+				a.warn(result.err)
+				if result.sent == 0 {
+					return hookResponse{}, false, false
+				}
+			}
+		default:
+		}
+		// A response being written does not parse yet; it is read again.
+		if response, err := a.loadHookResponse(id); err == nil {
+			return response, true, true
+		}
+		if _, err := a.loadPendingApproval(id); errors.Is(err, os.ErrNotExist) {
+			return hookResponse{}, false, true
+		}
+		if !time.Now().Before(deadline) {
+			return hookResponse{}, false, true
+		}
+		time.Sleep(min(hookApprovalPoll, time.Until(deadline)))
+	}
+}
+
+// hookResponse is the answer to an approval a hook waits for. It is kept
+// next to the pending approval, in <id>.decision.
+type hookResponse struct {
+	Decision approvalDecision `json:"decision"`
+	Feedback string           `json:"feedback,omitempty"`
+}
+
+// recordHookResponse records the response to the hook approval id. serve,
+// a toast listener and the protocol handler may answer at the same time
+// from separate processes, so the file is created exclusively: the first
+// response wins and the others fail as already answered.
+func (a *App) recordHookResponse(id string, response hookResponse) error {
+	path, err := a.pendingApprovalPath(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("marshal approval response: %w", err)
+	}
+	f, err := os.OpenFile(hookResponsePath(path), os.O_WRONLY|os.O_CREATE|os.O_EXCL, privateFileMode)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("approval request already answered: %s", id)
+	}
+	if err != nil {
+		return fmt.Errorf("write approval response: %w", err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write approval response: %w", err)
+	}
+	return nil
+}
+
+func (a *App) loadHookResponse(id string) (hookResponse, error) {
+	path, err := a.pendingApprovalPath(id)
+	if err != nil {
+		return hookResponse{}, err
+	}
+	data, err := a.readFile(hookResponsePath(path))
+	if err != nil {
+		return hookResponse{}, fmt.Errorf("read approval response: %w", err)
+	}
+	var response hookResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return hookResponse{}, fmt.Errorf("parse approval response: %w", err)
+	}
+	if response.Decision == "" {
+		return hookResponse{}, fmt.Errorf("approval response is invalid: %s", id)
+	}
+	return response, nil
+}
+
+// hookResponsePath returns where the response to the pending approval at
+// path is recorded.
+func hookResponsePath(path string) string {
+	return strings.TrimSuffix(path, ".json") + ".decision"
+}
+
+func (a *App) writeHookDecision(hookEvent, decision, reason string) error {
+	out, err := event.ClaudePermissionOutput(hookEvent, decision, reason)
+	if err != nil {
+		a.warn(err)
+		return nil
+	}
+	if out != nil {
+		fmt.Fprintf(a.stdout, "%s\n", out)
+	}
+	return nil
+}
+
+func (a *App) warn(err error) {
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	}
+}
+
+// buildHookActions returns the actions of an approval a hook waits for.
+// Hooks can only allow or deny a single call, so there is no "don't ask
+// again" action.
+func buildHookActions(l i18n.Language, id string) []notifier.Action {
+	return []notifier.Action{
+		{Label: l.T("Allow"), URI: approvalActionURI(id, approvalProceed)},
+		{Label: l.T("Deny"), URI: approvalActionURI(id, approvalReject), Input: l.T("Tell Claude what to do instead")},
+	}
+}
@@ -0,0 +1,90 @@
+//go:build linux
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cc-notify/internal/notifier"
+)
+
+// ensureToastShortcut registers the toast AppUserModelID on the Windows host
+// when cc-notify runs inside WSL. The shortcut opens the WSL binary. On
+// other Linux systems there is nothing to register.
+func ensureToastShortcut(exePath, appID string) error {
+	host, ok := notifier.DetectWSL()
+	if !ok {
+		return nil
+	}
+	return runPowerShellScript(host.PowerShell, toastShortcutScript("wsl.exe", wslExecArgs(host.Distro, exePath), appID))
+}
+
+// ensureURIProtocol routes cc-notify:// activations on the Windows host back
+// into this WSL distribution, so toast and popup buttons reach exePath. A
+// handler of a Windows install of cc-notify is left in place.
+func ensureURIProtocol(exePath string) error {
+	host, ok := notifier.DetectWSL()
+	if !ok {
+		return nil
+	}
+	command := wslProtocolCommand(host.Distro, exePath)
+	current, err := powerShellOutput(host.PowerShell, uriProtocolCommandScript)
+	if err != nil {
+		return fmt.Errorf("read the cc-notify:// handler: %w", err)
+	}
+	if err := checkProtocolHandler(current, command); err != nil {
+		return err
+	}
+	return runPowerShellScript(host.PowerShell, uriProtocolScript("", command))
+}
+
+// checkProtocolHandler reports an error when the registered handler current
+// belongs to something other than a WSL install of cc-notify. Handlers of
+// other distributions are replaced; the last install wins.
+func checkProtocolHandler(current, command string) error {
+	if current == "" || current == command || strings.HasPrefix(strings.ToLower(current), "wsl.exe ") {
+		return nil
+	}
+	return fmt.Errorf("cc-notify:// already opens %s on Windows; it is kept, so approvals in WSL get no buttons", current)
+}
+
+// desktopActionsReachUs reports whether desktop notification buttons come
+// back to this binary. In WSL they go through the host's cc-notify://
+// handler, which may belong to a Windows install.
+func desktopActionsReachUs() bool {
+	host, ok := notifier.DetectWSL()
+	if !ok {
+		return true
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	current, err := powerShellOutput(host.PowerShell, uriProtocolCommandScript)
+	return err == nil && current == wslProtocolCommand(host.Distro, exePath)
+}
+
+// wslProtocolCommand is the Windows command line that hands a protocol URI to
+// exePath inside distro. --exec skips the Linux shell, so the URI reaches
+// cc-notify verbatim.
+func wslProtocolCommand(distro, exePath string) string {
+	return "wsl.exe " + wslExecArgs(distro, exePath) + ` "%1"`
+}
+
+func wslExecArgs(distro, exePath string) string {
+	args := "--exec " + quoteWindowsArg(exePath)
+	if distro != "" {
+		args = "-d " + quoteWindowsArg(distro) + " " + args
+	}
+	return args
+}
+
+// quoteWindowsArg quotes arg for a Windows command line when needed.
+func quoteWindowsArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWSLProtocolCommand(t *testing.T) {
+	cases := []struct {
+		distro, exe, want string
+	}{
+		{distro: "Ubuntu", exe: "/home/dev/bin/cc-notify", want: `wsl.exe -d Ubuntu --exec /home/dev/bin/cc-notify "%1"`},
+		{distro: "", exe: "/home/dev/bin/cc-notify", want: `wsl.exe --exec /home/dev/bin/cc-notify "%1"`},
+		{distro: "My Distro", exe: "/opt/cc notify/cc-notify", want: `wsl.exe -d "My Distro" --exec "/opt/cc notify/cc-notify" "%1"`},
+	}
+	for _, tc := range cases {
+		if got := wslProtocolCommand(tc.distro, tc.exe); got != tc.want {
+			t.Fatalf("wslProtocolCommand(%q, %q) = %q, want %q", tc.distro, tc.exe, got, tc.want)
+		}
+	}
+}
+
+func TestURIProtocolScript_EscapesCommand(t *testing.T) {
+	script := uriProtocolScript("", `wsl.exe --exec "/home/o'neil/cc-notify" "%1"`)
+	if !strings.Contains(script, `$command = 'wsl.exe --exec "/home/o''neil/cc-notify" "%1"'`) {
+		t.Fatalf("expected the command as an escaped PowerShell literal, got:\n%s", script)
+	}
+	if !strings.Contains(script, "if ($iconPath -ne '')") {
+		t.Fatalf("expected the icon to be optional, got:\n%s", script)
+	}
+}
+
+func TestCheckProtocolHandler(t *testing.T) {
+	command := `wsl.exe -d Ubuntu --exec /home/dev/bin/cc-notify "%1"`
+	cases := []struct {
+		current string
+		keep    bool
+	}{
+		{current: ""},
+		{current: command},
+		{current: `wsl.exe -d Debian --exec /usr/local/bin/cc-notify "%1"`},
+		{current: `"C:\Users\dev\cc-notify\cc-notify.exe" "%1"`, keep: true},
+	}
+	for _, tc := range cases {
+		err := checkProtocolHandler(tc.current, command)
+		if (err != nil) != tc.keep {
+			t.Fatalf("checkProtocolHandler(%q) = %v, want kept=%v", tc.current, err, tc.keep)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-//go:build !windows
+//go:build !windows && !linux
 
 package app
 
@@ -9,3 +9,7 @@ func ensureToastShortcut(exePath, appID string) error {
 func ensureURIProtocol(exePath string) error {
 	return nil
 }
+
+func desktopActionsReachUs() bool {
+	return true
+}
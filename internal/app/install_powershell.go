@@ -0,0 +1,180 @@
+//go:build windows || linux
+
+package app
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode/utf16"
+)
+
+// toastShortcutScript creates a Start Menu shortcut to target with the
+// AppUserModelID toasts are shown under. This replicates the
+// Ensure-ToastShortcut function from scripts/install.ps1. A target without a
+// directory is resolved on the Windows PATH.
+func toastShortcutScript(target, arguments, appID string) string {
+	return fmt.Sprintf(
+		`$ErrorActionPreference = 'Stop'
+$target = '%s'
+$arguments = '%s'
+$appID = '%s'
+if (-not [System.IO.Path]::IsPathRooted($target)) {
+  $target = (Get-Command $target).Source
+}
+$linkPath = Join-Path ([Environment]::GetFolderPath('Programs')) 'cc-notify.lnk'
+$linkDir = Split-Path -Parent $linkPath
+New-Item -ItemType Directory -Force $linkDir | Out-Null
+
+$shell = New-Object -ComObject WScript.Shell
+$shortcut = $shell.CreateShortcut($linkPath)
+$shortcut.TargetPath = $target
+$shortcut.Arguments = $arguments
+$shortcut.WorkingDirectory = Split-Path -Parent $target
+$shortcut.IconLocation = "$target,0"
+$shortcut.Description = "cc-notify"
+$shortcut.Save()
+
+if (-not ("ShortcutAppId" -as [type])) {
+  Add-Type -TypeDefinition @"
+using System;
+using System.Runtime.InteropServices;
+
+public static class ShortcutAppId {
+  [StructLayout(LayoutKind.Sequential, Pack = 4)]
+  public struct PROPERTYKEY {
+    public Guid fmtid;
+    public uint pid;
+  }
+
+  [StructLayout(LayoutKind.Explicit)]
+  public struct PROPVARIANT {
+    [FieldOffset(0)] public ushort vt;
+    [FieldOffset(8)] public IntPtr pointerValue;
+  }
+
+  [ComImport, Guid("886D8EEB-8CF2-4446-8D02-CDBA1DBDCF99"), InterfaceType(ComInterfaceType.InterfaceIsIUnknown)]
+  interface IPropertyStore {
+    uint GetCount(out uint cProps);
+    uint GetAt(uint iProp, out PROPERTYKEY pkey);
+    uint GetValue(ref PROPERTYKEY key, out PROPVARIANT pv);
+    uint SetValue(ref PROPERTYKEY key, ref PROPVARIANT pv);
+    uint Commit();
+  }
+
+  [DllImport("shell32.dll", CharSet = CharSet.Unicode, PreserveSig = false)]
+  static extern void SHGetPropertyStoreFromParsingName(
+    string pszPath,
+    IntPtr zero,
+    uint flags,
+    ref Guid iid,
+    out IPropertyStore propertyStore
+  );
+
+  [DllImport("ole32.dll", PreserveSig = false)]
+  static extern void PropVariantClear(ref PROPVARIANT pvar);
+
+  public static void SetShortcutAppId(string shortcutPath, string appId) {
+    Guid iid = new Guid("886D8EEB-8CF2-4446-8D02-CDBA1DBDCF99");
+    IPropertyStore store;
+    SHGetPropertyStoreFromParsingName(shortcutPath, IntPtr.Zero, 0x2, ref iid, out store);
+    PROPERTYKEY key = new PROPERTYKEY { fmtid = new Guid("9F4C2855-9F79-4B39-A8D0-E1D42DE1D5F3"), pid = 5 };
+    PROPVARIANT pv = new PROPVARIANT();
+    pv.vt = 31;
+    pv.pointerValue = Marshal.StringToCoTaskMemUni(appId);
+    try {
+      store.SetValue(ref key, ref pv);
+      store.Commit();
+    } finally {
+      PropVariantClear(ref pv);
+      Marshal.ReleaseComObject(store);
+    }
+  }
+}
+"@
+}
+
+[ShortcutAppId]::SetShortcutAppId($linkPath, $appID)
+`,
+		escapePSString(target),
+		escapePSString(arguments),
+		escapePSString(appID),
+	)
+}
+
+// uriProtocolScript registers command as the cc-notify:// protocol handler in
+// the current user's registry so that toast notification action buttons can
+// call back into cc-notify. Replicates Ensure-UriProtocol from install.ps1.
+// An empty iconPath leaves the default icon unset.
+func uriProtocolScript(iconPath, command string) string {
+	return fmt.Sprintf(
+		`$ErrorActionPreference = 'Stop'
+$iconPath = '%s'
+$command = '%s'
+$protocolKey = 'HKCU:\Software\Classes\cc-notify'
+
+New-Item -Path $protocolKey -Force | Out-Null
+Set-Item -Path $protocolKey -Value 'URL:cc-notify Protocol'
+New-ItemProperty -Path $protocolKey -Name 'URL Protocol' -Value '' -PropertyType String -Force | Out-Null
+
+if ($iconPath -ne '') {
+  $iconKey = Join-Path $protocolKey 'DefaultIcon'
+  New-Item -Path $iconKey -Force | Out-Null
+  Set-Item -Path $iconKey -Value ('"{0}",0' -f $iconPath)
+}
+
+$commandKey = Join-Path $protocolKey 'shell\open\command'
+New-Item -Path $commandKey -Force | Out-Null
+Set-Item -Path $commandKey -Value $command
+`,
+		escapePSString(iconPath),
+		escapePSString(command),
+	)
+}
+
+// uriProtocolCommandScript prints the command the cc-notify:// protocol
+// handler runs, or nothing when no handler is registered.
+const uriProtocolCommandScript = `$key = Get-Item -LiteralPath 'HKCU:\Software\Classes\cc-notify\shell\open\command' -ErrorAction SilentlyContinue
+if ($key) { [Console]::Out.Write($key.GetValue('')) }
+`
+
+// runPowerShellScript runs script with the Windows PowerShell at shell.
+func runPowerShellScript(shell, script string) error {
+	_, err := powerShellOutput(shell, script)
+	return err
+}
+
+// powerShellOutput runs script with the Windows PowerShell at shell and
+// returns what it printed.
+func powerShellOutput(shell, script string) (string, error) {
+	cmd := exec.Command(shell,
+		"-NoProfile",
+		"-NonInteractive",
+		"-ExecutionPolicy", "Bypass",
+		"-EncodedCommand", encodePowerShellCommand(script),
+	)
+	output, err := cmd.CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if err != nil {
+		if text == "" {
+			return "", err
+		}
+		return "", fmt.Errorf("%w: %s", err, text)
+	}
+	return text, nil
+}
+
+func escapePSString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+func encodePowerShellCommand(command string) string {
+	utf16Text := utf16.Encode([]rune(command))
+	utf16LEBytes := make([]byte, len(utf16Text)*2)
+	for i, code := range utf16Text {
+		utf16LEBytes[i*2] = byte(code)
+		utf16LEBytes[i*2+1] = byte(code >> 8)
+	}
+	return base64.StdEncoding.EncodeToString(utf16LEBytes)
+}
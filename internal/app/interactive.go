@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"cc-notify/internal/event"
+	"cc-notify/internal/i18n"
 	"cc-notify/internal/notifier"
+	"cc-notify/internal/source"
 )
 
 const (
@@ -48,11 +52,8 @@ const (
 // version is the tool version shown in the header.
 const version = "v0.4.4"
 
-const (
-	tabDefault = 0
-	tabCodex   = 1
-	tabClaude  = 2
-)
+// tabDefault is the first tab; every source adapter has a tab after it.
+const tabDefault = 0
 
 type keyCode int
 
@@ -74,17 +75,18 @@ func (a *App) runInteractive() error {
 	}
 
 	if !exists || !prefs.SetupDone {
-		a.renderSetupBanner()
-		fmt.Fprintln(a.stdout, "  First launch detected. Auto-configuring hooks...")
+		l := prefs.lang()
+		a.renderSetupBanner(l)
+		fmt.Fprintln(a.stdout, "  "+l.T("First launch detected. Auto-configuring hooks..."))
 		fmt.Fprintln(a.stdout)
 
 		if err := a.runInstall(nil); err != nil {
-			fmt.Fprintf(a.stderr, "  %s%s note:%s auto install failed: %v\n", colorBold, colorYellow, colorReset, err)
+			fmt.Fprintf(a.stderr, "  %s%s %s%s %s: %v\n", colorBold, colorYellow, l.T("note:"), colorReset, l.T("auto install failed"), err)
 		}
 
 		prefs.SetupDone = true
 		if saveErr := a.savePreferences(prefs); saveErr != nil {
-			fmt.Fprintf(a.stderr, "  %s%s note:%s save setup state failed: %v\n", colorBold, colorYellow, colorReset, saveErr)
+			fmt.Fprintf(a.stderr, "  %s%s %s%s %s: %v\n", colorBold, colorYellow, l.T("note:"), colorReset, l.T("save setup state failed"), saveErr)
 		}
 	}
 
@@ -96,12 +98,12 @@ func (a *App) runInteractive() error {
 	return a.runInteractiveLineUI(&prefs)
 }
 
-func (a *App) renderSetupBanner() {
+func (a *App) renderSetupBanner(l i18n.Language) {
 	fmt.Fprintln(a.stdout)
 	fmt.Fprintln(a.stdout)
-	fmt.Fprintf(a.stdout, "  %s%s ⚡ CODEX NOTIFIED %s  %s%sInitial Setup%s\n",
+	fmt.Fprintf(a.stdout, "  %s%s ⚡ CODEX NOTIFIED %s  %s%s%s%s\n",
 		colorBold, colorCyan, colorReset,
-		colorBgDim, colorWhite, colorReset)
+		colorBgDim, colorWhite, l.T("Initial Setup"), colorReset)
 	fmt.Fprintf(a.stdout, "  %s%s%s\n", colorDim, strings.Repeat(symHLine, 50), colorReset)
 	fmt.Fprintln(a.stdout)
 }
@@ -113,7 +115,10 @@ func (a *App) runInteractiveKeyUI(prefs *Preferences) error {
 	}
 	defer restore()
 
-	tabNames := []string{"Default", "Codex", "Claude Code"}
+	tabNames := []string{"Default"}
+	for _, src := range source.All() {
+		tabNames = append(tabNames, src.DisplayName())
+	}
 
 	tab := tabDefault
 	cursor := 0
@@ -121,17 +126,19 @@ func (a *App) runInteractiveKeyUI(prefs *Preferences) error {
 	reader := bufio.NewReader(a.stdin)
 
 	for {
+		l := prefs.lang()
 		items := a.tabMenuItems(tab, *prefs)
 		if cursor >= len(items) {
 			cursor = 0
 		}
 
 		clearScreen(a.stdout)
-		a.renderHeader()
+		a.renderHeader(l)
 
 		// ── Tab bar ──
 		fmt.Fprintf(a.stdout, "  ")
 		for i, name := range tabNames {
+			name = l.T(name)
 			if i == tab {
 				fmt.Fprintf(a.stdout, " %s%s %s %s", colorBold+colorCyan, symCornerTL+symHLine, name, symHLine+symCornerTR+colorReset)
 			} else {
@@ -162,11 +169,11 @@ func (a *App) runInteractiveKeyUI(prefs *Preferences) error {
 		// ── Bottom bar ──
 		fmt.Fprintln(a.stdout)
 		fmt.Fprintf(a.stdout, "  %s%s%s\n", colorDim, strings.Repeat(symHLine, 52), colorReset)
-		fmt.Fprintf(a.stdout, "  %s←/→%s tab  %s↑/↓%s navigate  %s⏎%s select  %sesc%s quit\n",
-			colorBold, colorReset,
-			colorBold, colorReset,
-			colorBold, colorReset,
-			colorBold, colorReset)
+		fmt.Fprintf(a.stdout, "  %s←/→%s %s  %s↑/↓%s %s  %s⏎%s %s  %sesc%s %s\n",
+			colorBold, colorReset, l.T("tab"),
+			colorBold, colorReset, l.T("navigate"),
+			colorBold, colorReset, l.T("select"),
+			colorBold, colorReset, l.T("quit"))
 
 		key, err := readKey(reader)
 		if err != nil {
@@ -205,10 +212,10 @@ func (a *App) runInteractiveKeyUI(prefs *Preferences) error {
 			status = result.status
 		case keyEsc:
 			if err := a.savePreferences(*prefs); err != nil {
-				fmt.Fprintf(a.stderr, "  %snote:%s save on exit failed: %v\n", colorYellow, colorReset, err)
+				fmt.Fprintf(a.stderr, "  %s%s%s %s: %v\n", colorYellow, l.T("note:"), colorReset, l.T("save on exit failed"), err)
 			}
 			clearScreen(a.stdout)
-			fmt.Fprintf(a.stdout, "  %s%sGoodbye!%s\n\n", colorDim, colorCyan, colorReset)
+			fmt.Fprintf(a.stdout, "  %s%s%s%s\n\n", colorDim, colorCyan, l.T("Goodbye!"), colorReset)
 			return nil
 		}
 	}
@@ -228,108 +235,119 @@ type menuItem struct {
 }
 
 func (a *App) renderTabInfo(tab int, p Preferences) {
+	l := p.lang()
 	switch tab {
 	case tabDefault:
-		statusPill := fmt.Sprintf("%s%s%s ON%s", colorBold, colorGreen, symBullet, colorReset)
-		if !p.Enabled {
-			statusPill = fmt.Sprintf("%s%s OFF%s", colorDim, symCircle, colorReset)
-		}
-		fmt.Fprintf(a.stdout, "  %s%s%sGlobal defaults applied to all tools%s\n",
-			colorDim, symBar, " ", colorReset)
-		fmt.Fprintf(a.stdout, "  %s%s%s %s  mode:%s%s%s  content:%s%s%s\n",
-			colorDim, symBar, colorReset,
-			statusPill,
-			colorBold, p.Mode, colorReset,
-			colorBold, p.Content, colorReset)
-	case tabCodex:
-		en, mode, content := p.ToolPrefs("codex")
-		statusPill := fmt.Sprintf("%s%s%s ON%s", colorBold, colorGreen, symBullet, colorReset)
-		if !en {
-			statusPill = fmt.Sprintf("%s%s OFF%s", colorDim, symCircle, colorReset)
-		}
-		inheritHint := ""
-		if p.CodexEnabled == nil && p.CodexMode == "" && p.CodexContent == "" {
-			inheritHint = fmt.Sprintf("  %s(all inherited from Default)%s", colorDim, colorReset)
-		}
-		fmt.Fprintf(a.stdout, "  %s%s%s Codex CLI notifications%s%s\n",
-			colorDim, symBar, " ", colorReset, inheritHint)
-		fmt.Fprintf(a.stdout, "  %s%s%s %s  mode:%s%s%s  content:%s%s%s\n",
+		fmt.Fprintf(a.stdout, "  %s%s%s%s%s\n",
+			colorDim, symBar, " ", l.T("Global defaults applied to all tools"), colorReset)
+		fmt.Fprintf(a.stdout, "  %s%s%s %s  %s%s%s%s  %s%s%s%s\n",
 			colorDim, symBar, colorReset,
-			statusPill,
-			colorBold, mode, colorReset,
-			colorBold, content, colorReset)
-	case tabClaude:
-		en, mode, content := p.ToolPrefs("claude")
-		statusPill := fmt.Sprintf("%s%s%s ON%s", colorBold, colorGreen, symBullet, colorReset)
-		if !en {
-			statusPill = fmt.Sprintf("%s%s OFF%s", colorDim, symCircle, colorReset)
-		}
+			toggleIndicator(p.Enabled),
+			l.T("mode:"), colorBold, p.Mode, colorReset,
+			l.T("content:"), colorBold, p.Content, colorReset)
+	default:
+		src := source.All()[tab-1]
+		en, mode, content := p.ToolPrefs(src.Name())
 		inheritHint := ""
-		if p.ClaudeEnabled == nil && p.ClaudeMode == "" && p.ClaudeContent == "" {
-			inheritHint = fmt.Sprintf("  %s(all inherited from Default)%s", colorDim, colorReset)
+		if p.Tools[src.Name()].isZero() {
+			inheritHint = fmt.Sprintf("  %s%s%s", colorDim, l.T("(all inherited from Default)"), colorReset)
 		}
-		fmt.Fprintf(a.stdout, "  %s%s%s Claude Code notifications%s%s\n",
-			colorDim, symBar, " ", colorReset, inheritHint)
-		fmt.Fprintf(a.stdout, "  %s%s%s %s  mode:%s%s%s  content:%s%s%s\n",
+		fmt.Fprintf(a.stdout, "  %s%s%s %s%s%s\n",
+			colorDim, symBar, " ", l.Sprintf("%s notifications", src.DisplayName()), colorReset, inheritHint)
+		fmt.Fprintf(a.stdout, "  %s%s%s %s  %s%s%s%s  %s%s%s%s\n",
 			colorDim, symBar, colorReset,
-			statusPill,
-			colorBold, mode, colorReset,
-			colorBold, content, colorReset)
+			toggleIndicator(en),
+			l.T("mode:"), colorBold, mode, colorReset,
+			l.T("content:"), colorBold, content, colorReset)
 	}
 	fmt.Fprintln(a.stdout)
 }
 
 func (a *App) tabMenuItems(tab int, p Preferences) []menuItem {
-	switch tab {
-	case tabDefault:
+	if tab == tabDefault {
 		return a.defaultTabItems(p)
-	case tabCodex:
-		return a.codexTabItems(p)
-	case tabClaude:
-		return a.claudeTabItems(p)
 	}
-	return nil
+	return a.toolTabItems(source.All()[tab-1], p)
+}
+
+// padDisplay pads text with spaces to width terminal columns, counting
+// East Asian wide characters as two, and keeps at least one space.
+func padDisplay(text string, width int) string {
+	n := 0
+	for _, r := range text {
+		n++
+		if isWide(r) {
+			n++
+		}
+	}
+	if n < width {
+		return text + strings.Repeat(" ", width-n)
+	}
+	return text + " "
+}
+
+// isWide reports whether r takes two terminal columns.
+func isWide(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115F || // Hangul Jamo
+		(r >= 0x2E80 && r <= 0xA4CF && r != 0x303F) || // CJK, Kana, Yi
+		(r >= 0xAC00 && r <= 0xD7A3) || // Hangul syllables
+		(r >= 0xF900 && r <= 0xFAFF) || // CJK compatibility ideographs
+		(r >= 0xFE30 && r <= 0xFE4F) || // CJK compatibility forms
+		(r >= 0xFF00 && r <= 0xFF60) || // fullwidth forms
+		(r >= 0xFFE0 && r <= 0xFFE6) ||
+		(r >= 0x20000 && r <= 0x3FFFD))
+}
+
+// menuLabelWidth is the column menu values are aligned to.
+const menuLabelWidth = 25
+
+// menuLabel lays out a menu entry with its value aligned in a column.
+func menuLabel(sym, text, value string) string {
+	return sym + " " + padDisplay(text, menuLabelWidth) + value
 }
 
 func (a *App) defaultTabItems(p Preferences) []menuItem {
-	modeOpts := []string{"auto  " + colorDim + symDot + " toast first, popup fallback" + colorReset,
-		"toast " + colorDim + symDot + " Windows system notification" + colorReset,
-		"popup " + colorDim + symDot + " popup dialog" + colorReset}
+	l := p.lang()
+	modeOpts := []string{"auto  " + colorDim + symDot + " " + l.T("toast first, popup fallback") + colorReset,
+		"toast " + colorDim + symDot + " " + l.T("Windows system notification") + colorReset,
+		"popup " + colorDim + symDot + " " + l.T("popup dialog") + colorReset,
+		"terminal " + colorDim + symDot + " " + l.T("escape sequence to the agent terminal (works over SSH)") + colorReset}
 	promptOpts := []string{
-		"popup    " + colorDim + symDot + " desktop popup dialog" + colorReset,
-		"toast    " + colorDim + symDot + " Windows notification buttons" + colorReset,
-		"terminal " + colorDim + symDot + " inline prompt inside Codex/Claude terminal" + colorReset,
+		"popup    " + colorDim + symDot + " " + l.T("desktop popup dialog") + colorReset,
+		"toast    " + colorDim + symDot + " " + l.T("Windows notification buttons") + colorReset,
+		"terminal " + colorDim + symDot + " " + l.T("inline prompt inside Codex/Claude terminal") + colorReset,
 	}
-	contentOpts := []string{
-		"summary  " + colorDim + symDot + " short summary" + colorReset,
-		"full     " + colorDim + symDot + " full assistant message" + colorReset,
-		"complete " + colorDim + symDot + " minimal text" + colorReset,
+	contentOpts := contentLabels(l)
+	languageValues := []string{"", string(i18n.English), string(i18n.SimplifiedChinese)}
+	languageOpts := []string{"auto     " + colorDim + symDot + " " + l.T("follow the system locale") + colorReset}
+	for _, lang := range i18n.Languages() {
+		languageOpts = append(languageOpts, fmt.Sprintf("%-8s %s%s %s%s", lang, colorDim, symDot, lang.Name(), colorReset))
 	}
 	return []menuItem{
 		{
-			label: fmt.Sprintf("%s Toggle notifications     %s", symSpark, toggleIndicator(p.Enabled)),
+			label: menuLabel(symSpark, l.T("Toggle notifications"), toggleIndicator(p.Enabled)),
 			action: func(prefs *Preferences) actionResult {
 				prefs.Enabled = !prefs.Enabled
 				return actionResult{status: a.saveOrSessionText(*prefs)}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Notification mode        %s%s%s", symBell, colorDim, p.Mode, colorReset),
+			label: menuLabel(symBell, l.T("Notification mode"), colorDim+p.Mode+colorReset),
 			action: func(prefs *Preferences) actionResult {
-				start := indexOf([]string{"auto", "toast", "popup"}, prefs.Mode)
-				sel, err := a.selectSingleTTY("Default Mode", "Notification delivery method for all tools.", modeOpts, start)
+				start := indexOf([]string{"auto", "toast", "popup", "terminal"}, prefs.Mode)
+				sel, err := a.selectSingleTTY(l, l.T("Default Mode"), l.T("Notification delivery method for all tools."), modeOpts, start)
 				if err != nil {
 					return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 				}
-				prefs.Mode = []string{"auto", "toast", "popup"}[sel]
+				prefs.Mode = []string{"auto", "toast", "popup", "terminal"}[sel]
 				return actionResult{status: a.saveOrSessionText(*prefs)}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Approval prompt mode    %s%s%s", symBell, colorDim, p.PausePrompt, colorReset),
+			label: menuLabel(symBell, l.T("Approval prompt mode"), colorDim+p.PausePrompt+colorReset),
 			action: func(prefs *Preferences) actionResult {
 				start := indexOf([]string{"popup", "toast", "terminal"}, prefs.PausePrompt)
-				sel, err := a.selectSingleTTY("Approval Prompt", "How pause approvals are displayed.", promptOpts, start)
+				sel, err := a.selectSingleTTY(l, l.T("Approval Prompt"), l.T("How pause approvals are displayed."), promptOpts, start)
 				if err != nil {
 					return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 				}
@@ -338,10 +356,10 @@ func (a *App) defaultTabItems(p Preferences) []menuItem {
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Content mode             %s%s%s", symGear, colorDim, p.Content, colorReset),
+			label: menuLabel(symGear, l.T("Content mode"), colorDim+p.Content+colorReset),
 			action: func(prefs *Preferences) actionResult {
 				start := indexOf([]string{"summary", "full", "complete"}, prefs.Content)
-				sel, err := a.selectSingleTTY("Default Content", "What to show in the notification body.", contentOpts, start)
+				sel, err := a.selectSingleTTY(l, l.T("Default Content"), l.T("What to show in the notification body."), contentOpts, start)
 				if err != nil {
 					return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 				}
@@ -350,25 +368,42 @@ func (a *App) defaultTabItems(p Preferences) []menuItem {
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Configure extra fields", symDot),
+			label: fmt.Sprintf("%s %s", symDot, l.T("Configure extra fields")),
 			action: func(prefs *Preferences) actionResult {
-				opts := []string{"Include project directory", "Include model name", "Include event type"}
-				cur := map[int]bool{0: prefs.IncludeDir, 1: prefs.IncludeModel, 2: prefs.IncludeEvent}
-				sel, err := a.selectMultiTTY("Extra Fields", "Toggle additional info in notifications.", opts, cur)
+				opts := []string{l.T("Include project directory"), l.T("Include model name"), l.T("Include event type"),
+					l.T("Include turn duration"), l.T("Include token count"), l.T("Include estimated cost"), l.T("Include tool calls"),
+					l.T("Include git branch"), l.T("Include git commit"), l.T("Include git changes"), l.T("Include git worktree"),
+					l.T("Include files edited this turn")}
+				cur := map[int]bool{
+					0: prefs.IncludeDir, 1: prefs.IncludeModel, 2: prefs.IncludeEvent,
+					3: prefs.IncludeDuration, 4: prefs.IncludeTokens, 5: prefs.IncludeCost, 6: prefs.IncludeTools,
+					7: prefs.IncludeGitBranch, 8: prefs.IncludeGitHead, 9: prefs.IncludeGitStatus, 10: prefs.IncludeGitWorktree,
+					11: prefs.IncludeChanges,
+				}
+				sel, err := a.selectMultiTTY(l, l.T("Extra Fields"), l.T("Toggle additional info in notifications."), opts, cur)
 				if err != nil {
 					return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 				}
 				prefs.IncludeDir = sel[0]
 				prefs.IncludeModel = sel[1]
 				prefs.IncludeEvent = sel[2]
+				prefs.IncludeDuration = sel[3]
+				prefs.IncludeTokens = sel[4]
+				prefs.IncludeCost = sel[5]
+				prefs.IncludeTools = sel[6]
+				prefs.IncludeGitBranch = sel[7]
+				prefs.IncludeGitHead = sel[8]
+				prefs.IncludeGitStatus = sel[9]
+				prefs.IncludeGitWorktree = sel[10]
+				prefs.IncludeChanges = sel[11]
 				prefs.FieldsConfigured = true
 				return actionResult{status: a.saveOrSessionText(*prefs)}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Toast AppId              %s%s%s", symPlug, colorDim, p.ToastAppID, colorReset),
+			label: menuLabel(symPlug, "Toast AppId", colorDim+p.ToastAppID+colorReset),
 			action: func(prefs *Preferences) actionResult {
-				appID, err := a.promptLine("  Toast AppId (blank = default): ")
+				appID, err := a.promptLine("  " + l.T("Toast AppId (blank = default): "))
 				if err != nil {
 					return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 				}
@@ -379,240 +414,309 @@ func (a *App) defaultTabItems(p Preferences) []menuItem {
 				return actionResult{status: a.saveOrSessionText(*prefs)}
 			},
 		},
+		{
+			label: menuLabel(symGear, l.T("Language"), colorDim+languageLabel(p.Language)+colorReset),
+			action: func(prefs *Preferences) actionResult {
+				sel, err := a.selectSingleTTY(l, l.T("Language"), l.T("Language of notifications and this UI."), languageOpts, indexOf(languageValues, prefs.Language))
+				if err != nil {
+					return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
+				}
+				prefs.Language = languageValues[sel]
+				return actionResult{status: a.saveOrSessionText(*prefs)}
+			},
+		},
 		{label: fmt.Sprintf("%s%s%s", colorDim, strings.Repeat(symHLine, 40), colorReset)},
 		{
-			label: fmt.Sprintf("%s Send preview notification", symBell),
+			label: fmt.Sprintf("%s %s", symBell, l.T("Send preview notification")),
 			action: func(prefs *Preferences) actionResult {
 				if err := a.previewNotification(*prefs); err != nil {
-					return actionResult{status: fmt.Sprintf("%s%s✗ Preview failed:%s %v", colorBold, colorRed, colorReset, err)}
+					return actionResult{status: failedText(l, "Preview failed:", err)}
 				}
-				return actionResult{status: fmt.Sprintf("%s%s✓ Preview sent.%s", colorBold, colorGreen, colorReset)}
+				return actionResult{status: doneText(l, "Preview sent.")}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Save settings now", symDisk),
+			label: menuLabel(symDot, l.T("Preview templates"), colorDim+l.Sprintf("%d configured", len(p.Templates))+colorReset),
+			action: func(prefs *Preferences) actionResult {
+				return a.previewTemplates(*prefs)
+			},
+		},
+		{
+			label: fmt.Sprintf("%s %s", symDisk, l.T("Save settings now")),
 			action: func(prefs *Preferences) actionResult {
 				if err := a.savePreferences(*prefs); err != nil {
-					return actionResult{status: fmt.Sprintf("%s%s✗ Save failed:%s %v", colorBold, colorRed, colorReset, err)}
+					return actionResult{status: failedText(l, "Save failed:", err)}
 				}
-				return actionResult{status: fmt.Sprintf("%s%s✓ Saved.%s", colorBold, colorGreen, colorReset)}
+				return actionResult{status: doneText(l, "Saved.")}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Exit", symWave),
+			label: fmt.Sprintf("%s %s", symWave, l.T("Exit")),
 			action: func(prefs *Preferences) actionResult {
 				_ = a.savePreferences(*prefs)
 				clearScreen(a.stdout)
-				fmt.Fprintf(a.stdout, "  %s%sGoodbye!%s\n\n", colorDim, colorCyan, colorReset)
+				fmt.Fprintf(a.stdout, "  %s%s%s%s\n\n", colorDim, colorCyan, l.T("Goodbye!"), colorReset)
 				return actionExit
 			},
 		},
 	}
 }
 
-func (a *App) toolModeAction(toolName string, modePtr *string) func(prefs *Preferences) actionResult {
+// languageLabel describes a language preference, e.g. "auto (English)".
+func languageLabel(pref string) string {
+	lang := i18n.Resolve(pref)
+	if pref == "" {
+		return "auto (" + lang.Name() + ")"
+	}
+	return lang.Name()
+}
+
+func contentLabels(l i18n.Language) []string {
+	return []string{
+		"summary  " + colorDim + symDot + " " + l.T("short summary") + colorReset,
+		"full     " + colorDim + symDot + " " + l.T("full assistant message") + colorReset,
+		"complete " + colorDim + symDot + " " + l.T("minimal text") + colorReset,
+	}
+}
+
+// doneText and failedText format the status line after a menu action.
+func doneText(l i18n.Language, msg string) string {
+	return fmt.Sprintf("%s%s✓ %s%s", colorBold, colorGreen, l.T(msg), colorReset)
+}
+
+func failedText(l i18n.Language, msg string, err error) string {
+	return fmt.Sprintf("%s%s✗ %s%s %v", colorBold, colorRed, l.T(msg), colorReset, err)
+}
+
+func (a *App) toolModeAction(l i18n.Language, src source.Adapter) func(prefs *Preferences) actionResult {
 	modeLabels := []string{
-		colorDim + "global " + symDot + " use Default setting" + colorReset,
-		"auto  " + colorDim + symDot + " toast first, popup fallback" + colorReset,
-		"toast " + colorDim + symDot + " Windows system notification" + colorReset,
-		"popup " + colorDim + symDot + " popup dialog" + colorReset,
+		colorDim + "global " + symDot + " " + l.T("use Default setting") + colorReset,
+		"auto  " + colorDim + symDot + " " + l.T("toast first, popup fallback") + colorReset,
+		"toast " + colorDim + symDot + " " + l.T("Windows system notification") + colorReset,
+		"popup " + colorDim + symDot + " " + l.T("popup dialog") + colorReset,
+		"terminal " + colorDim + symDot + " " + l.T("escape sequence to the agent terminal") + colorReset,
 	}
-	modeValues := []string{"auto", "toast", "popup"}
+	modeValues := []string{"auto", "toast", "popup", "terminal"}
 
 	return func(prefs *Preferences) actionResult {
+		tool := prefs.Tools[src.Name()]
 		cur := 0
-		if *modePtr != "" {
-			cur = indexOf(modeValues, *modePtr) + 1
+		if tool.Mode != "" {
+			cur = indexOf(modeValues, tool.Mode) + 1
 		}
-		sel, err := a.selectSingleTTY(toolName+" Mode", "Choose mode or 'global' to inherit Default.", modeLabels, cur)
+		sel, err := a.selectSingleTTY(l, l.Sprintf("%s Mode", src.DisplayName()), l.T("Choose mode or 'global' to inherit Default."), modeLabels, cur)
 		if err != nil {
 			return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 		}
 		if sel == 0 {
-			*modePtr = ""
+			tool.Mode = ""
 		} else {
-			*modePtr = modeValues[sel-1]
+			tool.Mode = modeValues[sel-1]
 		}
+		prefs.setTool(src.Name(), tool)
 		return actionResult{status: a.saveOrSessionText(*prefs)}
 	}
 }
 
-func (a *App) toolContentAction(toolName string, contentPtr *string) func(prefs *Preferences) actionResult {
-	contentLabels := []string{
-		colorDim + "global " + symDot + " use Default setting" + colorReset,
-		"summary  " + colorDim + symDot + " short summary" + colorReset,
-		"full     " + colorDim + symDot + " full assistant message" + colorReset,
-		"complete " + colorDim + symDot + " minimal text" + colorReset,
-	}
+func (a *App) toolContentAction(l i18n.Language, src source.Adapter) func(prefs *Preferences) actionResult {
+	labels := append([]string{colorDim + "global " + symDot + " " + l.T("use Default setting") + colorReset}, contentLabels(l)...)
 	contentValues := []string{"summary", "full", "complete"}
 
 	return func(prefs *Preferences) actionResult {
+		tool := prefs.Tools[src.Name()]
 		cur := 0
-		if *contentPtr != "" {
-			cur = indexOf(contentValues, *contentPtr) + 1
+		if tool.Content != "" {
+			cur = indexOf(contentValues, tool.Content) + 1
 		}
-		sel, err := a.selectSingleTTY(toolName+" Content", "Choose content mode or 'global' to inherit Default.", contentLabels, cur)
+		sel, err := a.selectSingleTTY(l, l.Sprintf("%s Content", src.DisplayName()), l.T("Choose content mode or 'global' to inherit Default."), labels, cur)
 		if err != nil {
 			return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
 		}
 		if sel == 0 {
-			*contentPtr = ""
+			tool.Content = ""
 		} else {
-			*contentPtr = contentValues[sel-1]
+			tool.Content = contentValues[sel-1]
 		}
+		prefs.setTool(src.Name(), tool)
 		return actionResult{status: a.saveOrSessionText(*prefs)}
 	}
 }
 
-func toolEnabledLabel(ptr *bool, globalEnabled bool) string {
+func toolEnabledLabel(l i18n.Language, ptr *bool, globalEnabled bool) string {
 	if ptr == nil {
 		if globalEnabled {
-			return fmt.Sprintf("%sinherit%s %s%s%s ON%s", colorDim, colorReset, colorDim, symDot, colorGreen, colorReset)
+			return fmt.Sprintf("%s%s%s %s%s%s ON%s", colorDim, l.T("inherit"), colorReset, colorDim, symDot, colorGreen, colorReset)
 		}
-		return fmt.Sprintf("%sinherit%s %s%s%s OFF%s", colorDim, colorReset, colorDim, symDot, colorRed, colorReset)
+		return fmt.Sprintf("%s%s%s %s%s%s OFF%s", colorDim, l.T("inherit"), colorReset, colorDim, symDot, colorRed, colorReset)
 	}
-	if *ptr {
-		return fmt.Sprintf("%s%s%s ON%s", colorBold, colorGreen, symBullet, colorReset)
-	}
-	return fmt.Sprintf("%s%s OFF%s", colorDim, symCircle, colorReset)
+	return toggleIndicator(*ptr)
 }
 
-func toolOverrideHint(val string) string {
+func toolOverrideHint(l i18n.Language, val string) string {
 	if val == "" {
-		return fmt.Sprintf("%sinherit%s", colorDim, colorReset)
+		return fmt.Sprintf("%s%s%s", colorDim, l.T("inherit"), colorReset)
 	}
 	return fmt.Sprintf("%s%s%s", colorCyan, val, colorReset)
 }
 
-func (a *App) codexTabItems(p Preferences) []menuItem {
+func (a *App) toolTabItems(src source.Adapter, p Preferences) []menuItem {
+	l := p.lang()
+	name, display := src.Name(), src.DisplayName()
+	tool := p.Tools[name]
+	configHint := ""
+	if path, err := a.sourceConfigPath(src); err == nil {
+		configHint = colorDim + homeRelative(path) + colorReset
+	}
 	return []menuItem{
 		{
-			label: fmt.Sprintf("%s Toggle Codex             %s", symSpark, toolEnabledLabel(p.CodexEnabled, p.Enabled)),
+			label: menuLabel(symSpark, l.Sprintf("Toggle %s", display), toolEnabledLabel(l, tool.Enabled, p.Enabled)),
 			action: func(prefs *Preferences) actionResult {
-				if prefs.CodexEnabled == nil {
-					prefs.CodexEnabled = boolPtr(false)
-				} else if !*prefs.CodexEnabled {
-					prefs.CodexEnabled = boolPtr(true)
+				tool := prefs.Tools[name]
+				if tool.Enabled == nil {
+					tool.Enabled = boolPtr(false)
+				} else if !*tool.Enabled {
+					tool.Enabled = boolPtr(true)
 				} else {
-					prefs.CodexEnabled = nil
+					tool.Enabled = nil
 				}
+				prefs.setTool(name, tool)
 				return actionResult{status: a.saveOrSessionText(*prefs)}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Codex mode               %s", symBell, toolOverrideHint(p.CodexMode)),
-			action: func(prefs *Preferences) actionResult {
-				return a.toolModeAction("Codex", &prefs.CodexMode)(prefs)
-			},
+			label:  menuLabel(symBell, l.Sprintf("%s mode", display), toolOverrideHint(l, tool.Mode)),
+			action: a.toolModeAction(l, src),
 		},
 		{
-			label: fmt.Sprintf("%s Codex content            %s", symGear, toolOverrideHint(p.CodexContent)),
-			action: func(prefs *Preferences) actionResult {
-				return a.toolContentAction("Codex", &prefs.CodexContent)(prefs)
-			},
+			label:  menuLabel(symGear, l.Sprintf("%s content", display), toolOverrideHint(l, tool.Content)),
+			action: a.toolContentAction(l, src),
 		},
 		{label: fmt.Sprintf("%s%s%s", colorDim, strings.Repeat(symHLine, 40), colorReset)},
 		{
-			label: fmt.Sprintf("%s Install Codex hook       %s~/.codex/config.toml%s", symPlug, colorDim, colorReset),
+			label: menuLabel(symPlug, l.Sprintf("Install %s hook", display), configHint),
 			action: func(prefs *Preferences) actionResult {
-				if err := a.runInstall([]string{"codex"}); err != nil {
-					return actionResult{status: fmt.Sprintf("%s%s✗ Install failed:%s %v", colorBold, colorRed, colorReset, err)}
+				if err := a.runInstall([]string{name}); err != nil {
+					return actionResult{status: failedText(l, "Install failed:", err)}
 				}
-				return actionResult{status: fmt.Sprintf("%s%s✓ Codex hook installed.%s", colorBold, colorGreen, colorReset)}
+				return actionResult{status: doneText(l, l.Sprintf("%s hook installed.", display))}
 			},
 		},
 		{
-			label: fmt.Sprintf("%s Send Codex preview", symBell),
+			label: fmt.Sprintf("%s %s", symBell, l.Sprintf("Send %s preview", display)),
 			action: func(prefs *Preferences) actionResult {
-				_, mode, content := prefs.ToolPrefs("codex")
-				if err := a.previewWithOverrides(*prefs, mode, content); err != nil {
-					return actionResult{status: fmt.Sprintf("%s%s✗ Preview failed:%s %v", colorBold, colorRed, colorReset, err)}
+				_, mode, content := prefs.ToolPrefs(name)
+				if err := a.previewWithOverrides(*prefs, name, mode, content); err != nil {
+					return actionResult{status: failedText(l, "Preview failed:", err)}
 				}
-				return actionResult{status: fmt.Sprintf("%s%s✓ Codex preview sent.%s", colorBold, colorGreen, colorReset)}
+				return actionResult{status: doneText(l, l.Sprintf("%s preview sent.", display))}
 			},
 		},
 	}
 }
 
-func (a *App) claudeTabItems(p Preferences) []menuItem {
-	return []menuItem{
-		{
-			label: fmt.Sprintf("%s Toggle Claude            %s", symSpark, toolEnabledLabel(p.ClaudeEnabled, p.Enabled)),
-			action: func(prefs *Preferences) actionResult {
-				if prefs.ClaudeEnabled == nil {
-					prefs.ClaudeEnabled = boolPtr(false)
-				} else if !*prefs.ClaudeEnabled {
-					prefs.ClaudeEnabled = boolPtr(true)
-				} else {
-					prefs.ClaudeEnabled = nil
-				}
-				return actionResult{status: a.saveOrSessionText(*prefs)}
-			},
-		},
-		{
-			label: fmt.Sprintf("%s Claude mode              %s", symBell, toolOverrideHint(p.ClaudeMode)),
-			action: func(prefs *Preferences) actionResult {
-				return a.toolModeAction("Claude", &prefs.ClaudeMode)(prefs)
-			},
-		},
-		{
-			label: fmt.Sprintf("%s Claude content           %s", symGear, toolOverrideHint(p.ClaudeContent)),
-			action: func(prefs *Preferences) actionResult {
-				return a.toolContentAction("Claude", &prefs.ClaudeContent)(prefs)
-			},
-		},
-		{label: fmt.Sprintf("%s%s%s", colorDim, strings.Repeat(symHLine, 40), colorReset)},
-		{
-			label: fmt.Sprintf("%s Install Claude hook      %s~/.claude/settings.json%s", symPlug, colorDim, colorReset),
-			action: func(prefs *Preferences) actionResult {
-				if err := a.runInstall([]string{"claude"}); err != nil {
-					return actionResult{status: fmt.Sprintf("%s%s✗ Install failed:%s %v", colorBold, colorRed, colorReset, err)}
-				}
-				return actionResult{status: fmt.Sprintf("%s%s✓ Claude hook installed.%s", colorBold, colorGreen, colorReset)}
-			},
-		},
-		{
-			label: fmt.Sprintf("%s Send Claude preview", symBell),
-			action: func(prefs *Preferences) actionResult {
-				_, mode, content := prefs.ToolPrefs("claude")
-				if err := a.previewWithOverrides(*prefs, mode, content); err != nil {
-					return actionResult{status: fmt.Sprintf("%s%s✗ Preview failed:%s %v", colorBold, colorRed, colorReset, err)}
-				}
-				return actionResult{status: fmt.Sprintf("%s%s✓ Claude preview sent.%s", colorBold, colorGreen, colorReset)}
-			},
-		},
+// homeRelative shortens a path in the home directory to ~/....
+func homeRelative(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	rel, err := filepath.Rel(home, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
 	}
+	return "~/" + filepath.ToSlash(rel)
 }
 
-func (a *App) previewWithOverrides(p Preferences, mode, content string) error {
-	title, body, _ := event.RenderNotificationWithOptions(event.Payload{
-		Type:                 "agent-turn-complete",
-		Summary:              "Sample summary: work completed.",
-		LastAssistantMessage: "Sample full answer: all requested changes are finished.",
-		CWD:                  "C:\\sample\\project",
-		Model:                "gpt-5",
-	}, event.RenderOptions{
-		ContentMode:  event.ContentMode(content),
-		IncludeDir:   p.IncludeDir,
-		IncludeModel: p.IncludeModel,
-		IncludeEvent: p.IncludeEvent,
-	})
+// sampleTurnStats fills the statistics line of preview notifications.
+var sampleTurnStats = &event.TurnStats{
+	Duration:  7*time.Minute + 12*time.Second,
+	Tokens:    event.TokenUsage{Input: 12000, CacheRead: 30000, Output: 6000},
+	ToolCalls: 23,
+	Commands:  23,
+	Cost:      0.42,
+}
+
+func (a *App) previewWithOverrides(p Preferences, source, mode, content string) error {
+	title, body, err := renderSample(p, source, "agent-turn-complete", content)
+	if err != nil {
+		return err
+	}
 
 	service := notifier.NewWithConfig(notifier.Config{
-		Mode:       mode,
-		ToastAppID: p.ToastAppID,
+		Mode:          mode,
+		ToastAppID:    p.ToastAppID,
+		TerminalStyle: p.TerminalStyle,
 	})
 	return service.Notify(title, body)
 }
 
-func (a *App) renderHeader() {
+// renderSample renders a sample eventType event from source as notify
+// would, templates included.
+func renderSample(p Preferences, source, eventType, content string) (title, body string, err error) {
+	opts, err := p.withTemplates(p.renderOptions(content), source, eventType)
+	if err != nil {
+		return "", "", err
+	}
+	l := p.lang()
+	title, body, _ = event.RenderNotificationWithOptions(event.Payload{
+		Type:                 eventType,
+		Summary:              l.T("Sample summary: work completed."),
+		LastAssistantMessage: l.T("Sample full answer: ran `go test ./...` and all requested changes are finished."),
+		CWD:                  "C:\\sample\\project",
+		Model:                "gpt-5",
+		SessionID:            "sample-session",
+		LastUserPrompt:       l.T("Sample prompt: fix the flaky test."),
+		Stats:                sampleTurnStats,
+	}, opts)
+	return title, body, nil
+}
+
+// previewTemplates prints every configured template rendered with a sample
+// event it applies to.
+func (a *App) previewTemplates(p Preferences) actionResult {
+	l := p.lang()
+	if len(p.Templates) == 0 {
+		return actionResult{status: fmt.Sprintf("%s%s%s", colorDim, l.T("No templates configured in settings.json."), colorReset)}
+	}
+	if err := validateTemplates(p.Templates); err != nil {
+		return actionResult{status: failedText(l, "Invalid template:", err)}
+	}
+	fmt.Fprintln(a.stdout)
+	for i, t := range p.Templates {
+		source := firstNonEmptyString(strings.ToLower(strings.TrimSpace(t.Source)), "codex")
+		eventType := strings.ToLower(strings.TrimSpace(t.Event))
+		switch eventType {
+		case "":
+			eventType = "agent-turn-complete"
+		case "complete", "paused":
+			eventType = "agent-turn-" + eventType
+		}
+		_, _, content := p.ToolPrefs(source)
+		title, body, err := renderSample(p, source, eventType, content)
+		if err != nil {
+			return actionResult{status: failedText(l, "Invalid template:", err)}
+		}
+		fmt.Fprintf(a.stdout, "  %stemplates[%d]%s %s%s %s %s%s\n", colorBold, i, colorReset, colorDim, source, symDot, eventType, colorReset)
+		fmt.Fprintf(a.stdout, "    %s%s%s\n", colorCyan, title, colorReset)
+		for _, line := range strings.Split(body, "\n") {
+			fmt.Fprintf(a.stdout, "    %s\n", line)
+		}
+		fmt.Fprintln(a.stdout)
+	}
+	if _, err := a.promptLine("  " + l.T("Press Enter to continue ")); err != nil {
+		return actionResult{status: fmt.Sprintf("%s✗ %v%s", colorRed, err, colorReset)}
+	}
+	return actionResult{status: doneText(l, "Templates rendered.")}
+}
+
+func (a *App) renderHeader(l i18n.Language) {
 	fmt.Fprintln(a.stdout)
 	fmt.Fprintf(a.stdout, "  %s%s╭─ %s⚡ cc-notify%s %s%s %s─╮%s\n",
 		colorDim, colorMagenta, colorBold+colorCyan, colorReset,
 		colorDim, version,
 		colorMagenta, colorReset)
-	fmt.Fprintf(a.stdout, "  %s%s╰─ %sNotifications for Codex CLI & Claude Code%s %s─╯%s\n",
-		colorDim, colorMagenta, colorCyan, colorReset,
+	fmt.Fprintf(a.stdout, "  %s%s╰─ %s%s%s %s─╯%s\n",
+		colorDim, colorMagenta, colorCyan, l.T("Notifications for your coding agents"), colorReset,
 		colorMagenta, colorReset)
 	fmt.Fprintln(a.stdout)
 }
@@ -628,6 +732,7 @@ func (a *App) runInteractiveLineUI(prefs *Preferences) error {
 	reader := bufio.NewReader(a.stdin)
 
 	for {
+		l := prefs.lang()
 		a.renderInteractiveMenu(*prefs)
 		fmt.Fprintf(a.stdout, "\n  %s%s❯%s ", colorBold, colorCyan, colorReset)
 
@@ -644,16 +749,16 @@ func (a *App) runInteractiveLineUI(prefs *Preferences) error {
 		case "2":
 			prefs.Mode = nextMode(prefs.Mode)
 			a.printSavedOrSession(*prefs)
-			fmt.Fprintf(a.stdout, "  Mode -> %s%s%s (%s)\n", colorCyan, prefs.Mode, colorReset, modeHint(prefs.Mode))
+			fmt.Fprintf(a.stdout, "  %s -> %s%s%s (%s)\n", l.T("Mode"), colorCyan, prefs.Mode, colorReset, l.T(modeHint(prefs.Mode)))
 		case "3":
 			prefs.Content = nextContentMode(prefs.Content)
 			a.printSavedOrSession(*prefs)
 		case "4":
 			prefs.PausePrompt = nextPausePrompt(prefs.PausePrompt)
 			a.printSavedOrSession(*prefs)
-			fmt.Fprintf(a.stdout, "  Prompt -> %s%s%s (%s)\n", colorCyan, prefs.PausePrompt, colorReset, pausePromptHint(prefs.PausePrompt))
+			fmt.Fprintf(a.stdout, "  %s -> %s%s%s (%s)\n", l.T("Prompt"), colorCyan, prefs.PausePrompt, colorReset, l.T(pausePromptHint(prefs.PausePrompt)))
 		case "5":
-			fmt.Fprintf(a.stdout, "  Toast AppId (blank = default): ")
+			fmt.Fprintf(a.stdout, "  %s", l.T("Toast AppId (blank = default): "))
 			appID, e := readInteractiveLine(reader, nil)
 			if e != nil {
 				return fmt.Errorf("read app id: %w", e)
@@ -666,51 +771,56 @@ func (a *App) runInteractiveLineUI(prefs *Preferences) error {
 			a.printSavedOrSession(*prefs)
 		case "6":
 			if err := a.previewNotification(*prefs); err != nil {
-				fmt.Fprintf(a.stderr, "  %s%s✗%s preview failed: %v\n", colorBold, colorRed, colorReset, err)
+				fmt.Fprintf(a.stderr, "  %s%s✗%s %s: %v\n", colorBold, colorRed, colorReset, l.T("preview failed"), err)
 			} else {
-				fmt.Fprintf(a.stdout, "  %s%s✓%s Preview sent.\n", colorBold, colorGreen, colorReset)
+				fmt.Fprintf(a.stdout, "  %s%s✓%s %s\n", colorBold, colorGreen, colorReset, l.T("Preview sent."))
 			}
 		case "7":
 			if err := a.runInstall([]string{"codex"}); err != nil {
-				fmt.Fprintf(a.stderr, "  %s%s✗%s Codex install failed: %v\n", colorBold, colorRed, colorReset, err)
+				fmt.Fprintf(a.stderr, "  %s%s✗%s %s: %v\n", colorBold, colorRed, colorReset, l.T("Codex install failed"), err)
 			} else {
-				fmt.Fprintf(a.stdout, "  %s%s✓%s Codex hook installed.\n", colorBold, colorGreen, colorReset)
+				fmt.Fprintf(a.stdout, "  %s%s✓%s %s\n", colorBold, colorGreen, colorReset, l.T("Codex hook installed."))
 			}
 		case "8":
 			if err := a.runInstall([]string{"claude"}); err != nil {
-				fmt.Fprintf(a.stderr, "  %s%s✗%s Claude install failed: %v\n", colorBold, colorRed, colorReset, err)
+				fmt.Fprintf(a.stderr, "  %s%s✗%s %s: %v\n", colorBold, colorRed, colorReset, l.T("Claude install failed"), err)
 			} else {
-				fmt.Fprintf(a.stdout, "  %s%s✓%s Claude Code hook installed.\n", colorBold, colorGreen, colorReset)
+				fmt.Fprintf(a.stdout, "  %s%s✓%s %s\n", colorBold, colorGreen, colorReset, l.T("Claude Code hook installed."))
 			}
 		case "9":
 			if err := a.savePreferences(*prefs); err != nil {
-				fmt.Fprintf(a.stderr, "  %s%s✗%s save failed: %v\n", colorBold, colorRed, colorReset, err)
+				fmt.Fprintf(a.stderr, "  %s%s✗%s %s: %v\n", colorBold, colorRed, colorReset, l.T("save failed"), err)
 			} else {
-				fmt.Fprintf(a.stdout, "  %s%s✓ Saved.%s\n", colorBold, colorGreen, colorReset)
+				fmt.Fprintf(a.stdout, "  %s\n", doneText(l, "Saved."))
 			}
+		case "l", "L":
+			prefs.Language = nextLanguage(prefs.Language)
+			a.printSavedOrSession(*prefs)
+			fmt.Fprintf(a.stdout, "  %s -> %s%s%s\n", prefs.lang().T("Language"), colorCyan, languageLabel(prefs.Language), colorReset)
 		case "0", "q", "quit", "exit":
 			if err := a.savePreferences(*prefs); err != nil {
-				fmt.Fprintf(a.stderr, "  %snote:%s save on exit failed: %v\n", colorYellow, colorReset, err)
+				fmt.Fprintf(a.stderr, "  %s%s%s %s: %v\n", colorYellow, l.T("note:"), colorReset, l.T("save on exit failed"), err)
 			}
-			fmt.Fprintf(a.stdout, "\n  %s%sGoodbye!%s\n\n", colorDim, colorCyan, colorReset)
+			fmt.Fprintf(a.stdout, "\n  %s%s%s%s\n\n", colorDim, colorCyan, l.T("Goodbye!"), colorReset)
 			return nil
 		default:
-			fmt.Fprintf(a.stderr, "  %sUnknown option. Choose 1-9 or 0 to exit.%s\n", colorDim, colorReset)
+			fmt.Fprintf(a.stderr, "  %s%s%s\n", colorDim, l.T("Unknown option. Choose 1-9, L or 0 to exit."), colorReset)
 		}
 	}
 }
 
 func (a *App) renderInteractiveMenu(p Preferences) {
+	l := p.lang()
 	fmt.Fprintln(a.stdout)
-	a.renderHeader()
+	a.renderHeader(l)
 
 	// Simple status for line-based UI
 	statusStr := "ON"
 	if !p.Enabled {
 		statusStr = "OFF"
 	}
-	fmt.Fprintf(a.stdout, "  Status: %s  Mode: %s  Content: %s\n", statusStr, p.Mode, p.Content)
-	fmt.Fprintf(a.stdout, "  %s%sSettings auto-saved to disk on every change.%s\n", colorDim, symDot+" ", colorReset)
+	fmt.Fprintf(a.stdout, "  %s\n", l.Sprintf("Status: %s  Mode: %s  Content: %s", statusStr, p.Mode, p.Content))
+	fmt.Fprintf(a.stdout, "  %s%s%s%s\n", colorDim, symDot+" ", l.T("Settings auto-saved to disk on every change."), colorReset)
 	fmt.Fprintln(a.stdout)
 
 	type menuSection struct {
@@ -735,6 +845,7 @@ func (a *App) renderInteractiveMenu(p Preferences) {
 				{"3", "Cycle content mode", "summary/full/complete"},
 				{"4", "Cycle approval prompt mode", "popup/toast/terminal"},
 				{"5", "Set Toast AppId", ""},
+				{"L", "Cycle language", "auto/en/zh-CN"},
 			},
 		},
 		{
@@ -754,7 +865,7 @@ func (a *App) renderInteractiveMenu(p Preferences) {
 	}
 
 	for _, section := range sections {
-		fmt.Fprintf(a.stdout, "  %s%s%s %s%s\n", colorMagenta, symBar, colorReset, colorBold+section.label, colorReset)
+		fmt.Fprintf(a.stdout, "  %s%s%s %s%s\n", colorMagenta, symBar, colorReset, colorBold+l.T(section.label), colorReset)
 		for _, item := range section.items {
 			hint := ""
 			if item.hint != "" {
@@ -764,13 +875,13 @@ func (a *App) renderInteractiveMenu(p Preferences) {
 				colorMagenta, symBar, colorReset,
 				colorCyan, item.key, colorReset,
 				colorDim+")"+colorReset,
-				item.text, hint)
+				l.T(item.text), hint)
 		}
 		fmt.Fprintln(a.stdout)
 	}
 }
 
-func (a *App) selectSingleTTY(title, status string, options []string, cursor int) (int, error) {
+func (a *App) selectSingleTTY(l i18n.Language, title, status string, options []string, cursor int) (int, error) {
 	restore, ok := enableRawInput(a.stdin, a.stdout)
 	if !ok {
 		return -1, fmt.Errorf("raw input unavailable")
@@ -803,10 +914,10 @@ func (a *App) selectSingleTTY(title, status string, options []string, cursor int
 
 		fmt.Fprintln(a.stdout)
 		fmt.Fprintf(a.stdout, "  %s%s%s\n", colorDim, strings.Repeat(symHLine, 50), colorReset)
-		fmt.Fprintf(a.stdout, "  %s↑/↓%s navigate  %s⏎%s confirm  %sesc%s back\n",
-			colorBold, colorReset,
-			colorBold, colorReset,
-			colorBold, colorReset)
+		fmt.Fprintf(a.stdout, "  %s↑/↓%s %s  %s⏎%s %s  %sesc%s %s\n",
+			colorBold, colorReset, l.T("navigate"),
+			colorBold, colorReset, l.T("confirm"),
+			colorBold, colorReset, l.T("back"))
 
 		key, err := readKey(reader)
 		if err != nil {
@@ -829,7 +940,7 @@ func (a *App) selectSingleTTY(title, status string, options []string, cursor int
 	}
 }
 
-func (a *App) selectMultiTTY(title, status string, options []string, selected map[int]bool) (map[int]bool, error) {
+func (a *App) selectMultiTTY(l i18n.Language, title, status string, options []string, selected map[int]bool) (map[int]bool, error) {
 	restore, ok := enableRawInput(a.stdin, a.stdout)
 	if !ok {
 		return selected, nil
@@ -861,10 +972,10 @@ func (a *App) selectMultiTTY(title, status string, options []string, selected ma
 
 		fmt.Fprintln(a.stdout)
 		fmt.Fprintf(a.stdout, "  %s%s%s\n", colorDim, strings.Repeat(symHLine, 50), colorReset)
-		fmt.Fprintf(a.stdout, "  %s↑/↓%s navigate  %sspace%s toggle  %s⏎%s confirm\n",
-			colorBold, colorReset,
-			colorBold, colorReset,
-			colorBold, colorReset)
+		fmt.Fprintf(a.stdout, "  %s↑/↓%s %s  %sspace%s %s  %s⏎%s %s\n",
+			colorBold, colorReset, l.T("navigate"),
+			colorBold, colorReset, l.T("toggle"),
+			colorBold, colorReset, l.T("confirm"))
 
 		key, err := readKey(reader)
 		if err != nil {
@@ -951,9 +1062,9 @@ func boolPtr(v bool) *bool { return &v }
 
 func (a *App) saveOrSessionText(p Preferences) string {
 	if err := a.savePreferences(p); err != nil {
-		return fmt.Sprintf("%s%s✗ Save failed:%s %v", colorBold, colorRed, colorReset, err)
+		return failedText(p.lang(), "Save failed:", err)
 	}
-	return fmt.Sprintf("%s%s✓ Saved.%s", colorBold, colorGreen, colorReset)
+	return doneText(p.lang(), "Saved.")
 }
 
 func (a *App) printSavedOrSession(p Preferences) {
@@ -962,24 +1073,7 @@ func (a *App) printSavedOrSession(p Preferences) {
 }
 
 func (a *App) previewNotification(p Preferences) error {
-	title, body, _ := event.RenderNotificationWithOptions(event.Payload{
-		Type:                 "agent-turn-complete",
-		Summary:              "Sample summary: work completed.",
-		LastAssistantMessage: "Sample full answer: all requested changes are finished.",
-		CWD:                  "C:\\sample\\project",
-		Model:                "gpt-5",
-	}, event.RenderOptions{
-		ContentMode:  event.ContentMode(p.Content),
-		IncludeDir:   p.IncludeDir,
-		IncludeModel: p.IncludeModel,
-		IncludeEvent: p.IncludeEvent,
-	})
-
-	service := notifier.NewWithConfig(notifier.Config{
-		Mode:       p.Mode,
-		ToastAppID: p.ToastAppID,
-	})
-	return service.Notify(title, body)
+	return a.previewWithOverrides(p, "codex", p.Mode, p.Content)
 }
 
 func nextMode(current string) string {
@@ -988,6 +1082,8 @@ func nextMode(current string) string {
 		return "toast"
 	case "toast":
 		return "popup"
+	case "popup":
+		return "terminal"
 	default:
 		return "auto"
 	}
@@ -999,11 +1095,24 @@ func modeHint(mode string) string {
 		return "Windows system notification"
 	case "popup":
 		return "popup dialog"
+	case "terminal":
+		return "escape sequence to the agent terminal"
 	default:
 		return "toast first, popup fallback"
 	}
 }
 
+func nextLanguage(current string) string {
+	switch current {
+	case "":
+		return string(i18n.English)
+	case string(i18n.English):
+		return string(i18n.SimplifiedChinese)
+	default:
+		return ""
+	}
+}
+
 func pausePromptHint(mode string) string {
 	switch mode {
 	case "toast":
@@ -1134,8 +1243,10 @@ func indexOf(options []string, current string) int {
 
 func (a *App) previewModeChoice(p Preferences) error {
 	service := notifier.NewWithConfig(notifier.Config{
-		Mode:       p.Mode,
-		ToastAppID: p.ToastAppID,
+		Mode:          p.Mode,
+		ToastAppID:    p.ToastAppID,
+		TerminalStyle: p.TerminalStyle,
 	})
-	return service.Notify("Notification Mode Selected", "Mode: "+p.Mode+" ("+modeHint(p.Mode)+")")
+	l := p.lang()
+	return service.Notify(l.T("Notification Mode Selected"), l.Sprintf("Mode: %s (%s)", p.Mode, l.T(modeHint(p.Mode))))
 }
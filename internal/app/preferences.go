@@ -3,10 +3,19 @@ package app
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"cc-notify/internal/event"
+	"cc-notify/internal/i18n"
+	"cc-notify/internal/notifier"
+	"cc-notify/internal/redact"
+	"cc-notify/internal/source"
 )
 
 const (
@@ -16,6 +25,19 @@ const (
 	defaultToastAppID  = "cc-notify.desktop"
 	legacyToastAppID   = "Windows PowerShell"
 	legacyToastAppID2  = "codex-notified.desktop"
+
+	defaultResponderAddr = "127.0.0.1:8787"
+
+	defaultRunMinSeconds = 10
+	defaultRunLines      = 10
+
+	defaultApprovalTimeoutSeconds = 300
+
+	// privateFileMode and privateDirMode keep what cc-notify stores next
+	// to the settings private: the credentials in settings.json, and the
+	// commands and feedback of approvals and snapshots.
+	privateFileMode = 0o600
+	privateDirMode  = 0o700
 )
 
 // Preferences stores user-facing behavior controls for notifications.
@@ -32,45 +54,508 @@ type Preferences struct {
 	ToastAppID       string `json:"toast_app_id"`
 	SetupDone        bool   `json:"setup_done"`
 
-	// Per-tool overrides. Empty string means "use global default".
-	CodexEnabled  *bool  `json:"codex_enabled,omitempty"`
-	CodexMode     string `json:"codex_mode,omitempty"`
-	CodexContent  string `json:"codex_content,omitempty"`
-	ClaudeEnabled *bool  `json:"claude_enabled,omitempty"`
-	ClaudeMode    string `json:"claude_mode,omitempty"`
-	ClaudeContent string `json:"claude_content,omitempty"`
+	// Turn statistics read from the session transcript.
+	IncludeDuration bool `json:"include_duration,omitempty"`
+	IncludeTokens   bool `json:"include_tokens,omitempty"`
+	IncludeCost     bool `json:"include_cost,omitempty"`
+	IncludeTools    bool `json:"include_tools,omitempty"`
+	// Git context of the project directory, read from its .git directory.
+	IncludeGitBranch   bool `json:"include_git_branch,omitempty"`
+	IncludeGitHead     bool `json:"include_git_head,omitempty"`
+	IncludeGitStatus   bool `json:"include_git_status,omitempty"`
+	IncludeGitWorktree bool `json:"include_git_worktree,omitempty"`
+	// IncludeChanges counts the files and lines changed during a turn,
+	// against a snapshot of the working tree taken at its start.
+	IncludeChanges bool `json:"include_changes,omitempty"`
+	// Prices are USD per million tokens by model name prefix, used to
+	// estimate the cost of a turn.
+	Prices event.PriceTable `json:"prices,omitempty"`
+	// Templates replace the title or body of matching events.
+	Templates []TemplatePreferences `json:"templates,omitempty"`
+	// MaxLength caps the message body in runes by channel name, e.g.
+	// {"desktop": 200, "telegram": 1000}. The "default" entry applies to
+	// channels without their own; without it bodies stop at 300.
+	MaxLength map[string]int `json:"max_length,omitempty"`
+
+	// Language of notifications and the interactive UI: "auto" (empty)
+	// follows the locale, or a tag such as "en" or "zh-CN".
+	Language string `json:"language,omitempty"`
+
+	// TerminalStyle is the escape sequence used by mode "terminal":
+	// auto (empty), osc9, osc777 or bell.
+	TerminalStyle string `json:"terminal_style,omitempty"`
+
+	// Toast customizes Windows toasts: images, sound and the scenario of
+	// approval toasts.
+	Toast *ToastPreferences `json:"toast,omitempty"`
+
+	// Tools override the defaults per agent, keyed by adapter name such as
+	// "codex", "claude", "gemini" or "aider".
+	Tools map[string]ToolPreferences `json:"tools,omitempty"`
+
+	// Per-tool overrides from older releases, moved into Tools when
+	// settings are loaded.
+	CodexEnabled  *bool    `json:"codex_enabled,omitempty"`
+	CodexMode     string   `json:"codex_mode,omitempty"`
+	CodexContent  string   `json:"codex_content,omitempty"`
+	ClaudeEnabled *bool    `json:"claude_enabled,omitempty"`
+	ClaudeMode    string   `json:"claude_mode,omitempty"`
+	ClaudeContent string   `json:"claude_content,omitempty"`
+	ClaudeEvents  []string `json:"claude_events,omitempty"`
+
+	// Remote channels. Nil means the channel is not configured.
+	Webhook  *WebhookPreferences     `json:"webhook,omitempty"`
+	Ntfy     *NtfyPreferences        `json:"ntfy,omitempty"`
+	Telegram *TelegramPreferences    `json:"telegram,omitempty"`
+	Slack    *ChatWebhookPreferences `json:"slack,omitempty"`
+	Discord  *ChatWebhookPreferences `json:"discord,omitempty"`
+	Teams    *ChatWebhookPreferences `json:"teams,omitempty"`
+	Email    *EmailPreferences       `json:"email,omitempty"`
+
+	// Channels lists further channels as URLs, e.g. ntfy://ntfy.sh/topic or
+	// desktop://toast. A desktop:// entry replaces mode, toast_app_id and
+	// terminal_style; a list without one turns the desktop notifier off.
+	Channels []string `json:"channels,omitempty"`
+
+	// Routes pick the channels for matching events. The first matching
+	// route wins; events that match none go to every channel.
+	Routes []RoutePreferences `json:"routes,omitempty"`
+
+	// Responder configures `cc-notify serve`, the HTTP endpoint that remote
+	// approval actions call back into.
+	Responder *ResponderPreferences `json:"responder,omitempty"`
+
+	// Run configures `cc-notify run`, which notifies when a command ends.
+	Run *RunPreferences `json:"run,omitempty"`
+
+	// Redact hides secrets in notifications before they are sent.
+	Redact *RedactPreferences `json:"redact,omitempty"`
+
+	// ClaudeApproval configures Claude Code hooks that wait for the user
+	// to approve a tool call and answer Claude themselves.
+	ClaudeApproval *ClaudeApprovalPreferences `json:"claude_approval,omitempty"`
+}
+
+// ToolPreferences overrides the defaults for one agent. Empty fields use
+// the global default.
+type ToolPreferences struct {
+	Enabled *bool  `json:"enabled,omitempty"`
+	Mode    string `json:"mode,omitempty"`
+	Content string `json:"content,omitempty"`
+	// Events are the hook events `install` registers for agents with a
+	// choice of them, e.g. Stop, SubagentStop or PreToolUse for Claude
+	// Code. Empty means the agent's defaults.
+	Events []string `json:"events,omitempty"`
+}
+
+func (t ToolPreferences) isZero() bool {
+	return t.Enabled == nil && t.Mode == "" && t.Content == "" && len(t.Events) == 0
+}
+
+// ToastPreferences customizes Windows toasts. Images are absolute paths or
+// file:, http(s): URIs; audio is a Windows sound name such as "Reminder" or
+// "silent".
+type ToastPreferences struct {
+	Logo             string `json:"logo,omitempty"`
+	LogoCircle       bool   `json:"logo_circle,omitempty"`
+	HeroImage        string `json:"hero_image,omitempty"`
+	Audio            string `json:"audio,omitempty"`
+	ApprovalScenario string `json:"approval_scenario,omitempty"`
+}
+
+// toastOptions returns the toast customization for notifier configs.
+func (p Preferences) toastOptions() notifier.ToastOptions {
+	if p.Toast == nil {
+		return notifier.ToastOptions{}
+	}
+	return notifier.ToastOptions{
+		Logo:             strings.TrimSpace(p.Toast.Logo),
+		LogoCircle:       p.Toast.LogoCircle,
+		HeroImage:        strings.TrimSpace(p.Toast.HeroImage),
+		Audio:            strings.TrimSpace(p.Toast.Audio),
+		ApprovalScenario: p.Toast.ApprovalScenario,
+	}
+}
+
+// WebhookPreferences configures the generic HTTP webhook channel.
+type WebhookPreferences struct {
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+}
+
+// NtfyPreferences configures the ntfy publisher.
+type NtfyPreferences struct {
+	Server   string   `json:"server,omitempty"`
+	Topic    string   `json:"topic"`
+	Token    string   `json:"token,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// RoutePreferences sends matching events to a fixed set of channels. Empty
+// match fields match anything; source, dir and model accept globs and event
+// accepts "complete" or "paused". An empty channel list mutes the event.
+type RoutePreferences struct {
+	Source   string   `json:"source,omitempty"`
+	Event    string   `json:"event,omitempty"`
+	Dir      string   `json:"dir,omitempty"`
+	Model    string   `json:"model,omitempty"`
+	Channels []string `json:"channels"`
+}
+
+// TemplatePreferences renders the title and body of matching events with
+// text/template; see event.TemplateData for the fields. Empty match fields
+// match anything and event accepts "complete" or "paused" like routes. An
+// empty title or body keeps the default.
+type TemplatePreferences struct {
+	Source string `json:"source,omitempty"`
+	Event  string `json:"event,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+func (t TemplatePreferences) matches(source, eventType string) bool {
+	if t.Source != "" && !strings.EqualFold(strings.TrimSpace(t.Source), source) {
+		return false
+	}
+	if t.Event != "" {
+		want := strings.ToLower(strings.TrimSpace(t.Event))
+		eventType = strings.ToLower(eventType)
+		return eventType == want || eventType == "agent-turn-"+want
+	}
+	return true
+}
+
+// withTemplates adds the templates for source and eventType to opts. For
+// title and body separately, the first matching template that sets one
+// wins.
+func (p Preferences) withTemplates(opts event.RenderOptions, source, eventType string) (event.RenderOptions, error) {
+	opts.Source = source
+	var errs []error
+	for i, t := range p.Templates {
+		if !t.matches(source, eventType) {
+			continue
+		}
+		if opts.TitleTemplate == nil && strings.TrimSpace(t.Title) != "" {
+			tmpl, err := event.ParseTemplate("title", t.Title)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("templates[%d].title: %w", i, err))
+			}
+			opts.TitleTemplate = tmpl
+		}
+		if opts.BodyTemplate == nil && strings.TrimSpace(t.Body) != "" {
+			tmpl, err := event.ParseTemplate("body", t.Body)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("templates[%d].body: %w", i, err))
+			}
+			opts.BodyTemplate = tmpl
+		}
+	}
+	return opts, errors.Join(errs...)
+}
+
+// validateTemplates parses and test-renders every template so mistakes are
+// reported when settings are saved rather than when a notification fires.
+func validateTemplates(templates []TemplatePreferences) error {
+	var errs []error
+	for i, t := range templates {
+		for _, field := range []struct{ name, text string }{{"title", t.Title}, {"body", t.Body}} {
+			if strings.TrimSpace(field.text) == "" {
+				continue
+			}
+			tmpl, err := event.ParseTemplate(field.name, field.text)
+			if err == nil {
+				err = event.CheckTemplate(tmpl)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("templates[%d].%s: %w", i, field.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p Preferences) notifierRoutes() []notifier.Route {
+	routes := make([]notifier.Route, 0, len(p.Routes))
+	for _, r := range p.Routes {
+		routes = append(routes, notifier.Route{
+			Source:   r.Source,
+			Event:    r.Event,
+			Dir:      r.Dir,
+			Model:    r.Model,
+			Channels: r.Channels,
+		})
+	}
+	return routes
+}
+
+// ChatWebhookPreferences configures a Slack, Discord or Teams incoming webhook.
+type ChatWebhookPreferences struct {
+	URL string `json:"url"`
+}
+
+// EmailPreferences configures the SMTP email channel.
+type EmailPreferences struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	// Security is starttls (default), tls or none.
+	Security string `json:"security,omitempty"`
+}
+
+// TelegramPreferences configures the Telegram bot channel.
+type TelegramPreferences struct {
+	// APIBase overrides https://api.telegram.org, e.g. for a self-hosted Bot API server.
+	APIBase string `json:"api_base,omitempty"`
+	Token   string `json:"token"`
+	ChatID  string `json:"chat_id"`
+}
+
+// ResponderPreferences configures the approval callback endpoint.
+type ResponderPreferences struct {
+	// Addr is the listen address for `cc-notify serve`.
+	Addr string `json:"addr,omitempty"`
+	// PublicURL is how remote clients reach the endpoint; defaults to http://Addr.
+	PublicURL string `json:"public_url,omitempty"`
+	// Token is required as a bearer token on every callback when set.
+	Token string `json:"token,omitempty"`
+}
+
+// callbackURL returns the base URL remote channels should call back into.
+func (r *ResponderPreferences) callbackURL() string {
+	if r == nil {
+		return ""
+	}
+	if u := strings.TrimSpace(r.PublicURL); u != "" {
+		return u
+	}
+	return "http://" + r.listenAddr()
+}
+
+func (r *ResponderPreferences) listenAddr() string {
+	if r == nil || strings.TrimSpace(r.Addr) == "" {
+		return defaultResponderAddr
+	}
+	return strings.TrimSpace(r.Addr)
+}
+
+func (r *ResponderPreferences) token() string {
+	if r == nil {
+		return ""
+	}
+	return strings.TrimSpace(r.Token)
+}
+
+// RedactPreferences configures secret redaction. Levels are off, standard
+// or strict; see redact.Level.
+type RedactPreferences struct {
+	// Local is the level of the desktop notifier, standard by default.
+	Local string `json:"local,omitempty"`
+	// Remote is the level of every other channel, strict by default.
+	Remote string `json:"remote,omitempty"`
+	// Patterns are regular expressions for further secrets, hidden unless
+	// the level is off.
+	Patterns []string `json:"patterns,omitempty"`
+}
+
+// redaction returns the redactor and the levels of local and remote
+// channels. Invalid patterns are reported and left out.
+func (p Preferences) redaction() (r *redact.Redactor, local, remote redact.Level, err error) {
+	settings := p.Redact
+	if settings == nil {
+		settings = &RedactPreferences{}
+	}
+	r, patternErr := redact.New(settings.Patterns)
+	local, localErr := redact.ParseLevel(settings.Local, redact.Standard)
+	remote, remoteErr := redact.ParseLevel(settings.Remote, redact.Strict)
+	return r, local, remote, errors.Join(patternErr, localErr, remoteErr)
+}
+
+// RunPreferences configures `cc-notify run`.
+type RunPreferences struct {
+	// MinSeconds is how long a command must run for its end to be
+	// announced; unset means 10 seconds and 0 announces every command.
+	MinSeconds *int `json:"min_seconds,omitempty"`
+	// Lines is how many of the last output lines are kept; 0 means 10.
+	Lines int `json:"lines,omitempty"`
 }
 
-// ToolPrefs returns the effective mode/content/enabled for the given source.
-// source is "codex" or "claude". Falls back to global defaults.
+func (r *RunPreferences) minDuration() time.Duration {
+	if r == nil || r.MinSeconds == nil || *r.MinSeconds < 0 {
+		return defaultRunMinSeconds * time.Second
+	}
+	return time.Duration(*r.MinSeconds) * time.Second
+}
+
+func (r *RunPreferences) lines() int {
+	if r == nil || r.Lines <= 0 {
+		return defaultRunLines
+	}
+	return r.Lines
+}
+
+// ClaudeApprovalPreferences configures approvals answered through Claude
+// Code's PermissionRequest and PreToolUse hooks instead of keys typed into
+// the terminal.
+type ClaudeApprovalPreferences struct {
+	// TimeoutSeconds is how long a hook waits for the decision; 0 means
+	// 300 seconds.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// Default is the decision when none arrives in time or no channel
+	// could ask: "ask" (empty) leaves it to Claude Code's own prompt,
+	// "allow" or "deny" answer for the user.
+	Default string `json:"default,omitempty"`
+	// Tools are the tools whose PreToolUse hook waits for a decision,
+	// e.g. ["Bash"], or ["*"] for all. PermissionRequest hooks always
+	// wait.
+	Tools []string `json:"tools,omitempty"`
+}
+
+func (c *ClaudeApprovalPreferences) timeout() time.Duration {
+	if c == nil || c.TimeoutSeconds <= 0 {
+		return defaultApprovalTimeoutSeconds * time.Second
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+func (c *ClaudeApprovalPreferences) defaultDecision() string {
+	if c == nil || c.Default == "" {
+		return event.ClaudeAsk
+	}
+	return c.Default
+}
+
+// waitsFor reports whether the PreToolUse hook of tool waits for a
+// decision.
+func (c *ClaudeApprovalPreferences) waitsFor(tool string) bool {
+	if c == nil {
+		return false
+	}
+	for _, name := range c.Tools {
+		if name == "*" || strings.EqualFold(strings.TrimSpace(name), tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolPrefs returns the effective mode/content/enabled for the given source,
+// an adapter name such as "codex". Falls back to global defaults.
 func (p Preferences) ToolPrefs(source string) (enabled bool, mode string, content string) {
 	enabled = p.Enabled
 	mode = p.Mode
 	content = p.Content
 
-	switch source {
-	case "codex":
-		if p.CodexEnabled != nil {
-			enabled = *p.CodexEnabled
-		}
-		if p.CodexMode != "" {
-			mode = p.CodexMode
+	tool := p.Tools[source]
+	if tool.Enabled != nil {
+		enabled = *tool.Enabled
+	}
+	if tool.Mode != "" {
+		mode = tool.Mode
+	}
+	if tool.Content != "" {
+		content = tool.Content
+	}
+	return
+}
+
+// setTool stores the overrides for the named source.
+func (p *Preferences) setTool(name string, tool ToolPreferences) {
+	if tool.isZero() {
+		delete(p.Tools, name)
+		return
+	}
+	if p.Tools == nil {
+		p.Tools = map[string]ToolPreferences{}
+	}
+	p.Tools[name] = tool
+}
+
+// normalizeTools moves the per-tool fields of older releases into Tools,
+// where they do not override what is set there, and normalizes the hook
+// events of every agent.
+func normalizeTools(p Preferences) map[string]ToolPreferences {
+	tools := map[string]ToolPreferences{}
+	for name, tool := range p.Tools {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			tools[name] = tool
 		}
-		if p.CodexContent != "" {
-			content = p.CodexContent
+	}
+	legacy := map[string]ToolPreferences{
+		"codex":  {Enabled: p.CodexEnabled, Mode: p.CodexMode, Content: p.CodexContent},
+		"claude": {Enabled: p.ClaudeEnabled, Mode: p.ClaudeMode, Content: p.ClaudeContent, Events: p.ClaudeEvents},
+	}
+	for name, old := range legacy {
+		tool := tools[name]
+		if tool.Enabled == nil {
+			tool.Enabled = old.Enabled
 		}
-	case "claude":
-		if p.ClaudeEnabled != nil {
-			enabled = *p.ClaudeEnabled
+		tool.Mode = firstNonEmptyString(tool.Mode, old.Mode)
+		tool.Content = firstNonEmptyString(tool.Content, old.Content)
+		if len(tool.Events) == 0 {
+			tool.Events = old.Events
 		}
-		if p.ClaudeMode != "" {
-			mode = p.ClaudeMode
+		tools[name] = tool
+	}
+
+	for name, tool := range tools {
+		tool.Mode = strings.TrimSpace(tool.Mode)
+		tool.Content = strings.TrimSpace(tool.Content)
+		if src, ok := source.Lookup(name); ok {
+			if selector, ok := src.(source.EventSelector); ok {
+				tool.Events, _ = selector.NormalizeEvents(tool.Events)
+			}
 		}
-		if p.ClaudeContent != "" {
-			content = p.ClaudeContent
+		if tool.isZero() {
+			delete(tools, name)
+		} else {
+			tools[name] = tool
 		}
 	}
-	return
+	if len(tools) == 0 {
+		return nil
+	}
+	return tools
+}
+
+// renderOptions returns how notifications are rendered with content mode
+// content.
+func (p Preferences) renderOptions(content string) event.RenderOptions {
+	return event.RenderOptions{
+		ContentMode:        event.ContentMode(content),
+		IncludeDir:         p.IncludeDir,
+		IncludeModel:       p.IncludeModel,
+		IncludeEvent:       p.IncludeEvent,
+		IncludeDuration:    p.IncludeDuration,
+		IncludeTokens:      p.IncludeTokens,
+		IncludeCost:        p.IncludeCost,
+		IncludeTools:       p.IncludeTools,
+		IncludeGitBranch:   p.IncludeGitBranch,
+		IncludeGitHead:     p.IncludeGitHead,
+		IncludeGitStatus:   p.IncludeGitStatus,
+		IncludeGitWorktree: p.IncludeGitWorktree,
+		IncludeChanges:     p.IncludeChanges,
+		MaxLength:          p.MaxLength[defaultMaxLengthKey],
+		Language:           p.lang(),
+	}
+}
+
+// lang returns the language text is shown in.
+func (p Preferences) lang() i18n.Language {
+	return i18n.Resolve(p.Language)
+}
+
+// defaultMaxLengthKey is the MaxLength entry used by channels without one.
+const defaultMaxLengthKey = "default"
+
+// channelMaxLength returns the body limit set for channel name, or 0 when
+// the channel uses the default.
+func (p Preferences) channelMaxLength(name string) int {
+	return p.MaxLength[strings.ToLower(name)]
 }
 
 func DefaultPreferences() Preferences {
@@ -108,7 +593,7 @@ func normalizePreferences(p Preferences) Preferences {
 	if p.ToastAppID == legacyToastAppID || p.ToastAppID == legacyToastAppID2 {
 		p.ToastAppID = def.ToastAppID
 	}
-	if p.Mode != "auto" && p.Mode != "toast" && p.Mode != "popup" {
+	if p.Mode != "auto" && p.Mode != "toast" && p.Mode != "popup" && p.Mode != "terminal" {
 		p.Mode = def.Mode
 	}
 	switch p.Content {
@@ -121,6 +606,85 @@ func normalizePreferences(p Preferences) Preferences {
 	default:
 		p.PausePrompt = def.PausePrompt
 	}
+	switch p.TerminalStyle {
+	case "", notifier.TerminalStyleAuto, notifier.TerminalStyleOSC9, notifier.TerminalStyleOSC777, notifier.TerminalStyleBell:
+	default:
+		p.TerminalStyle = ""
+	}
+	if p.Toast != nil {
+		switch p.Toast.ApprovalScenario = strings.TrimSpace(p.Toast.ApprovalScenario); p.Toast.ApprovalScenario {
+		case "", notifier.ToastScenarioDefault, notifier.ToastScenarioReminder, notifier.ToastScenarioUrgent, notifier.ToastScenarioAlarm:
+		default:
+			p.Toast.ApprovalScenario = ""
+		}
+	}
+	if p.Webhook != nil && strings.TrimSpace(p.Webhook.URL) == "" {
+		p.Webhook = nil
+	}
+	if p.Ntfy != nil && strings.TrimSpace(p.Ntfy.Topic) == "" {
+		p.Ntfy = nil
+	}
+	if p.Slack != nil && strings.TrimSpace(p.Slack.URL) == "" {
+		p.Slack = nil
+	}
+	if p.Discord != nil && strings.TrimSpace(p.Discord.URL) == "" {
+		p.Discord = nil
+	}
+	if p.Teams != nil && strings.TrimSpace(p.Teams.URL) == "" {
+		p.Teams = nil
+	}
+	if p.Email != nil && (strings.TrimSpace(p.Email.Host) == "" || len(p.Email.To) == 0) {
+		p.Email = nil
+	}
+	if p.Telegram != nil && (strings.TrimSpace(p.Telegram.Token) == "" || strings.TrimSpace(p.Telegram.ChatID) == "") {
+		p.Telegram = nil
+	}
+	if p.Redact != nil {
+		for _, level := range []*string{&p.Redact.Local, &p.Redact.Remote} {
+			if _, err := redact.ParseLevel(*level, redact.Standard); err != nil {
+				*level = ""
+			}
+			*level = strings.ToLower(strings.TrimSpace(*level))
+		}
+		if p.Redact.Local == "" && p.Redact.Remote == "" && len(p.Redact.Patterns) == 0 {
+			p.Redact = nil
+		}
+	}
+	if p.ClaudeApproval != nil {
+		switch p.ClaudeApproval.Default = strings.ToLower(strings.TrimSpace(p.ClaudeApproval.Default)); p.ClaudeApproval.Default {
+		case event.ClaudeAllow, event.ClaudeDeny, event.ClaudeAsk:
+		default:
+			p.ClaudeApproval.Default = ""
+		}
+		if p.ClaudeApproval.TimeoutSeconds < 0 {
+			p.ClaudeApproval.TimeoutSeconds = 0
+		}
+	}
+	p.Tools = normalizeTools(p)
+	p.CodexEnabled, p.CodexMode, p.CodexContent = nil, "", ""
+	p.ClaudeEnabled, p.ClaudeMode, p.ClaudeContent, p.ClaudeEvents = nil, "", "", nil
+	var maxLength map[string]int
+	for name, limit := range p.MaxLength {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" && limit > 0 {
+			if maxLength == nil {
+				maxLength = map[string]int{}
+			}
+			maxLength[name] = limit
+		}
+	}
+	p.MaxLength = maxLength
+	if lang, ok := i18n.Parse(p.Language); ok {
+		p.Language = string(lang)
+	} else {
+		p.Language = ""
+	}
+	var channels []string
+	for _, raw := range p.Channels {
+		if raw = strings.TrimSpace(raw); raw != "" {
+			channels = append(channels, raw)
+		}
+	}
+	p.Channels = channels
 	if !p.FieldsConfigured {
 		p.IncludeDir = def.IncludeDir
 		p.IncludeModel = def.IncludeModel
@@ -173,8 +737,16 @@ func (a *App) savePreferences(p Preferences) error {
 		return err
 	}
 	p = normalizePreferences(p)
+	if err := validateTemplates(p.Templates); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	if _, _, _, err := p.redaction(); err != nil {
+		return fmt.Errorf("invalid redact settings: %w", err)
+	}
 
-	if err := a.mkdirAll(filepath.Dir(path), 0o755); err != nil {
+	// Settings hold channel credentials, so only the user may read them.
+	dir := filepath.Dir(path)
+	if err := a.mkdirAll(dir, privateDirMode); err != nil {
 		return fmt.Errorf("create settings directory: %w", err)
 	}
 	raw, err := json.MarshalIndent(p, "", "  ")
@@ -182,8 +754,27 @@ func (a *App) savePreferences(p Preferences) error {
 		return fmt.Errorf("encode preferences: %w", err)
 	}
 	raw = append(raw, '\n')
-	if err := a.writeFile(path, raw, 0o644); err != nil {
+	if err := a.writeFile(path, raw, privateFileMode); err != nil {
 		return fmt.Errorf("write preferences: %w", err)
 	}
+	// Files and directories written by earlier versions were readable by
+	// everyone; writing does not change the mode of an existing file.
+	for name, mode := range map[string]fs.FileMode{dir: privateDirMode, path: privateFileMode} {
+		if err := os.Chmod(name, mode); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("restrict preferences: %w", err)
+		}
+	}
+	return nil
+}
+
+// mkdirPrivate creates dir with privateDirMode, and restricts a directory
+// an earlier version created readable by everyone.
+func (a *App) mkdirPrivate(dir string) error {
+	if err := a.mkdirAll(dir, privateDirMode); err != nil {
+		return err
+	}
+	if err := os.Chmod(dir, privateDirMode); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
 	return nil
 }
@@ -3,7 +3,12 @@ package app
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"cc-notify/internal/event"
 )
 
 func TestDefaultPreferences_UsesCodexToastAppID(t *testing.T) {
@@ -55,3 +60,190 @@ func TestLoadPreferences_AcceptsUTF8BOM(t *testing.T) {
 		t.Fatalf("expected mode toast, got %q", prefs.Mode)
 	}
 }
+
+func TestNormalizePreferences_AcceptsTerminalMode(t *testing.T) {
+	p := normalizePreferences(Preferences{Mode: "terminal", TerminalStyle: "osc777"})
+	if p.Mode != "terminal" || p.TerminalStyle != "osc777" {
+		t.Fatalf("expected terminal mode to be kept, got mode=%q style=%q", p.Mode, p.TerminalStyle)
+	}
+	p = normalizePreferences(Preferences{Mode: "terminal", TerminalStyle: "osc52"})
+	if p.TerminalStyle != "" {
+		t.Fatalf("expected unknown terminal style to fall back to auto, got %q", p.TerminalStyle)
+	}
+}
+
+func TestNormalizePreferences_ToastOptions(t *testing.T) {
+	p := normalizePreferences(Preferences{Toast: &ToastPreferences{Logo: ` C:\logo.png `, Audio: "Reminder", ApprovalScenario: " urgent "}})
+	opts := p.toastOptions()
+	if opts.Logo != `C:\logo.png` || opts.Audio != "Reminder" || opts.ApprovalScenario != "urgent" {
+		t.Fatalf("unexpected toast options: %+v", opts)
+	}
+	p = normalizePreferences(Preferences{Toast: &ToastPreferences{ApprovalScenario: "incomingCall"}})
+	if p.Toast.ApprovalScenario != "" {
+		t.Fatalf("expected unknown approval scenario to fall back to reminder, got %q", p.Toast.ApprovalScenario)
+	}
+}
+
+func TestSavePreferences_RejectsInvalidTemplates(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	tool := New(Options{
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+
+	prefs := DefaultPreferences()
+	prefs.Templates = []TemplatePreferences{
+		{Source: "codex", Title: "{{basename .CWD}}"},
+		{Event: "paused", Body: "{{.Summry}}"},
+	}
+	err := tool.savePreferences(prefs)
+	if err == nil || !strings.Contains(err.Error(), "templates[1].body") || !strings.Contains(err.Error(), "Summry") {
+		t.Fatalf("expected error naming the broken template, got %v", err)
+	}
+	if _, statErr := os.Stat(settingsPath); !os.IsNotExist(statErr) {
+		t.Fatalf("invalid settings must not be written")
+	}
+
+	prefs.Templates[1].Body = "{{.Summary | truncate 40}}"
+	if err := tool.savePreferences(prefs); err != nil {
+		t.Fatalf("save valid templates: %v", err)
+	}
+}
+
+func TestSavePreferences_RestrictsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+	dir := filepath.Join(t.TempDir(), "cc-notify")
+	settingsPath := filepath.Join(dir, "settings.json")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	// A file written by an earlier version, readable by everyone.
+	if err := os.WriteFile(settingsPath, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	tool := New(Options{SettingsPath: func() (string, error) { return settingsPath, nil }})
+
+	if err := tool.savePreferences(DefaultPreferences()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	for name, want := range map[string]os.FileMode{dir: 0o700, settingsPath: 0o600} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Fatalf("%s: got mode %o, want %o", filepath.Base(name), got, want)
+		}
+	}
+}
+
+func TestCreatePendingApproval_RestrictsPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+	dir := t.TempDir()
+	approvals := filepath.Join(dir, "approvals")
+	// A directory created by an earlier version, readable by everyone.
+	if err := os.MkdirAll(approvals, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	tool := New(Options{SettingsPath: func() (string, error) { return filepath.Join(dir, "settings.json"), nil }})
+
+	pending, err := tool.createPendingApproval(1, "", "", 0)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	path, _ := tool.pendingApprovalPath(pending.ID)
+	for name, want := range map[string]os.FileMode{approvals: 0o700, path: 0o600} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Fatalf("%s: got mode %o, want %o", filepath.Base(name), got, want)
+		}
+	}
+}
+
+func TestPreferencesWithTemplates_SelectsBySourceAndEvent(t *testing.T) {
+	p := Preferences{Templates: []TemplatePreferences{
+		{Source: "claude", Event: "agent-idle", Title: "idle"},
+		{Source: "claude", Event: "complete", Body: "claude done"},
+		{Title: "any", Body: "any body"},
+	}}
+	cases := []struct {
+		source, eventType, title, body string
+	}{
+		{"claude", "agent-idle", "idle", "any body"},
+		{"claude", "agent-turn-complete", "any", "claude done"},
+		{"codex", "agent-turn-complete", "any", "any body"},
+	}
+	for _, tc := range cases {
+		opts, err := p.withTemplates(event.RenderOptions{}, tc.source, tc.eventType)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if opts.TitleTemplate.Root.String() != tc.title || opts.BodyTemplate.Root.String() != tc.body {
+			t.Fatalf("%s/%s: got %q / %q", tc.source, tc.eventType, opts.TitleTemplate.Root, opts.BodyTemplate.Root)
+		}
+	}
+}
+
+func TestNormalizePreferences_ClaudeApproval(t *testing.T) {
+	p := normalizePreferences(Preferences{ClaudeApproval: &ClaudeApprovalPreferences{Default: " Deny ", TimeoutSeconds: 90, Tools: []string{"Bash"}}})
+	if c := p.ClaudeApproval; c.defaultDecision() != "deny" || c.timeout() != 90*time.Second || !c.waitsFor("bash") || c.waitsFor("Read") {
+		t.Fatalf("unexpected approval settings: %+v", c)
+	}
+	p = normalizePreferences(Preferences{ClaudeApproval: &ClaudeApprovalPreferences{Default: "maybe", TimeoutSeconds: -5}})
+	if c := p.ClaudeApproval; c.defaultDecision() != "ask" || c.timeout() != 300*time.Second {
+		t.Fatalf("expected invalid settings to fall back to defaults, got %+v", c)
+	}
+	var unset *ClaudeApprovalPreferences
+	if unset.waitsFor("Bash") || unset.defaultDecision() != "ask" {
+		t.Fatal("expected no approval settings to wait for no tool and leave the decision to Claude Code")
+	}
+}
+
+func TestNormalizePreferences_Language(t *testing.T) {
+	for raw, want := range map[string]string{"": "", "auto": "", "zh": "zh-CN", "en_US.UTF-8": "en", "fr": ""} {
+		if got := normalizePreferences(Preferences{Language: raw}).Language; got != want {
+			t.Fatalf("language %q: got %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestNormalizePreferences_MigratesPerToolFields(t *testing.T) {
+	p := normalizePreferences(Preferences{
+		CodexEnabled:  boolPtr(false),
+		CodexMode:     "popup",
+		ClaudeContent: "full",
+		ClaudeEvents:  []string{"notification", "stop", "Teleport"},
+		Tools: map[string]ToolPreferences{
+			"Claude": {Content: "complete"},
+			"Gemini": {Mode: " terminal ", Events: []string{"afteragent"}},
+			"aider":  {},
+		},
+	})
+	want := map[string]ToolPreferences{
+		"codex":  {Enabled: boolPtr(false), Mode: "popup"},
+		"claude": {Content: "complete", Events: []string{"Stop", "Notification"}},
+		"gemini": {Mode: "terminal", Events: []string{"AfterAgent"}},
+	}
+	if len(p.Tools) != len(want) {
+		t.Fatalf("unexpected tools: %+v", p.Tools)
+	}
+	for name, w := range want {
+		got := p.Tools[name]
+		if (got.Enabled == nil) != (w.Enabled == nil) || (got.Enabled != nil && *got.Enabled != *w.Enabled) ||
+			got.Mode != w.Mode || got.Content != w.Content || strings.Join(got.Events, ",") != strings.Join(w.Events, ",") {
+			t.Fatalf("%s: got %+v, want %+v", name, got, w)
+		}
+	}
+	if p.CodexEnabled != nil || p.CodexMode != "" || p.ClaudeContent != "" || p.ClaudeEvents != nil {
+		t.Fatalf("expected legacy fields cleared: %+v", p)
+	}
+	if enabled, mode, content := p.ToolPrefs("codex"); enabled || mode != "popup" || content != "summary" {
+		t.Fatalf("unexpected codex prefs: %v %q %q", enabled, mode, content)
+	}
+}
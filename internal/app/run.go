@@ -0,0 +1,222 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"cc-notify/internal/event"
+)
+
+// runSource is the name `cc-notify run` notifications carry, for per-tool
+// settings, templates and routes.
+const runSource = "run"
+
+// runCommand runs the command after "--" with the output streamed through,
+// then notifies about its end when it ran for long enough. It returns the
+// exit code of the command; errors are reported for commands that could not
+// be started.
+func (a *App) runCommand(args []string) (int, error) {
+	prefs, _, err := a.loadPreferences()
+	if err != nil {
+		return 1, err
+	}
+	minDuration, lines := prefs.Run.minDuration(), prefs.Run.lines()
+
+	var command []string
+	for i := 0; i < len(args) && command == nil; i++ {
+		switch arg := args[i]; arg {
+		case "--":
+			command = args[i+1:]
+			if len(command) == 0 {
+				return 1, fmt.Errorf("run: command is required")
+			}
+		case "--min-seconds", "--lines":
+			if i+1 >= len(args) {
+				return 1, fmt.Errorf("run: %s requires a value", arg)
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 0 {
+				return 1, fmt.Errorf("run: invalid %s value %q", arg, args[i])
+			}
+			if arg == "--min-seconds" {
+				minDuration = time.Duration(n) * time.Second
+			} else if n > 0 {
+				lines = n
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return 1, fmt.Errorf("run: unknown flag %s (put the command after --)", arg)
+			}
+			command = args[i:]
+		}
+	}
+	if len(command) == 0 {
+		return 1, fmt.Errorf("run: command is required")
+	}
+
+	// Ctrl+C reaches the command too; cc-notify outlives it to report it.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	tail := newTailWriter(lines)
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = a.stdin
+	cmd.Stdout = passThrough(a.stdout, tail)
+	cmd.Stderr = io.MultiWriter(a.stderr, tail)
+	start := time.Now()
+	err = cmd.Run()
+	duration := time.Since(start)
+
+	exitCode := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return 127, fmt.Errorf("run %s: %w", command[0], err)
+		}
+		exitCode = exitErr.ExitCode()
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			// Killed by a signal: exit like a shell would.
+			exitCode = 128 + int(status.Signal())
+		} else if exitCode < 0 {
+			exitCode = 1
+		}
+	}
+
+	if duration < minDuration {
+		return exitCode, nil
+	}
+	cwd, _ := os.Getwd()
+	payload := event.CommandResult{
+		Command:  commandLine(command),
+		ExitCode: exitCode,
+		Duration: duration,
+		CWD:      cwd,
+		Output:   tail.Lines(),
+	}.Payload()
+	if err := a.notifyCommand(prefs, payload); err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	}
+	return exitCode, nil
+}
+
+// passThrough returns what the command writes its output to in place of
+// w. A terminal is handed to the command as is, so it keeps its colors,
+// progress bars and line buffering, and that output is not in tail; other
+// writers get a copy of the output in tail. Stderr is always copied, as
+// the errors that end a failed command are the lines worth notifying.
+func passThrough(w io.Writer, tail io.Writer) io.Writer {
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return f
+		}
+	}
+	return io.MultiWriter(w, tail)
+}
+
+// notifyCommand sends the end of a command with the run settings. The
+// report goes to stderr, as stdout belongs to the command.
+func (a *App) notifyCommand(prefs Preferences, payload event.Payload) error {
+	enabled, mode, content := prefs.ToolPrefs(runSource)
+	if !enabled {
+		fmt.Fprintf(a.stderr, "notifications disabled for %s\n", runSource)
+		return nil
+	}
+	opts, err := prefs.withTemplates(prefs.renderOptions(content), runSource, payload.Type)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	}
+	return a.deliver(prefs, runSource, payload, opts, mode, content, a.stderr)
+}
+
+// commandLine joins args back into a command line, quoting arguments that
+// would not survive as typed.
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ansiEscape matches the color and cursor sequences of terminal output.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+// maxPartialLine bounds the unterminated line a tailWriter holds, for
+// commands that redraw a progress bar without ever ending the line.
+const maxPartialLine = 4096
+
+// tailWriter keeps the last non-blank lines written to it, with terminal
+// escape sequences removed. It is safe for concurrent use, as stdout and
+// stderr are written to it at once.
+type tailWriter struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial []byte
+}
+
+func newTailWriter(max int) *tailWriter {
+	return &tailWriter{max: max}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		w.add(string(data[:i]))
+		data = data[i+1:]
+	}
+	if len(data) > maxPartialLine {
+		data = data[len(data)-maxPartialLine:]
+	}
+	w.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+func (w *tailWriter) add(line string) {
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		// Only the last redraw of the line is what the terminal shows.
+		line = line[i+1:]
+	}
+	line = strings.TrimRight(ansiEscape.ReplaceAllString(line, ""), " \t")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	w.lines = append(w.lines, line)
+	if len(w.lines) > w.max {
+		w.lines = w.lines[len(w.lines)-w.max:]
+	}
+}
+
+// Lines returns the kept lines, including an unterminated last line.
+func (w *tailWriter) Lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.partial) > 0 {
+		w.add(string(w.partial))
+		w.partial = nil
+	}
+	return append([]string(nil), w.lines...)
+}
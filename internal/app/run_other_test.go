@@ -0,0 +1,53 @@
+//go:build !windows
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRun_RunExitsLikeAShellOnSignal(t *testing.T) {
+	settingsPath := writeRunSettings(t, nil)
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{
+		Notifier:     &fakeNotifier{},
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+	if code := tool.Run([]string{"run", "--", "sh", "-c", "kill -TERM $$"}); code != 128+15 {
+		t.Fatalf("expected 143 for a command killed by SIGTERM, got %d (stderr=%q)", code, stderr.String())
+	}
+}
+
+func TestRun_RunKeepsStderrLinesWhenStdoutIsATerminal(t *testing.T) {
+	zero := 0
+	settingsPath := writeRunSettings(t, &RunPreferences{MinSeconds: &zero})
+	// /dev/null is a character device, like a terminal.
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", os.DevNull, err)
+	}
+	defer tty.Close()
+	var stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdout:       tty,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+
+	if code := tool.Run(append([]string{"run", "--"}, helperCommand(t, "progress,!error: undefined: foo", 1)...)); code != 1 {
+		t.Fatalf("expected the exit code of the command, got %d (stderr=%q)", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "error: undefined: foo") {
+		t.Fatalf("expected stderr to stream through, got %q", stderr.String())
+	}
+	if !strings.Contains(desktop.body, "error: undefined: foo") || strings.Contains(desktop.body, "progress") {
+		t.Fatalf("expected only the stderr lines in body, got %q", desktop.body)
+	}
+}
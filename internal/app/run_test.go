@@ -0,0 +1,166 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRunHelperProcess is the command wrapped by the run tests. It prints
+// the lines in CC_NOTIFY_RUN_OUTPUT, those starting with "!" to stderr, and
+// exits with CC_NOTIFY_RUN_EXIT.
+func TestRunHelperProcess(t *testing.T) {
+	if os.Getenv("CC_NOTIFY_RUN_HELPER") != "1" {
+		t.Skip("helper process for run tests")
+	}
+	for _, line := range strings.Split(os.Getenv("CC_NOTIFY_RUN_OUTPUT"), ",") {
+		if text, ok := strings.CutPrefix(line, "!"); ok {
+			fmt.Fprintln(os.Stderr, text)
+		} else {
+			fmt.Fprintln(os.Stdout, line)
+		}
+	}
+	code, _ := strconv.Atoi(os.Getenv("CC_NOTIFY_RUN_EXIT"))
+	os.Exit(code)
+}
+
+func helperCommand(t *testing.T, output string, exitCode int) []string {
+	t.Setenv("CC_NOTIFY_RUN_HELPER", "1")
+	t.Setenv("CC_NOTIFY_RUN_OUTPUT", output)
+	t.Setenv("CC_NOTIFY_RUN_EXIT", strconv.Itoa(exitCode))
+	return []string{os.Args[0], "-test.run=^TestRunHelperProcess$"}
+}
+
+func writeRunSettings(t *testing.T, run *RunPreferences) string {
+	t.Helper()
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	settings := DefaultPreferences()
+	settings.Run = run
+	raw, _ := json.Marshal(settings)
+	if err := os.WriteFile(settingsPath, raw, 0o644); err != nil {
+		t.Fatalf("write settings: %v", err)
+	}
+	return settingsPath
+}
+
+func TestRun_RunNotifiesFailureWithOutputTail(t *testing.T) {
+	zero := 0
+	settingsPath := writeRunSettings(t, &RunPreferences{MinSeconds: &zero, Lines: 2})
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+
+	args := append([]string{"run", "--"}, helperCommand(t, "building,compiling,error: undefined: foo", 3)...)
+	if code := tool.Run(args); code != 3 {
+		t.Fatalf("expected the exit code of the command, got %d (stderr=%q)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "building") {
+		t.Fatalf("expected output to stream through, got %q", stdout.String())
+	}
+	if desktop.count != 1 || desktop.title != "Command Failed" {
+		t.Fatalf("expected a failure notification, got %d %q", desktop.count, desktop.title)
+	}
+	if !strings.Contains(desktop.body, "compiling\nerror: undefined: foo") || strings.Contains(desktop.body, "building") {
+		t.Fatalf("expected the last 2 lines in body, got %q", desktop.body)
+	}
+	if !strings.Contains(desktop.body, "Exit code: 3") {
+		t.Fatalf("expected exit code in body, got %q", desktop.body)
+	}
+}
+
+func TestRun_RunSkipsShortCommands(t *testing.T) {
+	settingsPath := writeRunSettings(t, nil)
+	var stdout, stderr bytes.Buffer
+	desktop := &fakeNotifier{}
+	tool := New(Options{
+		Notifier:     desktop,
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+
+	if code := tool.Run(append([]string{"run", "--"}, helperCommand(t, "!done", 0)...)); code != 0 {
+		t.Fatalf("run failed: %q", stderr.String())
+	}
+	if stderr.String() != "done\n" {
+		t.Fatalf("expected stderr to stream through, got %q", stderr.String())
+	}
+	if desktop.count != 0 {
+		t.Fatalf("expected no notification below the threshold, got %d", desktop.count)
+	}
+
+	if code := tool.Run(append([]string{"run", "--min-seconds", "0", "--"}, helperCommand(t, "done", 0)...)); code != 0 {
+		t.Fatalf("run failed: %q", stderr.String())
+	}
+	if desktop.count != 1 || desktop.title != "Command Finished" {
+		t.Fatalf("expected a completion notification, got %d %q", desktop.count, desktop.title)
+	}
+}
+
+func TestRun_RunKeepsStdoutForTheCommand(t *testing.T) {
+	zero := 0
+	settingsPath := writeRunSettings(t, &RunPreferences{MinSeconds: &zero})
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{
+		Notifier:     &fakeNotifier{},
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return settingsPath, nil },
+	})
+
+	if code := tool.Run(append([]string{"run", "--"}, helperCommand(t, "result", 0)...)); code != 0 {
+		t.Fatalf("run failed: %q", stderr.String())
+	}
+	if stdout.String() != "result\n" {
+		t.Fatalf("expected only the command output on stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "notification sent") {
+		t.Fatalf("expected the report on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRun_RunReportsMissingCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	tool := New(Options{
+		Notifier:     &fakeNotifier{},
+		Stdout:       &stdout,
+		Stderr:       &stderr,
+		SettingsPath: func() (string, error) { return filepath.Join(t.TempDir(), "settings.json"), nil },
+	})
+	if code := tool.Run([]string{"run", "--"}); code != 1 || !strings.Contains(stderr.String(), "command is required") {
+		t.Fatalf("expected usage error, got %d %q", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := tool.Run([]string{"run", "--", filepath.Join(t.TempDir(), "missing")}); code != 127 {
+		t.Fatalf("expected 127 for a command that cannot start, got %d %q", code, stderr.String())
+	}
+}
+
+func TestTailWriter_KeepsLastLines(t *testing.T) {
+	w := newTailWriter(3)
+	for _, chunk := range []string{"one\n\ntw", "o\r\n\x1b[31mthree\x1b[0m\n", "10%\r50%\r100%\nlast"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if got, want := w.Lines(), []string{"three", "100%", "last"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestCommandLine_QuotesArguments(t *testing.T) {
+	if got := commandLine([]string{"git", "commit", "-m", "fix it"}); got != `git commit -m "fix it"` {
+		t.Fatalf("unexpected command line: %s", got)
+	}
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cc-notify/internal/notifier"
+)
+
+// telegramRetryDelay is how long serve waits before polling Telegram again
+// after a Bot API failure.
+const telegramRetryDelay = 5 * time.Second
+
+// runServe listens for approval callbacks from remote channels such as ntfy
+// and applies them exactly like a local action button would. When Telegram is
+// configured it also polls the bot for inline keyboard taps.
+func (a *App) runServe(args []string) error {
+	prefs, _, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	addr := prefs.Responder.listenAddr()
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--addr":
+			if i+1 >= len(args) {
+				return fmt.Errorf("serve --addr requires a value")
+			}
+			addr = strings.TrimSpace(args[i+1])
+			i++
+		default:
+			return fmt.Errorf("unknown serve option: %s", args[i])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote, err := remoteChannels(prefs)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	}
+	for _, ch := range remote {
+		if tg, ok := ch.Service.(*notifier.Telegram); ok {
+			fmt.Fprintln(a.stdout, "polling telegram for approval responses")
+			go a.pollTelegram(ctx, tg)
+		}
+	}
+
+	token := prefs.Responder.token()
+	if token == "" {
+		fmt.Fprintln(a.stderr, "warning: responder token is empty; anyone who can reach this address can answer approvals")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/respond", a.respondHandler(token))
+	fmt.Fprintf(a.stdout, "listening for approval callbacks on http://%s/respond\n", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve: %w", err)
+	}
+	return nil
+}
+
+// resolveApprovalURI applies a cc-notify://respond URI. Calls are serialized
+// so two taps cannot race on the same approval file.
+func (a *App) resolveApprovalURI(uri string) error {
+	a.respondMu.Lock()
+	defer a.respondMu.Unlock()
+	return a.runProtocolURI(uri)
+}
+
+// pollTelegram delivers Telegram button taps until ctx is done, retrying
+// after Bot API failures.
+func (a *App) pollTelegram(ctx context.Context, tg *notifier.Telegram) {
+	for {
+		err := tg.PollActions(ctx, a.resolveApprovalURI)
+		if ctx.Err() != nil {
+			return
+		}
+		fmt.Fprintf(a.stderr, "telegram: %v\n", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(telegramRetryDelay):
+		}
+	}
+}
+
+// respondHandler accepts POST /respond?decision=...&id=... and resolves the
+// pending approval. With a token set, the request must carry it as a bearer
+// token, or carry the approval's own notifier.ApprovalToken in the query as
+// the ntfy buttons do.
+func (a *App) respondHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		query := r.URL.Query()
+		approvalToken := query.Get(notifier.CallbackTokenParam)
+		query.Del(notifier.CallbackTokenParam)
+		if token != "" {
+			bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			want := notifier.ApprovalToken(token, query.Get("id"))
+			if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 &&
+				subtle.ConstantTimeCompare([]byte(approvalToken), []byte(want)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		uri := "cc-notify://respond?" + query.Encode()
+		_, decision, _, err := parseApprovalProtocolURI(uri)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.resolveApprovalURI(uri); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "response sent: %s\n", decision)
+	})
+}
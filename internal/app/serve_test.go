@@ -0,0 +1,101 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newPendingApprovalForServe(t *testing.T) (*App, *fakeApprovalExecutor, string) {
+	t.Helper()
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+
+	var stdout, stderr bytes.Buffer
+	actionNotifier := &fakeActionNotifier{}
+	executor := &fakeApprovalExecutor{}
+	tool := New(Options{
+		Notifier:         actionNotifier,
+		Stdout:           &stdout,
+		Stderr:           &stderr,
+		SettingsPath:     func() (string, error) { return settingsPath, nil },
+		ApprovalExecutor: executor,
+	})
+
+	code := tool.Run([]string{"notify", `{"type":"agent-turn-paused","summary":"need approval"}`})
+	if code != 0 {
+		t.Fatalf("notify paused failed: stderr=%q", stderr.String())
+	}
+	uri, err := url.Parse(actionNotifier.actions[0].URI)
+	if err != nil {
+		t.Fatalf("parse action uri: %v", err)
+	}
+	return tool, executor, uri.Query().Get("id")
+}
+
+func TestRespondHandler_DeliversDecision(t *testing.T) {
+	tool, executor, id := newPendingApprovalForServe(t)
+	server := httptest.NewServer(tool.respondHandler("s3cret"))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/respond?decision=proceed-always&id="+id, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post respond: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+	if len(executor.calls) != 1 || executor.calls[0].decision != approvalProceedAlways {
+		t.Fatalf("unexpected executor calls: %+v", executor.calls)
+	}
+
+	// The approval is consumed; a second tap must not deliver again.
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/respond?decision=proceed&id="+id, nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post respond: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || len(executor.calls) != 1 {
+		t.Fatalf("expected replay to be rejected, status=%d calls=%d", resp.StatusCode, len(executor.calls))
+	}
+}
+
+func TestRespondHandler_RejectsBadRequests(t *testing.T) {
+	tool, executor, id := newPendingApprovalForServe(t)
+	handler := tool.respondHandler("s3cret")
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		auth   string
+		status int
+	}{
+		{name: "wrong token", method: http.MethodPost, target: "/respond?decision=proceed&id=" + id, auth: "Bearer nope", status: http.StatusUnauthorized},
+		{name: "missing token", method: http.MethodPost, target: "/respond?decision=proceed&id=" + id, status: http.StatusUnauthorized},
+		{name: "get", method: http.MethodGet, target: "/respond?decision=proceed&id=" + id, auth: "Bearer s3cret", status: http.StatusMethodNotAllowed},
+		{name: "bad decision", method: http.MethodPost, target: "/respond?decision=maybe&id=" + id, auth: "Bearer s3cret", status: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(""))
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: status = %d, want %d", tc.name, rec.Code, tc.status)
+		}
+	}
+	if len(executor.calls) != 0 {
+		t.Fatalf("no decision should be delivered, got %+v", executor.calls)
+	}
+}
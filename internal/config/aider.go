@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AiderDefaultPath returns the Aider config path in the user's home
+// directory. Aider also reads .aider.conf.yml from the git root and the
+// current directory, which take precedence.
+func AiderDefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve user home: %w", err)
+	}
+	return filepath.Join(home, ".aider.conf.yml"), nil
+}
+
+const (
+	aiderNotificationsKey = "notifications"
+	aiderCommandKey       = "notifications-command"
+)
+
+// AiderUpsertNotifications turns on Aider notifications and points
+// notifications-command at cc-notify, replacing a command already set.
+func AiderUpsertNotifications(content string, exePath string) (string, bool, error) {
+	bom, content := stripBOM(content)
+	newline := detectNewline(content)
+	lines := splitLines(content)
+
+	wanted := []struct{ key, line string }{
+		{aiderNotificationsKey, aiderNotificationsKey + ": true"},
+		{aiderCommandKey, aiderCommandKey + ": " + quoteYAMLString(buildAiderCommand(exePath))},
+	}
+	changed := false
+	for _, w := range wanted {
+		start, end, found := findYAMLKey(lines, w.key)
+		if !found {
+			lines = append(lines, w.line)
+			changed = true
+			continue
+		}
+		if end-start == 1 && strings.TrimSpace(lines[start]) == w.line {
+			continue
+		}
+		lines = append(lines[:start], append([]string{w.line}, lines[end:]...)...)
+		changed = true
+	}
+	if !changed {
+		return bom + content, false, nil
+	}
+	return bom + joinLines(lines, newline), true, nil
+}
+
+// AiderRemoveNotifications removes the cc-notify notifications-command and
+// the notifications switch installed with it. A command that is not ours is
+// left alone.
+func AiderRemoveNotifications(content string) (string, bool, error) {
+	bom, content := stripBOM(content)
+	newline := detectNewline(content)
+	lines := splitLines(content)
+
+	start, end, found := findYAMLKey(lines, aiderCommandKey)
+	if !found || !strings.Contains(strings.Join(lines[start:end], "\n"), hookMarker) {
+		return bom + content, false, nil
+	}
+	lines = append(lines[:start], lines[end:]...)
+	if start, end, found := findYAMLKey(lines, aiderNotificationsKey); found {
+		lines = append(lines[:start], lines[end:]...)
+	}
+	return bom + joinLines(lines, newline), true, nil
+}
+
+// buildAiderCommand returns the shell command Aider runs when it waits for
+// input. The path is quoted because Aider hands the command to a shell.
+func buildAiderCommand(exePath string) string {
+	if strings.ContainsAny(exePath, " \t") {
+		exePath = `"` + exePath + `"`
+	}
+	return fmt.Sprintf("%s notify --aider", exePath)
+}
+
+// findYAMLKey finds the top-level key in lines. The entry ends before the
+// next line that is not indented, so block values are included.
+func findYAMLKey(lines []string, key string) (start int, end int, found bool) {
+	for i, line := range lines {
+		name, _, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimRight(name, " \t") != key {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && (strings.HasPrefix(lines[end], " ") || strings.HasPrefix(lines[end], "\t")) {
+			end++
+		}
+		return i, end, true
+	}
+	return 0, 0, false
+}
+
+// quoteYAMLString quotes value as a single-quoted YAML scalar, in which
+// backslashes, common in Windows paths, need no escaping.
+func quoteYAMLString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestAiderUpsertNotifications(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing string
+		exe      string
+		want     string
+	}{
+		{
+			name: "empty",
+			exe:  "/usr/local/bin/cc-notify",
+			want: "notifications: true\nnotifications-command: '/usr/local/bin/cc-notify notify --aider'\n",
+		},
+		{
+			name:     "replaces existing values",
+			existing: "model: sonnet\r\nnotifications: false\r\nnotifications-command: >\r\n  say done\r\ndark-mode: true\r\n",
+			exe:      `C:\Program Files\cc-notify\cc-notify.exe`,
+			want:     "model: sonnet\r\nnotifications: true\r\nnotifications-command: '\"C:\\Program Files\\cc-notify\\cc-notify.exe\" notify --aider'\r\ndark-mode: true\r\n",
+		},
+	}
+	for _, tc := range cases {
+		got, changed, err := AiderUpsertNotifications(tc.existing, tc.exe)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !changed || got != tc.want {
+			t.Fatalf("%s: got changed=%v\n%q\nwant\n%q", tc.name, changed, got, tc.want)
+		}
+		again, changed, err := AiderUpsertNotifications(got, tc.exe)
+		if err != nil || changed || again != got {
+			t.Fatalf("%s: expected second upsert to be a no-op, changed=%v err=%v", tc.name, changed, err)
+		}
+	}
+}
+
+func TestAiderRemoveNotifications(t *testing.T) {
+	installed := "model: sonnet\nnotifications: true\nnotifications-command: '/usr/local/bin/cc-notify notify --aider'\n"
+	got, changed, err := AiderRemoveNotifications(installed)
+	if err != nil || !changed || got != "model: sonnet\n" {
+		t.Fatalf("unexpected result: changed=%v err=%v %q", changed, err, got)
+	}
+
+	own := "notifications: true\nnotifications-command: say done\n"
+	got, changed, err = AiderRemoveNotifications(own)
+	if err != nil || changed || got != own {
+		t.Fatalf("expected user's own command kept: changed=%v err=%v %q", changed, err, got)
+	}
+}
@@ -175,3 +175,74 @@ func TestClaudeRemoveHook_RemovesNotificationHook(t *testing.T) {
 		t.Fatalf("expected Notification hook to be removed: %q", out)
 	}
 }
+
+func TestClaudeUpsertHook_InstallsSelectedEvents(t *testing.T) {
+	existing := `{
+  "hooks": {
+    "Notification": [
+      {"matcher": "", "hooks": [{"type": "command", "command": "C:\\tools\\cc-notify.exe notify --claude"}]}
+    ],
+    "PreToolUse": [
+      {"matcher": "Bash", "hooks": [{"type": "command", "command": "audit.sh"}]}
+    ]
+  }
+}`
+	out, changed, err := ClaudeUpsertHook(existing, `C:\tools\cc-notify.exe`, "stop", "SubagentStop", "PreToolUse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected changed")
+	}
+	var parsed struct {
+		Hooks map[string][]hookMatcher `json:"hooks"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	for _, name := range []string{"Stop", "SubagentStop"} {
+		if !containsOurHook(parsed.Hooks[name]) {
+			t.Fatalf("expected %s hook: %s", name, out)
+		}
+	}
+	if _, ok := parsed.Hooks["Notification"]; ok {
+		t.Fatalf("expected disabled Notification hook to be removed: %s", out)
+	}
+	pre := parsed.Hooks["PreToolUse"]
+	if len(pre) != 2 || pre[0].Hooks[0].Command != "audit.sh" || !containsOurHook(pre[1:]) {
+		t.Fatalf("expected PreToolUse hook next to the user's own: %s", out)
+	}
+
+	removed, changed, err := ClaudeRemoveHook(out)
+	if err != nil || !changed {
+		t.Fatalf("expected remove to change settings, err=%v", err)
+	}
+	if strings.Contains(removed, "cc-notify") || !strings.Contains(removed, "audit.sh") {
+		t.Fatalf("expected only our hooks removed: %s", removed)
+	}
+}
+
+func TestClaudeUpsertHookWithTimeout_ApprovalHooks(t *testing.T) {
+	out, _, err := ClaudeUpsertHookWithTimeout("", `C:\tools\cc-notify.exe`, 330, "Stop", "PermissionRequest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var parsed struct {
+		Hooks map[string][]hookMatcher `json:"hooks"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if got := parsed.Hooks["PermissionRequest"]; len(got) != 1 || got[0].Hooks[0].Timeout != 330 {
+		t.Fatalf("expected the approval hook to wait 330s: %s", out)
+	}
+	if got := parsed.Hooks["Stop"]; len(got) != 1 || got[0].Hooks[0].Timeout != 0 {
+		t.Fatalf("expected the Stop hook to keep the default timeout: %s", out)
+	}
+}
+
+func TestClaudeUpsertHook_RejectsUnknownEvents(t *testing.T) {
+	if _, _, err := ClaudeUpsertHook("", `C:\tools\cc-notify.exe`, "Stop", "Teleport"); err == nil || !strings.Contains(err.Error(), "Teleport") {
+		t.Fatalf("expected unknown event error, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cc-notify/internal/event"
+)
+
+// GeminiDefaultPath returns the Gemini CLI user settings path.
+func GeminiDefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve user home: %w", err)
+	}
+	return filepath.Join(home, ".gemini", "settings.json"), nil
+}
+
+// GeminiUpsertHook inserts or updates the cc-notify hook in Gemini CLI
+// settings for each of events, by default AfterAgent and Notification, and
+// removes it from the events left out.
+func GeminiUpsertHook(content string, exePath string, events ...string) (string, bool, error) {
+	if len(events) == 0 {
+		events = event.DefaultGeminiHookEvents
+	}
+	enabled, unknown := event.NormalizeGeminiHookEvents(events)
+	if len(unknown) > 0 {
+		return "", false, fmt.Errorf("unsupported gemini hook events: %s", strings.Join(unknown, ", "))
+	}
+	return upsertHooks("gemini", content, buildHookCommand(exePath, "gemini"), event.GeminiHookEvents, enabled, nil)
+}
+
+// GeminiRemoveHook removes the cc-notify hook from Gemini CLI settings.
+func GeminiRemoveHook(content string) (string, bool, error) {
+	return removeHooks("gemini", content, event.GeminiHookEvents)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGeminiUpsertHook_DefaultEvents(t *testing.T) {
+	existing := `{
+  "theme": "GitHub",
+  "hooks": {
+    "BeforeTool": [
+      {"matcher": "run_shell_command", "hooks": [{"type": "command", "command": "audit.sh"}]}
+    ]
+  }
+}`
+	out, changed, err := GeminiUpsertHook(existing, `/usr/local/bin/cc-notify`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed {
+		t.Fatal("expected changed")
+	}
+	var parsed struct {
+		Theme string                   `json:"theme"`
+		Hooks map[string][]hookMatcher `json:"hooks"`
+	}
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if parsed.Theme != "GitHub" {
+		t.Fatalf("expected other settings kept: %s", out)
+	}
+	for _, name := range []string{"AfterAgent", "Notification"} {
+		matchers := parsed.Hooks[name]
+		if len(matchers) != 1 || matchers[0].Hooks[0].Command != "/usr/local/bin/cc-notify notify --gemini" {
+			t.Fatalf("expected %s hook: %s", name, out)
+		}
+	}
+	if len(parsed.Hooks["BeforeTool"]) != 1 || containsOurHook(parsed.Hooks["BeforeTool"]) {
+		t.Fatalf("expected user's BeforeTool hook untouched: %s", out)
+	}
+
+	removed, changed, err := GeminiRemoveHook(out)
+	if err != nil || !changed {
+		t.Fatalf("expected remove to change settings, err=%v", err)
+	}
+	if strings.Contains(removed, "cc-notify") || !strings.Contains(removed, "audit.sh") {
+		t.Fatalf("expected only our hooks removed: %s", removed)
+	}
+}
+
+func TestGeminiUpsertHook_RejectsUnknownEvents(t *testing.T) {
+	if _, _, err := GeminiUpsertHook("", `/usr/local/bin/cc-notify`, "AfterAgent", "Stop"); err == nil || !strings.Contains(err.Error(), "Stop") {
+		t.Fatalf("expected unknown event error, got %v", err)
+	}
+	if _, _, err := GeminiUpsertHook("{", `/usr/local/bin/cc-notify`); err == nil || !strings.Contains(err.Error(), "parse gemini settings") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// hookSettings is a settings.json file with a "hooks" object in the format
+// Claude Code introduced and Gemini CLI adopted: event name to matcher
+// groups of commands.
+type hookSettings struct {
+	raw map[string]json.RawMessage
+}
+
+// hookEntry represents a single hook command entry.
+type hookEntry struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	// Timeout is in seconds; zero leaves the agent's default.
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// hookMatcher represents a matcher group containing hooks.
+type hookMatcher struct {
+	Matcher string      `json:"matcher"`
+	Hooks   []hookEntry `json:"hooks"`
+}
+
+const hookMarker = "cc-notify"
+
+func parseHookSettings(tool, content string) (hookSettings, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return hookSettings{raw: make(map[string]json.RawMessage)}, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return hookSettings{}, fmt.Errorf("parse %s settings: %w", tool, err)
+	}
+	if raw == nil {
+		raw = make(map[string]json.RawMessage)
+	}
+	return hookSettings{raw: raw}, nil
+}
+
+func (s hookSettings) serialize() (string, error) {
+	data, err := json.MarshalIndent(s.raw, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("serialize settings: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+func (s hookSettings) getHooks() (map[string]json.RawMessage, error) {
+	hooksRaw, ok := s.raw["hooks"]
+	if !ok {
+		return make(map[string]json.RawMessage), nil
+	}
+	var hooks map[string]json.RawMessage
+	if err := json.Unmarshal(hooksRaw, &hooks); err != nil {
+		return nil, fmt.Errorf("parse hooks: %w", err)
+	}
+	return hooks, nil
+}
+
+func (s *hookSettings) setHooks(hooks map[string]json.RawMessage) error {
+	data, err := json.Marshal(hooks)
+	if err != nil {
+		return fmt.Errorf("marshal hooks: %w", err)
+	}
+	s.raw["hooks"] = json.RawMessage(data)
+	return nil
+}
+
+func getMatcherList(hooks map[string]json.RawMessage, event string) ([]hookMatcher, error) {
+	raw, ok := hooks[event]
+	if !ok {
+		return nil, nil
+	}
+	var matchers []hookMatcher
+	if err := json.Unmarshal(raw, &matchers); err != nil {
+		return nil, fmt.Errorf("parse hook event %s: %w", event, err)
+	}
+	return matchers, nil
+}
+
+func setMatcherList(hooks map[string]json.RawMessage, event string, matchers []hookMatcher) error {
+	data, err := json.Marshal(matchers)
+	if err != nil {
+		return fmt.Errorf("marshal hook event %s: %w", event, err)
+	}
+	hooks[event] = json.RawMessage(data)
+	return nil
+}
+
+func containsOurHook(matchers []hookMatcher) bool {
+	for _, m := range matchers {
+		for _, h := range m.Hooks {
+			if strings.Contains(h.Command, hookMarker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func removeOurHook(matchers []hookMatcher) ([]hookMatcher, bool) {
+	changed := false
+	var result []hookMatcher
+	for _, m := range matchers {
+		var filtered []hookEntry
+		for _, h := range m.Hooks {
+			if strings.Contains(h.Command, hookMarker) {
+				changed = true
+			} else {
+				filtered = append(filtered, h)
+			}
+		}
+		if len(filtered) > 0 {
+			m.Hooks = filtered
+			result = append(result, m)
+		} else if len(m.Hooks) > 0 {
+			// All hooks were ours, drop the entire matcher group
+		} else {
+			result = append(result, m)
+		}
+	}
+	return result, changed
+}
+
+// buildHookCommand returns the command a hook runs: notify with the flag
+// naming the agent, which passes hook input on stdin as JSON.
+func buildHookCommand(exePath, flag string) string {
+	return fmt.Sprintf("%s notify --%s", exePath, flag)
+}
+
+// upsertHooks installs cmd for each of events and removes it from the other
+// known events of tool. timeouts sets the timeout of the hooks of some
+// events, in seconds.
+func upsertHooks(tool, content, cmd string, known, events []string, timeouts map[string]int) (string, bool, error) {
+	settings, err := parseHookSettings(tool, content)
+	if err != nil {
+		return "", false, err
+	}
+
+	hooks, err := settings.getHooks()
+	if err != nil {
+		return "", false, err
+	}
+
+	wanted := make(map[string]bool, len(events))
+	for _, name := range events {
+		wanted[name] = true
+	}
+
+	anyChanged := false
+
+	for _, name := range known {
+		matchers, err := getMatcherList(hooks, name)
+		if err != nil {
+			return "", false, err
+		}
+
+		// Remove existing to avoid duplicates
+		matchers, removed := removeOurHook(matchers)
+		if removed {
+			anyChanged = true
+		}
+
+		if !wanted[name] {
+			if removed {
+				if len(matchers) == 0 {
+					delete(hooks, name)
+				} else if err := setMatcherList(hooks, name, matchers); err != nil {
+					return "", false, err
+				}
+			}
+			continue
+		}
+
+		newMatcher := hookMatcher{
+			Matcher: "",
+			Hooks: []hookEntry{
+				{Type: "command", Command: cmd, Timeout: timeouts[name]},
+			},
+		}
+		matchers = append(matchers, newMatcher)
+		anyChanged = true
+
+		if err := setMatcherList(hooks, name, matchers); err != nil {
+			return "", false, err
+		}
+	}
+
+	if err := settings.setHooks(hooks); err != nil {
+		return "", false, err
+	}
+
+	result, err := settings.serialize()
+	if err != nil {
+		return "", false, err
+	}
+	return result, anyChanged, nil
+}
+
+// removeHooks removes the cc-notify hook from the known events of tool.
+func removeHooks(tool, content string, known []string) (string, bool, error) {
+	settings, err := parseHookSettings(tool, content)
+	if err != nil {
+		return "", false, err
+	}
+
+	hooks, err := settings.getHooks()
+	if err != nil {
+		return "", false, err
+	}
+
+	anyChanged := false
+	for _, name := range known {
+		matchers, err := getMatcherList(hooks, name)
+		if err != nil {
+			return "", false, err
+		}
+
+		matchers, removed := removeOurHook(matchers)
+		if removed {
+			anyChanged = true
+			if len(matchers) == 0 {
+				delete(hooks, name)
+			} else {
+				if err := setMatcherList(hooks, name, matchers); err != nil {
+					return "", false, err
+				}
+			}
+		}
+	}
+
+	if !anyChanged {
+		return content, false, nil
+	}
+
+	if len(hooks) == 0 {
+		delete(settings.raw, "hooks")
+	} else {
+		if err := settings.setHooks(hooks); err != nil {
+			return "", false, err
+		}
+	}
+
+	result, err := settings.serialize()
+	if err != nil {
+		return "", false, err
+	}
+	return result, true, nil
+}
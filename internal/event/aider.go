@@ -0,0 +1,81 @@
+package event
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// AiderHistoryFile is the chat history Aider writes in the directory it
+// runs in, unless --chat-history-file says otherwise.
+const AiderHistoryFile = ".aider.chat.history.md"
+
+// AiderHistory holds what cc-notify takes from an Aider chat history about
+// its latest exchange.
+type AiderHistory struct {
+	// LastAssistantMessage is the reply to LastUserPrompt.
+	LastAssistantMessage string
+	// LastUserPrompt is the last prompt the user typed.
+	LastUserPrompt string
+}
+
+// ReadAiderHistory reads the Aider chat history at path from the end. Aider
+// writes prompts as "#### " lines and its own output, such as applied edits
+// and commits, as "> " quotes; the remaining lines after the last prompt are
+// the reply.
+func ReadAiderHistory(path string) (AiderHistory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return AiderHistory{}, fmt.Errorf("read aider chat history: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return AiderHistory{}, fmt.Errorf("read aider chat history: %w", err)
+	}
+	history, err := readAiderHistory(f, info.Size())
+	if err != nil {
+		return AiderHistory{}, fmt.Errorf("read aider chat history: %w", err)
+	}
+	return history, nil
+}
+
+func readAiderHistory(r io.ReaderAt, size int64) (AiderHistory, error) {
+	// Both are collected last line first.
+	var reply, prompt []string
+	err := scanLinesBackward(r, size, func(raw []byte) bool {
+		line := strings.TrimRight(string(raw), "\r")
+		if text, ok := strings.CutPrefix(line, "####"); ok && (text == "" || text[0] == ' ') {
+			prompt = append(prompt, strings.TrimSpace(text))
+			return true
+		}
+		if len(prompt) > 0 || strings.HasPrefix(line, "# aider chat started at") {
+			return false
+		}
+		if !strings.HasPrefix(line, ">") {
+			reply = append(reply, line)
+		}
+		return true
+	})
+	if err != nil {
+		return AiderHistory{}, err
+	}
+	if len(prompt) == 0 {
+		// Nothing was asked in this session yet.
+		return AiderHistory{}, nil
+	}
+	return AiderHistory{
+		LastAssistantMessage: joinReversed(reply),
+		LastUserPrompt:       joinReversed(prompt),
+	}, nil
+}
+
+// joinReversed joins lines collected last first back into text.
+func joinReversed(lines []string) string {
+	ordered := make([]string, len(lines))
+	for i, line := range lines {
+		ordered[len(lines)-1-i] = line
+	}
+	return strings.TrimSpace(strings.Join(ordered, "\n"))
+}
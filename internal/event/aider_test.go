@@ -0,0 +1,50 @@
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadAiderHistory_LastExchange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), AiderHistoryFile)
+	content := "\n# aider chat started at 2025-05-01 10:00:00\n\n" +
+		"> /usr/bin/aider --model sonnet\n> Aider v0.82.0\n\n" +
+		"#### add a README\n\nAdded README.md.\n\n> Applied edit to README.md\n\n" +
+		"#### fix the flaky test\n#### in cache_test.go\n\n" +
+		"The test raced on the shared map.\n\n```go\nmu.Lock()\n```\n\n" +
+		"> Applied edit to cache_test.go\r\n> Commit 1a2b3c4 fix: lock the cache map\r\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+
+	got, err := ReadAiderHistory(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := AiderHistory{
+		LastAssistantMessage: "The test raced on the shared map.\n\n```go\nmu.Lock()\n```",
+		LastUserPrompt:       "fix the flaky test\nin cache_test.go",
+	}
+	if got != want {
+		t.Fatalf("unexpected history:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadAiderHistory_NewSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), AiderHistoryFile)
+	content := "#### an older prompt\n\nan older answer\n\n# aider chat started at 2025-05-02 09:00:00\n\n> Aider v0.82.0\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+	got, err := ReadAiderHistory(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != (AiderHistory{}) {
+		t.Fatalf("expected nothing from a fresh session, got %+v", got)
+	}
+	if _, err := ReadAiderHistory(filepath.Join(t.TempDir(), "missing.md")); err == nil {
+		t.Fatalf("expected error for missing history")
+	}
+}
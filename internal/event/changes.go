@@ -0,0 +1,300 @@
+package event
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ChangeStats counts what changed in a working tree during a turn.
+type ChangeStats struct {
+	// Files counts the files created, edited or deleted.
+	Files int
+	// Added and Deleted count changed lines; files without line counts,
+	// such as binary ones, only count as files.
+	Added   int
+	Deleted int
+}
+
+// TreeSnapshot records the files of a working tree so that the changes
+// made since can be counted later. Files are kept as line hashes rather
+// than content, so a snapshot holds no source code.
+type TreeSnapshot struct {
+	// Root is the working tree root.
+	Root  string                  `json:"root"`
+	Files map[string]snapshotFile `json:"files"`
+}
+
+// snapshotFile records one file by its slash-separated path below Root.
+type snapshotFile struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"`
+	// Sum is a hash of the content, or of size and modification time for
+	// files that were not read, see readSnapshotFile.
+	Sum uint64 `json:"sum"`
+	// Lines holds a 32-bit hash of every line, big-endian. It is empty
+	// for binary files and those above maxSnapshotFileSize, whose lines
+	// are not counted.
+	Lines []byte `json:"lines,omitempty"`
+}
+
+// hasLines reports whether the lines of f were recorded. Only an empty
+// file, or the zero record of a missing one, has no lines legitimately.
+func (f snapshotFile) hasLines() bool {
+	return len(f.Lines) > 0 || f.Size == 0
+}
+
+// maxSnapshotFileSize is the largest file that is read and whose lines are
+// counted.
+const maxSnapshotFileSize = 1 << 20
+
+// maxSnapshotRead bounds the bytes read by one snapshot or comparison.
+// Once it is used up, further files are only compared by size and
+// modification time.
+const maxSnapshotRead = 64 << 20
+
+// maxSnapshotFiles is the largest tree that is tracked at all.
+const maxSnapshotFiles = 50000
+
+// maxDiffCells bounds the work of counting the lines changed in one file;
+// beyond it the count is estimated from the lines each version lacks.
+const maxDiffCells = 25_000_000
+
+// SnapshotTree records the files of the repository containing dir: the
+// tracked ones and those git would list as untracked. It returns nil
+// outside a repository, where there is no telling the project apart from
+// what it ignores, and for trees above maxSnapshotFiles.
+func SnapshotTree(dir string) (*TreeSnapshot, error) {
+	repo, ok := openGitRepo(dir)
+	if !ok {
+		return nil, nil
+	}
+	files, err := repo.listFiles()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", repo.root, err)
+	}
+	if len(files) > maxSnapshotFiles {
+		return nil, nil
+	}
+	snap := &TreeSnapshot{Root: repo.root, Files: make(map[string]snapshotFile, len(files))}
+	budget := int64(maxSnapshotRead)
+	for _, rel := range files {
+		if f, ok := readSnapshotFile(filepath.Join(repo.root, filepath.FromSlash(rel)), nil, &budget); ok {
+			snap.Files[rel] = f
+		}
+	}
+	return snap, nil
+}
+
+// Changes compares the working tree with the snapshot. Files whose size
+// and modification time are unchanged are not read again.
+func (s *TreeSnapshot) Changes() (ChangeStats, error) {
+	repo, ok := openGitRepo(s.Root)
+	if !ok {
+		return ChangeStats{}, fmt.Errorf("compare %s: not a git repository", s.Root)
+	}
+	files, err := repo.listFiles()
+	if err != nil {
+		return ChangeStats{}, fmt.Errorf("compare %s: %w", s.Root, err)
+	}
+	var stats ChangeStats
+	seen := make(map[string]bool, len(files))
+	budget := int64(maxSnapshotRead)
+	for _, rel := range files {
+		seen[rel] = true
+		old, existed := s.Files[rel]
+		var prev *snapshotFile
+		if existed {
+			prev = &old
+		}
+		cur, ok := readSnapshotFile(filepath.Join(s.Root, filepath.FromSlash(rel)), prev, &budget)
+		switch {
+		case !ok && !existed:
+		case !ok:
+			stats.add(old, snapshotFile{})
+		case !existed:
+			stats.add(snapshotFile{}, cur)
+		case cur.Sum != old.Sum || cur.Size != old.Size:
+			stats.add(old, cur)
+		}
+	}
+	for rel, old := range s.Files {
+		if !seen[rel] {
+			stats.add(old, snapshotFile{})
+		}
+	}
+	return stats, nil
+}
+
+// add counts the change of a file from old to cur, either of which is
+// zero for a created or deleted file. When one side's lines were not
+// recorded, say a file that grew past maxSnapshotFileSize, only the file
+// is counted: diffing against nothing would report every line as changed.
+func (c *ChangeStats) add(old, cur snapshotFile) {
+	c.Files++
+	if !old.hasLines() || !cur.hasLines() {
+		return
+	}
+	added, deleted := lineDiff(lineHashes(old.Lines), lineHashes(cur.Lines))
+	c.Added += added
+	c.Deleted += deleted
+}
+
+// listFiles returns the tracked files of the working tree that git
+// compares with it, and the untracked ones, sorted.
+func (r gitRepo) listFiles() ([]string, error) {
+	entries, _, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if e.mode&modeTypeMask != modeGitlink {
+			files = append(files, e.path)
+		}
+	}
+	r.scanUntracked(entries, func(rel string) { files = append(files, rel) })
+	sort.Strings(files)
+	return files, nil
+}
+
+// readSnapshotFile records the file at name. A prev record with the same
+// size and modification time is returned as is; ok is false when the file
+// does not exist or cannot be read. Files above maxSnapshotFileSize, and
+// all files once budget bytes were read, are not read: their Sum hashes
+// size and modification time, so touching them counts as a change.
+func readSnapshotFile(name string, prev *snapshotFile, budget *int64) (snapshotFile, bool) {
+	st, err := os.Lstat(name)
+	if err != nil || st.IsDir() {
+		return snapshotFile{}, false
+	}
+	f := snapshotFile{Size: st.Size(), ModTime: st.ModTime().UnixNano()}
+	if prev != nil && prev.Size == f.Size && prev.ModTime == f.ModTime {
+		return *prev, true
+	}
+	if st.Mode().IsRegular() && (f.Size > maxSnapshotFileSize || f.Size > *budget) {
+		sum := fnv.New64a()
+		binary.Write(sum, binary.BigEndian, [2]int64{f.Size, f.ModTime})
+		f.Sum = sum.Sum64()
+		return f, true
+	}
+	*budget -= f.Size
+
+	var content []byte
+	if st.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(name)
+		if err != nil {
+			return snapshotFile{}, false
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else if content, err = os.ReadFile(name); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return snapshotFile{}, false
+		}
+		// A file that cannot be read counts as changed, without lines.
+		return f, true
+	}
+	sum := fnv.New64a()
+	sum.Write(content)
+	f.Sum = sum.Sum64()
+	if !isBinary(content) {
+		f.Lines = hashLines(content)
+	}
+	return f, true
+}
+
+// isBinary reports whether content looks binary the way git decides it:
+// a NUL byte among the first 8000 bytes.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0
+}
+
+// hashLines returns the 32-bit hash of every line of content, ignoring
+// CR before LF.
+func hashLines(content []byte) []byte {
+	var out []byte
+	for len(content) > 0 {
+		line := content
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			line, content = content[:i], content[i+1:]
+		} else {
+			content = nil
+		}
+		h := fnv.New32a()
+		h.Write(bytes.TrimSuffix(line, []byte("\r")))
+		out = binary.BigEndian.AppendUint32(out, h.Sum32())
+	}
+	return out
+}
+
+func lineHashes(lines []byte) []uint32 {
+	out := make([]uint32, len(lines)/4)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint32(lines[4*i:])
+	}
+	return out
+}
+
+// lineDiff counts the lines b adds to and deletes from a, like the
+// numstat of git diff, from their longest common subsequence.
+func lineDiff(a, b []uint32) (added, deleted int) {
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return len(b), len(a)
+	}
+
+	common := 0
+	if len(a)*len(b) <= maxDiffCells {
+		prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+		for i := range a {
+			for j := range b {
+				switch {
+				case a[i] == b[j]:
+					cur[j+1] = prev[j] + 1
+				case prev[j+1] >= cur[j]:
+					cur[j+1] = prev[j+1]
+				default:
+					cur[j+1] = cur[j]
+				}
+			}
+			prev, cur = cur, prev
+		}
+		common = prev[len(b)]
+	} else {
+		counts := make(map[uint32]int, len(a))
+		for _, h := range a {
+			counts[h]++
+		}
+		for _, h := range b {
+			if counts[h] > 0 {
+				counts[h]--
+				common++
+			}
+		}
+	}
+	return len(b) - common, len(a) - common
+}
+
+// changesLine renders stats, e.g. "Edited 6 files, +120/−34".
+func changesLine(stats *ChangeStats, opts RenderOptions) string {
+	if stats == nil || !opts.IncludeChanges {
+		return ""
+	}
+	lang := opts.Language
+	if stats.Files == 0 {
+		return lang.T("No files edited")
+	}
+	files := lang.Plural(stats.Files, "Edited 1 file", "Edited %d files")
+	return fmt.Sprintf("%s, +%d/−%d", files, stats.Added, stats.Deleted)
+}
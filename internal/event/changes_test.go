@@ -0,0 +1,150 @@
+package event
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTreeSnapshot_Changes(t *testing.T) {
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "*.log\n")
+	writeTestFile(t, filepath.Join(repo, "main.go"), "package main\n\nfunc main() {\n\tprintln(1)\n}\n")
+	writeTestFile(t, filepath.Join(repo, "old.txt"), "a\nb\nc\n")
+	writeTestFile(t, filepath.Join(repo, "same.txt"), "same\n")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	writeTestFile(t, filepath.Join(repo, "draft.md"), "draft\n")
+
+	snap, err := SnapshotTree(repo)
+	if err != nil || snap == nil {
+		t.Fatalf("snapshot: %+v, %v", snap, err)
+	}
+	// The snapshot is kept on disk between hooks.
+	raw, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(raw), "println") {
+		t.Fatalf("snapshot must not hold file content: %s", raw)
+	}
+	snap = &TreeSnapshot{}
+	if err := json.Unmarshal(raw, snap); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	// main.go: 1 line replaced, 2 added. old.txt deleted (3 lines),
+	// new.go created (3 lines), draft.md grown by 1 line. same.txt is
+	// rewritten unchanged and debug.log is ignored.
+	writeTestFile(t, filepath.Join(repo, "main.go"), "package main\n\nfunc main() {\n\tprintln(2)\n\tprintln(3)\n\tprintln(4)\n}\n")
+	if err := os.Remove(filepath.Join(repo, "old.txt")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeTestFile(t, filepath.Join(repo, "pkg", "new.go"), "package pkg\n\nvar x = 1")
+	writeTestFile(t, filepath.Join(repo, "draft.md"), "draft\nmore\n")
+	writeTestFile(t, filepath.Join(repo, "same.txt"), "same\n")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(repo, "same.txt"), later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	writeTestFile(t, filepath.Join(repo, "debug.log"), "noise\n")
+
+	got, err := snap.Changes()
+	if err != nil {
+		t.Fatalf("changes: %v", err)
+	}
+	if want := (ChangeStats{Files: 4, Added: 7, Deleted: 4}); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestTreeSnapshot_LargeFilesAreNotRead(t *testing.T) {
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	large := filepath.Join(repo, "data.bin")
+	writeTestFile(t, large, strings.Repeat("x\n", maxSnapshotFileSize))
+	writeTestFile(t, filepath.Join(repo, "a.txt"), "a\n")
+	writeTestFile(t, filepath.Join(repo, "b.txt"), "b\n")
+
+	snap, err := SnapshotTree(repo)
+	if err != nil || snap == nil {
+		t.Fatalf("snapshot: %+v, %v", snap, err)
+	}
+	if f := snap.Files["data.bin"]; len(f.Lines) != 0 || f.Sum == 0 {
+		t.Fatalf("expected the large file by size and time only, got %d line bytes, sum %x", len(f.Lines), f.Sum)
+	}
+	if got, _ := snap.Changes(); got.Files != 0 {
+		t.Fatalf("expected no changes, got %+v", got)
+	}
+
+	// Touching the large file is a change, without line counts.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(large, later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if got, _ := snap.Changes(); got != (ChangeStats{Files: 1}) {
+		t.Fatalf("expected the touched large file as a change, got %+v", got)
+	}
+
+	// A file that outgrew the limit since the snapshot has no lines to
+	// compare with, so it does not count as all of its old lines deleted.
+	writeTestFile(t, filepath.Join(repo, "b.txt"), strings.Repeat("b\n", maxSnapshotFileSize))
+	if got, _ := snap.Changes(); got != (ChangeStats{Files: 2}) {
+		t.Fatalf("expected b.txt as a change without lines, got %+v", got)
+	}
+
+	// Past the read budget files are not read either.
+	budget := int64(1)
+	f, ok := readSnapshotFile(filepath.Join(repo, "a.txt"), nil, &budget)
+	if !ok || len(f.Lines) != 0 || budget != 1 {
+		t.Fatalf("expected a.txt not to be read past the budget, got %+v (budget %d)", f, budget)
+	}
+}
+
+func TestSnapshotTree_OutsideRepository(t *testing.T) {
+	snap, err := SnapshotTree(t.TempDir())
+	if snap != nil || err != nil {
+		t.Fatalf("expected nothing outside a repository, got %+v, %v", snap, err)
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	lines := func(text string) []uint32 { return lineHashes(hashLines([]byte(text))) }
+	cases := []struct {
+		a, b           string
+		added, deleted int
+	}{
+		{a: "", b: "x\ny\n", added: 2},
+		{a: "x\ny\n", b: "", deleted: 2},
+		{a: "a\nb\nc\n", b: "a\nB\nc\n", added: 1, deleted: 1},
+		{a: "a\nb\nc\nd\n", b: "b\nc\nd\ne\n", added: 1, deleted: 1},
+		{a: "a\r\nb\r\n", b: "a\nb\n"},
+	}
+	for _, tc := range cases {
+		added, deleted := lineDiff(lines(tc.a), lines(tc.b))
+		if added != tc.added || deleted != tc.deleted {
+			t.Fatalf("lineDiff(%q, %q) = +%d/-%d, want +%d/-%d", tc.a, tc.b, added, deleted, tc.added, tc.deleted)
+		}
+	}
+}
+
+func TestRenderNotificationWithOptions_ChangesLine(t *testing.T) {
+	payload := Payload{
+		Type:    "agent-turn-complete",
+		Summary: "done",
+		Changes: &ChangeStats{Files: 6, Added: 120, Deleted: 34},
+		Stats:   &TurnStats{Duration: 90 * time.Second},
+	}
+	opts := RenderOptions{IncludeChanges: true, IncludeDuration: true}
+	if _, body, _ := RenderNotificationWithOptions(payload, opts); body != "done\nEdited 6 files, +120/−34\nDone in 1m30s" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+	payload.Changes = &ChangeStats{}
+	if _, body, _ := RenderNotificationWithOptions(payload, RenderOptions{IncludeChanges: true}); body != "done\nNo files edited" {
+		t.Fatalf("unexpected body without changes: %q", body)
+	}
+}
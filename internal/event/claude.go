@@ -0,0 +1,409 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Claude Code hook event names, as sent in hook_event_name and used as keys
+// under "hooks" in Claude Code settings.
+const (
+	ClaudeStop             = "Stop"
+	ClaudeSubagentStop     = "SubagentStop"
+	ClaudeNotification     = "Notification"
+	ClaudePreCompact       = "PreCompact"
+	ClaudeSessionStart     = "SessionStart"
+	ClaudeSessionEnd       = "SessionEnd"
+	ClaudeUserPromptSubmit = "UserPromptSubmit"
+	ClaudePreToolUse       = "PreToolUse"
+	ClaudePostToolUse      = "PostToolUse"
+	// ClaudePermissionRequest is sent when Claude Code is about to ask for
+	// permission; the hook can answer in its place.
+	ClaudePermissionRequest = "PermissionRequest"
+)
+
+// ClaudeHookEvents lists every Claude Code hook event cc-notify understands.
+var ClaudeHookEvents = []string{
+	ClaudeStop,
+	ClaudeSubagentStop,
+	ClaudeNotification,
+	ClaudePreCompact,
+	ClaudeSessionStart,
+	ClaudeSessionEnd,
+	ClaudeUserPromptSubmit,
+	ClaudePreToolUse,
+	ClaudePostToolUse,
+	ClaudePermissionRequest,
+}
+
+// DefaultClaudeHookEvents are the events installed when the user has not
+// chosen any.
+var DefaultClaudeHookEvents = []string{ClaudeStop, ClaudeNotification}
+
+// Claude Notification subtypes, sent in notification_type.
+const (
+	ClaudeNotificationPermission  = "permission_prompt"
+	ClaudeNotificationIdle        = "idle_prompt"
+	ClaudeNotificationAuthSuccess = "auth_success"
+	ClaudeNotificationElicitation = "elicitation_dialog"
+)
+
+// cc-notify event types produced from Claude hooks, besides the shared
+// agent-turn-complete and agent-turn-paused.
+const (
+	TypeSubagentComplete  = "subagent-complete"
+	TypeAgentIdle         = "agent-idle"
+	TypeAgentNotification = "agent-notification"
+	TypeContextCompact    = "context-compact"
+	TypeSessionStart      = "session-start"
+	TypeSessionEnd        = "session-end"
+	TypePromptSubmit      = "prompt-submit"
+	TypePreToolUse        = "pre-tool-use"
+	TypePostToolUse       = "post-tool-use"
+)
+
+// ClaudeHookCommon holds the fields every Claude Code hook receives.
+type ClaudeHookCommon struct {
+	HookEventName  string `json:"hook_event_name"`
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	CWD            string `json:"cwd"`
+	PermissionMode string `json:"permission_mode,omitempty"`
+	// Model is not part of the hook contract but is passed through when a
+	// wrapper adds it.
+	Model string `json:"model,omitempty"`
+}
+
+// ClaudeHook is a parsed Claude Code hook input. Payload converts it into
+// the cc-notify event it announces.
+type ClaudeHook interface {
+	Common() ClaudeHookCommon
+	Payload() Payload
+}
+
+// ClaudeStopHook is sent when the main agent or a subagent finishes.
+type ClaudeStopHook struct {
+	ClaudeHookCommon
+	StopHookActive bool `json:"stop_hook_active"`
+}
+
+// ClaudeNotificationHook is sent when Claude Code shows a notification.
+type ClaudeNotificationHook struct {
+	ClaudeHookCommon
+	Message          string `json:"message"`
+	Title            string `json:"title,omitempty"`
+	NotificationType string `json:"notification_type,omitempty"`
+}
+
+// ClaudePreCompactHook is sent before the conversation is compacted.
+type ClaudePreCompactHook struct {
+	ClaudeHookCommon
+	Trigger            string `json:"trigger"`
+	CustomInstructions string `json:"custom_instructions"`
+}
+
+// ClaudeSessionStartHook is sent when a session starts or resumes.
+type ClaudeSessionStartHook struct {
+	ClaudeHookCommon
+	Source string `json:"source"`
+}
+
+// ClaudeSessionEndHook is sent when a session ends.
+type ClaudeSessionEndHook struct {
+	ClaudeHookCommon
+	Reason string `json:"reason"`
+}
+
+// ClaudeUserPromptSubmitHook is sent when the user submits a prompt.
+type ClaudeUserPromptSubmitHook struct {
+	ClaudeHookCommon
+	Prompt string `json:"prompt"`
+}
+
+// ClaudeToolHook is sent before (PreToolUse) and after (PostToolUse) a tool
+// call, and before Claude Code asks for permission to make one
+// (PermissionRequest). ToolResponse is only set after the call.
+type ClaudeToolHook struct {
+	ClaudeHookCommon
+	ToolName     string          `json:"tool_name"`
+	ToolInput    json.RawMessage `json:"tool_input,omitempty"`
+	ToolResponse json.RawMessage `json:"tool_response,omitempty"`
+}
+
+// ParseClaudeHook parses Claude Code hook input by its hook_event_name into
+// one of the *Claude...Hook types. Input from older releases that sent
+// hook_type instead is accepted too.
+func ParseClaudeHook(raw string) (ClaudeHook, error) {
+	data := []byte(strings.TrimSpace(stripBOM(raw)))
+	var head struct {
+		HookEventName string `json:"hook_event_name"`
+		HookType      string `json:"hook_type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("parse claude hook input: %w", err)
+	}
+	name := canonicalClaudeEvent(firstNonEmpty(head.HookEventName, head.HookType))
+	if name == "" {
+		// Older releases sent Stop input without naming the event.
+		name = ClaudeStop
+	}
+
+	var hook ClaudeHook
+	var common *ClaudeHookCommon
+	switch name {
+	case ClaudeStop, ClaudeSubagentStop:
+		h := &ClaudeStopHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	case ClaudeNotification:
+		h := &ClaudeNotificationHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	case ClaudePreCompact:
+		h := &ClaudePreCompactHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	case ClaudeSessionStart:
+		h := &ClaudeSessionStartHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	case ClaudeSessionEnd:
+		h := &ClaudeSessionEndHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	case ClaudeUserPromptSubmit:
+		h := &ClaudeUserPromptSubmitHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	case ClaudePreToolUse, ClaudePostToolUse, ClaudePermissionRequest:
+		h := &ClaudeToolHook{}
+		hook, common = h, &h.ClaudeHookCommon
+	default:
+		return nil, fmt.Errorf("parse claude hook input: unsupported hook event %q", name)
+	}
+	if err := json.Unmarshal(data, hook); err != nil {
+		return nil, fmt.Errorf("parse claude %s hook input: %w", name, err)
+	}
+	common.HookEventName = name
+	return hook, nil
+}
+
+// canonicalClaudeEvent returns the hook event name for raw regardless of
+// case, or raw itself when it is unknown.
+func canonicalClaudeEvent(raw string) string {
+	return canonicalHookEvent(ClaudeHookEvents, raw)
+}
+
+// NormalizeClaudeHookEvents returns the known events in names, canonically
+// spelled, deduplicated and in ClaudeHookEvents order. Unknown names are
+// returned separately.
+func NormalizeClaudeHookEvents(names []string) (events []string, unknown []string) {
+	return normalizeHookEvents(ClaudeHookEvents, names)
+}
+
+// canonicalHookEvent returns the event in known matching raw regardless of
+// case, or raw itself when there is none.
+func canonicalHookEvent(known []string, raw string) string {
+	raw = strings.TrimSpace(raw)
+	for _, name := range known {
+		if strings.EqualFold(raw, name) {
+			return name
+		}
+	}
+	return raw
+}
+
+// normalizeHookEvents returns the events of names found in known, in known
+// order, and the unknown ones sorted.
+func normalizeHookEvents(known, names []string) (events []string, unknown []string) {
+	seen := map[string]bool{}
+	for _, raw := range names {
+		name := canonicalHookEvent(known, raw)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if !isHookEvent(known, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	for _, name := range known {
+		if seen[name] {
+			events = append(events, name)
+		}
+	}
+	sort.Strings(unknown)
+	return events, unknown
+}
+
+func isHookEvent(known []string, name string) bool {
+	for _, k := range known {
+		if name == k {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ClaudeHookCommon) Common() ClaudeHookCommon { return c }
+
+// payload returns the shared payload fields for eventType.
+func (c ClaudeHookCommon) payload(eventType, summary string) Payload {
+	return Payload{
+		Type:           eventType,
+		Summary:        strings.TrimSpace(summary),
+		CWD:            strings.TrimSpace(c.CWD),
+		Model:          strings.TrimSpace(c.Model),
+		TranscriptPath: strings.TrimSpace(c.TranscriptPath),
+		SessionID:      strings.TrimSpace(c.SessionID),
+		HookEvent:      c.HookEventName,
+	}
+}
+
+func (h ClaudeStopHook) Payload() Payload {
+	if h.HookEventName == ClaudeSubagentStop {
+		return h.payload(TypeSubagentComplete, "")
+	}
+	summary := ""
+	if id := strings.TrimSpace(h.SessionID); id != "" {
+		summary = "Claude Code session " + id + " completed"
+	}
+	return h.payload("agent-turn-complete", summary)
+}
+
+// Payload tells subtypes apart by notification_type. Input without the
+// field comes from releases that predate it; only then is the message
+// inspected for a permission request.
+func (h ClaudeNotificationHook) Payload() Payload {
+	eventType := TypeAgentNotification
+	switch h.NotificationType {
+	case ClaudeNotificationPermission:
+		eventType = "agent-turn-paused"
+	case ClaudeNotificationIdle:
+		eventType = TypeAgentIdle
+	case "":
+		if isLegacyApprovalMessage(h.Message) {
+			eventType = "agent-turn-paused"
+		}
+	}
+	return h.payload(eventType, h.Message)
+}
+
+func (h ClaudePreCompactHook) Payload() Payload {
+	summary := "Compacting context"
+	if trigger := strings.TrimSpace(h.Trigger); trigger != "" {
+		summary += " (" + trigger + ")"
+	}
+	if instructions := strings.TrimSpace(h.CustomInstructions); instructions != "" {
+		summary += ": " + instructions
+	}
+	return h.payload(TypeContextCompact, summary)
+}
+
+func (h ClaudeSessionStartHook) Payload() Payload {
+	summary := "Session started"
+	if source := strings.TrimSpace(h.Source); source != "" {
+		summary += " (" + source + ")"
+	}
+	return h.payload(TypeSessionStart, summary)
+}
+
+func (h ClaudeSessionEndHook) Payload() Payload {
+	summary := "Session ended"
+	if reason := strings.TrimSpace(h.Reason); reason != "" {
+		summary += " (" + strings.ReplaceAll(reason, "_", " ") + ")"
+	}
+	return h.payload(TypeSessionEnd, summary)
+}
+
+func (h ClaudeUserPromptSubmitHook) Payload() Payload {
+	return h.payload(TypePromptSubmit, h.Prompt)
+}
+
+func (h ClaudeToolHook) Payload() Payload {
+	summary := strings.TrimSpace(h.ToolName)
+	if detail := toolInputDetail(h.ToolInput); detail != "" {
+		summary += ": " + detail
+	}
+	eventType := TypePreToolUse
+	switch h.HookEventName {
+	case ClaudePostToolUse:
+		eventType = TypePostToolUse
+	case ClaudePermissionRequest:
+		eventType = "agent-turn-paused"
+	}
+	payload := h.payload(eventType, summary)
+	payload.Tool = strings.TrimSpace(h.ToolName)
+	return payload
+}
+
+// Permission decisions of PreToolUse and PermissionRequest hooks. ask
+// leaves the decision to Claude Code's own prompt.
+const (
+	ClaudeAllow = "allow"
+	ClaudeDeny  = "deny"
+	ClaudeAsk   = "ask"
+)
+
+// ClaudePermissionOutput returns what a PreToolUse or PermissionRequest
+// hook prints to apply decision, one of ClaudeAllow, ClaudeDeny and
+// ClaudeAsk. reason is shown to Claude with a denial. A PermissionRequest
+// hook that asks prints nothing, so Claude Code shows its prompt.
+func ClaudePermissionOutput(hookEvent, decision, reason string) ([]byte, error) {
+	type permissionDecision struct {
+		Behavior string `json:"behavior"`
+		Message  string `json:"message,omitempty"`
+	}
+	type hookSpecificOutput struct {
+		HookEventName            string              `json:"hookEventName"`
+		PermissionDecision       string              `json:"permissionDecision,omitempty"`
+		PermissionDecisionReason string              `json:"permissionDecisionReason,omitempty"`
+		Decision                 *permissionDecision `json:"decision,omitempty"`
+	}
+	out := hookSpecificOutput{HookEventName: hookEvent}
+	switch hookEvent {
+	case ClaudePreToolUse:
+		out.PermissionDecision, out.PermissionDecisionReason = decision, reason
+	case ClaudePermissionRequest:
+		if decision == ClaudeAsk {
+			return nil, nil
+		}
+		out.Decision = &permissionDecision{Behavior: decision}
+		if decision == ClaudeDeny {
+			out.Decision.Message = reason
+		}
+	default:
+		return nil, fmt.Errorf("claude %s hooks take no permission decision", hookEvent)
+	}
+	return json.Marshal(map[string]any{"hookSpecificOutput": out})
+}
+
+// toolInputDetail picks the most telling field of a tool input: the shell
+// command, the file, the pattern or the URL.
+func toolInputDetail(raw json.RawMessage) string {
+	var input map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &input) != nil {
+		return ""
+	}
+	for _, key := range []string{"command", "file_path", "notebook_path", "path", "pattern", "url", "query", "description"} {
+		if value, ok := input[key].(string); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// isLegacyApprovalMessage recognizes permission requests in Notification
+// input that lacks notification_type.
+func isLegacyApprovalMessage(message string) bool {
+	text := strings.ToLower(message)
+	switch {
+	case strings.Contains(text, "would you like to run"),
+		strings.Contains(text, "requires your approval"),
+		strings.Contains(text, "needs your approval"),
+		strings.Contains(text, "needs your permission"),
+		strings.Contains(text, "permission") && strings.Contains(text, "command"),
+		strings.Contains(text, "allow") && strings.Contains(text, "command"),
+		strings.Contains(text, "是否执行"),
+		strings.Contains(text, "需要你的批准"),
+		strings.Contains(text, "允许执行"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package event
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseClaudeHook_MapsEveryEvent(t *testing.T) {
+	common := `"session_id":"s-1","transcript_path":"/home/dev/.claude/projects/api/s-1.jsonl","cwd":"/home/dev/api"`
+	cases := []struct {
+		raw     string
+		typ     string
+		summary string
+		tool    string
+	}{
+		{raw: `{"hook_event_name":"Stop","stop_hook_active":false,` + common + `}`, typ: "agent-turn-complete", summary: "Claude Code session s-1 completed"},
+		{raw: `{"hook_event_name":"SubagentStop",` + common + `}`, typ: TypeSubagentComplete},
+		{raw: `{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs your permission to use Bash",` + common + `}`, typ: "agent-turn-paused", summary: "Claude needs your permission to use Bash"},
+		{raw: `{"hook_event_name":"Notification","notification_type":"idle_prompt","message":"Claude is waiting for your input",` + common + `}`, typ: TypeAgentIdle, summary: "Claude is waiting for your input"},
+		{raw: `{"hook_event_name":"Notification","notification_type":"auth_success","message":"Would you like to run this command?",` + common + `}`, typ: TypeAgentNotification, summary: "Would you like to run this command?"},
+		{raw: `{"hook_event_name":"PreCompact","trigger":"manual","custom_instructions":"keep the API notes",` + common + `}`, typ: TypeContextCompact, summary: "Compacting context (manual): keep the API notes"},
+		{raw: `{"hook_event_name":"SessionStart","source":"resume",` + common + `}`, typ: TypeSessionStart, summary: "Session started (resume)"},
+		{raw: `{"hook_event_name":"SessionEnd","reason":"prompt_input_exit",` + common + `}`, typ: TypeSessionEnd, summary: "Session ended (prompt input exit)"},
+		{raw: `{"hook_event_name":"UserPromptSubmit","prompt":"fix the flaky test",` + common + `}`, typ: TypePromptSubmit, summary: "fix the flaky test"},
+		{raw: `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"go test ./...","description":"Run tests"},` + common + `}`, typ: TypePreToolUse, summary: "Bash: go test ./...", tool: "Bash"},
+		{raw: `{"hook_event_name":"PostToolUse","tool_name":"Edit","tool_input":{"file_path":"/home/dev/api/main.go"},"tool_response":{"success":true},` + common + `}`, typ: TypePostToolUse, summary: "Edit: /home/dev/api/main.go", tool: "Edit"},
+		{raw: `{"hook_event_name":"PermissionRequest","tool_name":"Bash","tool_input":{"command":"rm -rf build"},` + common + `}`, typ: "agent-turn-paused", summary: "Bash: rm -rf build", tool: "Bash"},
+	}
+	for _, tc := range cases {
+		hook, err := ParseClaudeHook(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.raw, err)
+		}
+		got := hook.Payload()
+		want := Payload{
+			Type:           tc.typ,
+			Summary:        tc.summary,
+			CWD:            "/home/dev/api",
+			TranscriptPath: "/home/dev/.claude/projects/api/s-1.jsonl",
+			SessionID:      "s-1",
+			HookEvent:      hook.Common().HookEventName,
+			Tool:           tc.tool,
+		}
+		if got != want {
+			t.Fatalf("%s:\n got %+v\nwant %+v", tc.raw, got, want)
+		}
+		if _, _, ok := RenderNotification(got); !ok {
+			t.Fatalf("%s: event type %q is not rendered", tc.raw, got.Type)
+		}
+	}
+}
+
+func TestParseClaudeHook_TypedStructs(t *testing.T) {
+	hook, err := ParseClaudeHook(`{"hook_event_name":"posttooluse","tool_name":"Bash","tool_input":{"command":"ls"},"tool_response":{"stdout":"a"}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tool, ok := hook.(*ClaudeToolHook)
+	if !ok || tool.HookEventName != ClaudePostToolUse || string(tool.ToolResponse) != `{"stdout":"a"}` {
+		t.Fatalf("expected typed PostToolUse hook, got %#v", hook)
+	}
+	if hook.Common().HookEventName != ClaudePostToolUse {
+		t.Fatalf("expected canonical event name, got %q", hook.Common().HookEventName)
+	}
+}
+
+func TestParseClaudeHook_LegacyInput(t *testing.T) {
+	hook, err := ParseClaudeHook(`{"hook_type":"notification","message":"Would you like to run the following command? ping 127.0.0.1"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := hook.Payload().Type; got != "agent-turn-paused" {
+		t.Fatalf("expected legacy approval message to pause, got %q", got)
+	}
+
+	hook, err = ParseClaudeHook(`{"session_id":"abc"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := hook.(*ClaudeStopHook); !ok {
+		t.Fatalf("expected unnamed input to be treated as Stop, got %T", hook)
+	}
+
+	if _, err := ParseClaudeHook(`{"hook_event_name":"TeleportStart"}`); err == nil || !strings.Contains(err.Error(), "TeleportStart") {
+		t.Fatalf("expected unsupported event error, got %v", err)
+	}
+}
+
+func TestNormalizeClaudeHookEvents(t *testing.T) {
+	events, unknown := NormalizeClaudeHookEvents([]string{"notification", " Stop", "PreToolUse", "stop", "Teleport", ""})
+	if !reflect.DeepEqual(events, []string{ClaudeStop, ClaudeNotification, ClaudePreToolUse}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if !reflect.DeepEqual(unknown, []string{"Teleport"}) {
+		t.Fatalf("unexpected unknown events: %v", unknown)
+	}
+}
+
+func TestRenderNotification_ClaudeEventTitles(t *testing.T) {
+	seen := map[string]bool{}
+	for _, typ := range []string{TypeSubagentComplete, TypeAgentIdle, TypeAgentNotification, TypeContextCompact, TypeSessionStart, TypeSessionEnd, TypePromptSubmit, TypePreToolUse, TypePostToolUse} {
+		title, body, ok := RenderNotificationWithOptions(Payload{Type: typ}, RenderOptions{ContentMode: ContentModeComplete})
+		if !ok || title == "" || body == "" || body == "complete" {
+			t.Fatalf("%s: unexpected rendering %q / %q", typ, title, body)
+		}
+		if seen[title] {
+			t.Fatalf("%s: title %q is not distinct", typ, title)
+		}
+		seen[title] = true
+	}
+}
+
+func TestClaudePermissionOutput(t *testing.T) {
+	cases := []struct {
+		event, decision, reason string
+		want                    string
+	}{
+		{event: ClaudePreToolUse, decision: ClaudeAllow, want: `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"allow"}}`},
+		{event: ClaudePreToolUse, decision: ClaudeDeny, reason: "use make test", want: `{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"deny","permissionDecisionReason":"use make test"}}`},
+		{event: ClaudePermissionRequest, decision: ClaudeAllow, want: `{"hookSpecificOutput":{"hookEventName":"PermissionRequest","decision":{"behavior":"allow"}}}`},
+		{event: ClaudePermissionRequest, decision: ClaudeDeny, reason: "not now", want: `{"hookSpecificOutput":{"hookEventName":"PermissionRequest","decision":{"behavior":"deny","message":"not now"}}}`},
+		{event: ClaudePermissionRequest, decision: ClaudeAsk},
+	}
+	for _, tc := range cases {
+		out, err := ClaudePermissionOutput(tc.event, tc.decision, tc.reason)
+		if err != nil || string(out) != tc.want {
+			t.Fatalf("%s %s: got %s, %v\nwant %s", tc.event, tc.decision, out, err, tc.want)
+		}
+	}
+	if _, err := ClaudePermissionOutput(ClaudeStop, ClaudeAllow, ""); err == nil {
+		t.Fatalf("expected an error for a hook without decisions")
+	}
+}
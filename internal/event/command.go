@@ -0,0 +1,62 @@
+package event
+
+import (
+	"strings"
+	"time"
+)
+
+// cc-notify event types produced by `cc-notify run` when the wrapped
+// command exits.
+const (
+	TypeCommandComplete = "command-complete"
+	TypeCommandFailed   = "command-failed"
+)
+
+// CommandResult describes a command that ran to its end.
+type CommandResult struct {
+	// Command is the command line as the user typed it.
+	Command  string
+	ExitCode int
+	Duration time.Duration
+	CWD      string
+	// Output holds the last lines the command printed to stdout and
+	// stderr.
+	Output []string
+}
+
+// Payload converts the result into the event it announces: a failure when
+// the command exited with a non-zero code. The output tail is both summary
+// and full message.
+func (r CommandResult) Payload() Payload {
+	payload := Payload{
+		Type:     TypeCommandComplete,
+		CWD:      strings.TrimSpace(r.CWD),
+		Command:  strings.TrimSpace(r.Command),
+		ExitCode: r.ExitCode,
+		Stats:    &TurnStats{Duration: r.Duration},
+	}
+	if r.ExitCode != 0 {
+		payload.Type = TypeCommandFailed
+	}
+	output := strings.TrimSpace(strings.Join(r.Output, "\n"))
+	payload.Summary = output
+	payload.LastAssistantMessage = output
+	return payload
+}
+
+// commandLines returns the lines naming the command and, for failures,
+// its exit code.
+func commandLines(payload Payload, opts RenderOptions) []string {
+	if payload.Type != TypeCommandComplete && payload.Type != TypeCommandFailed {
+		return nil
+	}
+	lang := opts.Language
+	var lines []string
+	if payload.Command != "" {
+		lines = append(lines, lang.Sprintf("Command: %s", truncate(80, payload.Command)))
+	}
+	if payload.Type == TypeCommandFailed {
+		lines = append(lines, lang.Sprintf("Exit code: %d", payload.ExitCode))
+	}
+	return lines
+}
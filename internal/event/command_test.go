@@ -0,0 +1,44 @@
+package event
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandResult_RendersFailure(t *testing.T) {
+	payload := CommandResult{
+		Command:  "go test ./...",
+		ExitCode: 2,
+		Duration: 95 * time.Second,
+		CWD:      "/home/dev/api",
+		Output:   []string{"--- FAIL: TestParse (0.00s)", "FAIL"},
+	}.Payload()
+	if payload.Type != TypeCommandFailed {
+		t.Fatalf("expected failure event, got %q", payload.Type)
+	}
+
+	title, body, ok := RenderNotificationWithOptions(payload, RenderOptions{IncludeDir: true, IncludeDuration: true})
+	if !ok {
+		t.Fatalf("expected command event to render")
+	}
+	if title != "Command Failed" {
+		t.Fatalf("unexpected title: %q", title)
+	}
+	for _, want := range []string{"FAIL: TestParse", "Dir: api", "Command: go test ./...", "Exit code: 2", "Done in 1m35s"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in body:\n%s", want, body)
+		}
+	}
+}
+
+func TestCommandResult_RendersSuccess(t *testing.T) {
+	payload := CommandResult{Command: "make", Duration: time.Minute}.Payload()
+	title, body, ok := RenderNotificationWithOptions(payload, RenderOptions{})
+	if !ok || title != "Command Finished" {
+		t.Fatalf("unexpected title: %q (ok=%v)", title, ok)
+	}
+	if body != "Command finished\nCommand: make" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
@@ -0,0 +1,271 @@
+package event
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxLength is the body length, in runes, used when RenderOptions
+// does not set one.
+const DefaultMaxLength = 300
+
+// markdownBlock is a condensed block of a Markdown message.
+type markdownBlock struct {
+	text string
+	// rank orders blocks by how much they are worth keeping: the first
+	// paragraph, then list items, then other text, then code markers.
+	rank int
+}
+
+const (
+	rankLead = iota
+	rankBullet
+	rankText
+	rankCode
+)
+
+var (
+	fenceLine     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([^\\s`]*)")
+	headingLine   = regexp.MustCompile(`^ {0,3}#{1,6}(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	ruleLine      = regexp.MustCompile(`^ {0,3}([-*_])(?:\s*([-*_])){2,}\s*$`)
+	quotePrefix   = regexp.MustCompile(`^ {0,3}>\s?`)
+	bulletLine    = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedLine   = regexp.MustCompile(`^\s*(\d{1,9})[.)]\s+(.*)$`)
+	tableRule     = regexp.MustCompile(`^\s*\|?\s*:?-{2,}:?\s*(?:\|\s*:?-{2,}:?\s*)*\|?\s*$`)
+	inlineImage   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	inlineLink    = regexp.MustCompile(`\[([^\]]+)\](?:\([^)]*\)|\[[^\]]*\])`)
+	autoLink      = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	strongStars   = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	strongUnder   = regexp.MustCompile(`__(\S(?:.*?\S)?)__`)
+	strikeThrough = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	emStar        = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	emUnder       = regexp.MustCompile(`(^|[^\p{L}\p{N}_])_(\S(?:[^_]*?\S)?)_($|[^\p{L}\p{N}_])`)
+)
+
+// Condense turns a Markdown agent message into plain notification text of
+// at most limit runes. Headings, emphasis and links are flattened, code
+// blocks become markers such as "[code: go, 40 lines]", and when the text
+// does not fit the first paragraph and list items are kept first. Text
+// that still does not fit is cut at a sentence boundary where possible.
+// Text without content condenses to "".
+func Condense(text string, limit int) string {
+	if limit <= 0 {
+		limit = DefaultMaxLength
+	}
+	blocks := parseMarkdownBlocks(text)
+	if len(blocks) == 0 {
+		return ""
+	}
+
+	if all := joinBlocks(blocks, nil); runeLen(all) <= limit {
+		return all
+	}
+
+	// Keep the most valuable blocks that fit, in their original order.
+	order := make([]int, len(blocks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return blocks[order[a]].rank < blocks[order[b]].rank })
+	keep := map[int]bool{}
+	used := 0
+	for n, i := range order {
+		size := runeLen(blocks[i].text)
+		if n == 0 && size > limit {
+			// The most valuable block alone is too long: it is all
+			// that is shown.
+			return truncateAtSentence(blocks[i].text, limit)
+		}
+		if used > 0 {
+			size++ // newline separator
+		}
+		if used+size > limit {
+			continue
+		}
+		keep[i] = true
+		used += size
+	}
+	return joinBlocks(blocks, keep)
+}
+
+func joinBlocks(blocks []markdownBlock, keep map[int]bool) string {
+	var parts []string
+	for i, block := range blocks {
+		if keep == nil || keep[i] {
+			parts = append(parts, block.text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// parseMarkdownBlocks splits text into condensed paragraphs, list items,
+// headings and code markers.
+func parseMarkdownBlocks(text string) []markdownBlock {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	var blocks []markdownBlock
+	var para []string
+	add := func(text string, rank int) {
+		if text = strings.TrimSpace(text); text != "" {
+			blocks = append(blocks, markdownBlock{text: text, rank: rank})
+		}
+	}
+	haveLead := false
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		// Line breaks within a paragraph are kept as the agent wrote them.
+		text := strings.TrimSpace(strings.Join(para, "\n"))
+		para = nil
+		if text == "" {
+			return
+		}
+		rank := rankText
+		if !haveLead {
+			rank, haveLead = rankLead, true
+		}
+		add(text, rank)
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		for quotePrefix.MatchString(line) {
+			line = quotePrefix.ReplaceAllString(line, "")
+		}
+
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			flush()
+			fence, lang := m[1], strings.ToLower(m[2])
+			count := 0
+			for i+1 < len(lines) {
+				i++
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence[:3]) && strings.Trim(strings.TrimSpace(lines[i]), fence[:1]) == "" {
+					break
+				}
+				count++
+			}
+			add(codeMarker(lang, count), rankCode)
+			continue
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case ruleLine.MatchString(line) || tableRule.MatchString(line):
+			flush()
+		case headingLine.MatchString(line):
+			flush()
+			add(flattenInline(headingLine.FindStringSubmatch(line)[1]), rankText)
+		case bulletLine.MatchString(line):
+			flush()
+			add("• "+flattenInline(bulletLine.FindStringSubmatch(line)[1]), rankBullet)
+		case orderedLine.MatchString(line):
+			flush()
+			m := orderedLine.FindStringSubmatch(line)
+			add(m[1]+". "+flattenInline(m[2]), rankBullet)
+		case strings.HasPrefix(strings.TrimSpace(line), "|"):
+			flush()
+			add(tableRow(line), rankText)
+		default:
+			para = append(para, flattenInline(line))
+		}
+	}
+	flush()
+	return blocks
+}
+
+func codeMarker(lang string, lines int) string {
+	count := fmt.Sprintf("%d lines", lines)
+	if lines == 1 {
+		count = "1 line"
+	}
+	if lang == "" {
+		return "[code: " + count + "]"
+	}
+	return "[code: " + lang + ", " + count + "]"
+}
+
+func tableRow(line string) string {
+	cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+	var parts []string
+	for _, cell := range cells {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			parts = append(parts, flattenInline(cell))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// flattenInline removes inline markup outside code spans and collapses
+// whitespace. Code spans keep their backticks.
+func flattenInline(line string) string {
+	segments := strings.Split(line, "`")
+	if len(segments)%2 == 0 {
+		// An unmatched backtick is plain text.
+		segments[len(segments)-2] += "`" + segments[len(segments)-1]
+		segments = segments[:len(segments)-1]
+	}
+	for i := 0; i < len(segments); i += 2 {
+		s := segments[i]
+		s = inlineImage.ReplaceAllString(s, "$1")
+		s = inlineLink.ReplaceAllString(s, "$1")
+		s = autoLink.ReplaceAllString(s, "$1")
+		s = strongStars.ReplaceAllString(s, "$1")
+		s = strongUnder.ReplaceAllString(s, "$1")
+		s = strikeThrough.ReplaceAllString(s, "$1")
+		s = emStar.ReplaceAllString(s, "$1")
+		// Adjacent matches share their boundary, so a second pass catches
+		// the ones the first skipped.
+		s = emUnder.ReplaceAllString(s, "$1$2$3")
+		s = emUnder.ReplaceAllString(s, "$1$2$3")
+		segments[i] = s
+	}
+	return strings.Join(strings.Fields(strings.Join(segments, "`")), " ")
+}
+
+// truncateAtSentence shortens text to at most limit runes. It cuts after
+// the last full sentence or line that fits, else at a word boundary with
+// an ellipsis, else mid-word for scripts without spaces.
+func truncateAtSentence(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	const ellipsis = "..."
+	if limit <= len(ellipsis) {
+		return string(runes[:limit])
+	}
+
+	minKeep := limit / 3
+	for i := limit - 1; i >= minKeep; i-- {
+		if isSentenceEnd(runes, i) {
+			return strings.TrimSpace(string(runes[:i+1]))
+		}
+	}
+
+	cut := limit - len(ellipsis)
+	for i := cut; i >= limit/2; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return strings.TrimRightFunc(string(runes[:i]), unicode.IsSpace) + ellipsis
+		}
+	}
+	return string(runes[:cut]) + ellipsis
+}
+
+// isSentenceEnd reports whether runes[i] ends a sentence or a line.
+func isSentenceEnd(runes []rune, i int) bool {
+	switch runes[i] {
+	case '。', '！', '？':
+		return true
+	case '.', '!', '?':
+		return i+1 < len(runes) && unicode.IsSpace(runes[i+1])
+	}
+	return i+1 < len(runes) && runes[i+1] == '\n'
+}
+
+func runeLen(text string) int {
+	return len([]rune(text))
+}
@@ -0,0 +1,58 @@
+package event
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCondense_FlattensMarkdown(t *testing.T) {
+	text := "## Summary\n\n" +
+		"Fixed the **flaky** test in [retry.go](internal/retry.go) and _renamed_ `do_retry`.\n\n" +
+		"```go\nfunc retry() {\n\treturn\n}\n```\n\n" +
+		"- Added a __backoff__ option\n" +
+		"1. Run ~~make~~ `go test ./...`\n\n" +
+		"> See <https://example.com/issue/1>\n"
+	want := "Summary\n" +
+		"Fixed the flaky test in retry.go and renamed `do_retry`.\n" +
+		"[code: go, 3 lines]\n" +
+		"• Added a backoff option\n" +
+		"1. Run make `go test ./...`\n" +
+		"See https://example.com/issue/1"
+	if got := Condense(text, 500); got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestCondense_PrefersLeadParagraphAndBullets(t *testing.T) {
+	text := "# Refactor\n\n" +
+		"Moved the retry logic into its own package.\n\n" +
+		"```\n" + strings.Repeat("line\n", 40) + "```\n\n" +
+		"Some background on why the old approach was slow and hard to test.\n\n" +
+		"- retry.go: new package\n" +
+		"- client.go: uses it\n"
+	want := "Moved the retry logic into its own package.\n• retry.go: new package\n• client.go: uses it"
+	if got := Condense(text, 95); got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+	// Shorter blocks still fill the space left, in their original order.
+	want = "Refactor\n" + want
+	if got := Condense(text, 100); got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestCondense_TruncatesAtSentence(t *testing.T) {
+	text := "Updated the parser. It now handles nested lists and tables. The old tests still pass."
+	if got := Condense(text, 60); got != "Updated the parser. It now handles nested lists and tables." {
+		t.Fatalf("unexpected sentence cut: %q", got)
+	}
+	if got := Condense("一二三四五六七八九十。一二三四五六七八九十", 15); got != "一二三四五六七八九十。" {
+		t.Fatalf("unexpected CJK cut: %q", got)
+	}
+	if got := Condense(strings.Repeat("word ", 30), 40); got != strings.TrimSpace(strings.Repeat("word ", 7))+"..." {
+		t.Fatalf("unexpected word cut: %q", got)
+	}
+	if got := Condense("  \n", 40); got != "" {
+		t.Fatalf("unexpected empty text: %q", got)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"text/template"
+
+	"cc-notify/internal/i18n"
 )
 
 // Payload is the Codex notify JSON payload.
@@ -18,6 +21,25 @@ type Payload struct {
 	CWD                  string `json:"cwd"`
 	Model                string `json:"model"`
 	TranscriptPath       string `json:"transcript-path"`
+	// SessionID identifies the agent session: Codex sends thread-id,
+	// Claude Code session_id.
+	SessionID string `json:"session-id,omitempty"`
+	// LastUserPrompt is the prompt that started the turn, when known.
+	LastUserPrompt string `json:"last-user-prompt,omitempty"`
+	// HookEvent is the agent's name for the hook event, e.g. PreToolUse,
+	// and Tool the tool a tool event is about.
+	HookEvent string `json:"hook-event,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	// Command and ExitCode describe the command wrapped by `cc-notify run`.
+	Command  string `json:"command,omitempty"`
+	ExitCode int    `json:"exit-code,omitempty"`
+	// Stats describes the turn when its transcript was read.
+	Stats *TurnStats `json:"-"`
+	// Git describes the repository at CWD when it was read.
+	Git *GitInfo `json:"-"`
+	// Changes counts the files and lines changed during the turn, when a
+	// snapshot of its start was taken.
+	Changes *ChangeStats `json:"-"`
 }
 
 // UnmarshalJSON implements custom JSON decoding that accepts both hyphenated
@@ -49,6 +71,17 @@ func (p *Payload) UnmarshalJSON(data []byte) error {
 				}
 			}
 		}
+		for _, key := range []string{"session_id", "thread-id", "thread_id"} {
+			if alias.SessionID != "" {
+				break
+			}
+			if v, ok := raw[key]; ok {
+				var s string
+				if json.Unmarshal(v, &s) == nil {
+					alias.SessionID = s
+				}
+			}
+		}
 	}
 
 	*p = Payload(alias)
@@ -70,6 +103,55 @@ type RenderOptions struct {
 	IncludeDir   bool
 	IncludeModel bool
 	IncludeEvent bool
+	// Turn statistics, shown on one line when Payload.Stats is set.
+	IncludeDuration bool
+	IncludeTokens   bool
+	IncludeCost     bool
+	IncludeTools    bool
+	// Git context, shown on one line when Payload.Git is set.
+	IncludeGitBranch   bool
+	IncludeGitHead     bool
+	IncludeGitStatus   bool
+	IncludeGitWorktree bool
+	// IncludeChanges shows Payload.Changes, the files and lines changed
+	// during the turn.
+	IncludeChanges bool
+	// MaxLength limits the message text in runes; 0 means
+	// DefaultMaxLength. See Condense.
+	MaxLength int
+	// TitleTemplate and BodyTemplate, when set, replace the default title
+	// and body; see TemplateData. Source is passed to them.
+	TitleTemplate *template.Template
+	BodyTemplate  *template.Template
+	Source        string
+	// Language translates titles, default bodies and field labels; empty
+	// means English.
+	Language i18n.Language
+	// Title replaces the default title of the event before it is
+	// translated, for sources with their own names for events.
+	Title string
+}
+
+// WantsStats reports whether any turn statistic is shown.
+func (o RenderOptions) WantsStats() bool {
+	return o.IncludeDuration || o.IncludeTokens || o.IncludeCost || o.IncludeTools
+}
+
+// WantsGit reports whether any git field is shown.
+func (o RenderOptions) WantsGit() bool {
+	return o.IncludeGitBranch || o.IncludeGitHead || o.IncludeGitStatus || o.IncludeGitWorktree
+}
+
+// NeedsGit reports whether Payload.Git is used: a git field is shown or
+// a template refers to one.
+func (o RenderOptions) NeedsGit() bool {
+	return o.WantsGit() || templateUses(o.TitleTemplate, gitTemplateFields...) || templateUses(o.BodyTemplate, gitTemplateFields...)
+}
+
+// NeedsGitStatus reports whether the modified and untracked counts of
+// Payload.Git are used, which cost a scan of the working tree.
+func (o RenderOptions) NeedsGitStatus() bool {
+	return o.IncludeGitStatus || templateUses(o.TitleTemplate, gitStatusTemplateFields...) || templateUses(o.BodyTemplate, gitStatusTemplateFields...)
 }
 
 // ParsePayload parses a Codex notify payload from JSON.
@@ -103,47 +185,86 @@ func RenderNotification(payload Payload) (title string, body string, ok bool) {
 // RenderNotificationWithOptions converts payload into notification title/body using user-selected options.
 // ok is false when event type is unsupported and should be ignored.
 func RenderNotificationWithOptions(payload Payload, opts RenderOptions) (title string, body string, ok bool) {
-	switch payload.Type {
-	case "agent-turn-complete":
-		title = "Codex Task Complete"
-	case "agent-turn-paused":
-		title = "Codex Needs Input"
-	default:
+	title, ok = eventTitles[payload.Type]
+	if !ok {
 		return "", "", false
 	}
+	if opts.Title != "" {
+		title = opts.Title
+	}
+	lang := opts.Language
+	title = lang.T(title)
 
 	mode := normalizeContentMode(opts.ContentMode)
 	switch mode {
 	case ContentModeComplete:
-		body = "complete"
-		if payload.Type == "agent-turn-paused" {
-			body = "waiting for approval"
+		switch payload.Type {
+		case "agent-turn-complete":
+			body = lang.T("complete")
+		case "agent-turn-paused":
+			body = lang.T("waiting for approval")
+		default:
+			body = lang.T(defaultBodyForType(payload.Type))
 		}
 	case ContentModeFull:
-		body = firstNonEmpty(payload.LastAssistantMessage, payload.Summary, defaultBodyForType(payload.Type))
+		body = firstNonEmpty(payload.LastAssistantMessage, localSummary(payload, lang), lang.T(defaultBodyForType(payload.Type)))
 	default:
-		body = firstNonEmpty(payload.Summary, payload.LastAssistantMessage, defaultBodyForType(payload.Type))
+		body = firstNonEmpty(localSummary(payload, lang), payload.LastAssistantMessage, lang.T(defaultBodyForType(payload.Type)))
 	}
-	body = cleanText(body)
+	// A message of markup alone condenses to nothing.
+	body = firstNonEmpty(Condense(body, opts.MaxLength), lang.T(defaultBodyForType(payload.Type)))
+	text := body
 
 	if opts.IncludeDir {
 		dirName := strings.TrimSpace(filepath.Base(strings.TrimSpace(payload.CWD)))
 		if dirName != "" && dirName != "." && dirName != string(filepath.Separator) {
-			body += "\nDir: " + dirName
+			body += "\n" + lang.Sprintf("Dir: %s", dirName)
 		}
 	}
 	if opts.IncludeModel {
 		model := strings.TrimSpace(payload.Model)
 		if model != "" {
-			body += "\nModel: " + model
+			body += "\n" + lang.Sprintf("Model: %s", model)
 		}
 	}
+	if line := gitLine(payload.Git, opts); line != "" {
+		body += "\n" + line
+	}
+	for _, line := range commandLines(payload, opts) {
+		body += "\n" + line
+	}
+	if line := changesLine(payload.Changes, opts); line != "" {
+		body += "\n" + line
+	}
+	if line := statsLine(payload.Stats, opts); line != "" {
+		body += "\n" + line
+	}
 	if opts.IncludeEvent {
-		body += "\nEvent: " + payload.Type
+		body += "\n" + lang.Sprintf("Event: %s", payload.Type)
+	}
+
+	if opts.TitleTemplate != nil || opts.BodyTemplate != nil {
+		data := newTemplateData(payload, opts.Source, title, text)
+		title = renderTemplate(opts.TitleTemplate, data, title)
+		body = renderTemplate(opts.BodyTemplate, data, body)
 	}
 	return title, body, true
 }
 
+// localSummary returns the summary of payload with the fixed text that
+// lifecycle hooks start it with, e.g. "Session started (resume)",
+// translated to lang.
+func localSummary(payload Payload, lang i18n.Language) string {
+	switch payload.Type {
+	case TypeContextCompact, TypeSessionStart, TypeSessionEnd:
+		prefix := defaultBodyForType(payload.Type)
+		if rest, ok := strings.CutPrefix(payload.Summary, prefix); ok {
+			return lang.T(prefix) + rest
+		}
+	}
+	return payload.Summary
+}
+
 func normalizeContentMode(mode ContentMode) ContentMode {
 	switch mode {
 	case ContentModeComplete, ContentModeFull:
@@ -153,10 +274,49 @@ func normalizeContentMode(mode ContentMode) ContentMode {
 	}
 }
 
+// eventTitles holds the notification title of every supported event type.
+var eventTitles = map[string]string{
+	"agent-turn-complete": "Codex Task Complete",
+	"agent-turn-paused":   "Codex Needs Input",
+	TypeSubagentComplete:  "Claude Subagent Finished",
+	TypeAgentIdle:         "Claude Is Waiting for You",
+	TypeAgentNotification: "Claude Code Notification",
+	TypeContextCompact:    "Claude Is Compacting Context",
+	TypeSessionStart:      "Claude Session Started",
+	TypeSessionEnd:        "Claude Session Ended",
+	TypePromptSubmit:      "Claude Prompt Submitted",
+	TypePreToolUse:        "Claude Is Using a Tool",
+	TypePostToolUse:       "Claude Tool Finished",
+	TypeCommandComplete:   "Command Finished",
+	TypeCommandFailed:     "Command Failed",
+}
+
 func defaultBodyForType(eventType string) string {
 	switch eventType {
 	case "agent-turn-paused":
 		return "Waiting for your approval"
+	case TypeSubagentComplete:
+		return "Subagent finished"
+	case TypeAgentIdle:
+		return "Waiting for your input"
+	case TypeAgentNotification:
+		return "New notification"
+	case TypeContextCompact:
+		return "Compacting context"
+	case TypeSessionStart:
+		return "Session started"
+	case TypeSessionEnd:
+		return "Session ended"
+	case TypePromptSubmit:
+		return "Prompt submitted"
+	case TypePreToolUse:
+		return "Tool call starting"
+	case TypePostToolUse:
+		return "Tool call finished"
+	case TypeCommandComplete:
+		return "Command finished"
+	case TypeCommandFailed:
+		return "Command failed"
 	default:
 		return "Task completed"
 	}
@@ -171,19 +331,3 @@ func firstNonEmpty(values ...string) string {
 	}
 	return ""
 }
-
-func cleanText(value string) string {
-	lines := strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n")
-	for i, line := range lines {
-		lines[i] = strings.Join(strings.Fields(line), " ")
-	}
-	result := strings.TrimSpace(strings.Join(lines, "\n"))
-	runes := []rune(result)
-	if len(runes) > 300 {
-		result = string(runes[:297]) + "..."
-	}
-	if result == "" {
-		return "Task completed"
-	}
-	return result
-}
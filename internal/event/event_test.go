@@ -3,6 +3,8 @@ package event
 import (
 	"strings"
 	"testing"
+
+	"cc-notify/internal/i18n"
 )
 
 func TestParsePayload_Success(t *testing.T) {
@@ -260,3 +262,60 @@ func TestRenderNotification_TruncatesByRunesNotBytes(t *testing.T) {
 		t.Fatalf("expected 300 runes after truncation, got %d", runeCount)
 	}
 }
+
+func TestParsePayload_SessionID(t *testing.T) {
+	cases := map[string]string{
+		`{"type":"agent-turn-complete","thread-id":"t-1"}`:                     "t-1",
+		`{"type":"agent-turn-complete","session_id":"s-1","thread-id":"t-1"}`:  "s-1",
+		`{"type":"agent-turn-complete","session-id":"p-1","session_id":"s-1"}`: "p-1",
+	}
+	for raw, want := range cases {
+		got, err := ParsePayload(raw)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.SessionID != want {
+			t.Fatalf("%s: expected session id %q, got %q", raw, want, got.SessionID)
+		}
+	}
+}
+
+func TestRenderNotificationWithOptions_Language(t *testing.T) {
+	payload := Payload{Type: "agent-turn-complete", CWD: "/work/api", Model: "gpt-5", Stats: &TurnStats{ToolCalls: 3, Commands: 1}}
+	title, body, _ := RenderNotificationWithOptions(payload, RenderOptions{
+		ContentMode:  ContentModeComplete,
+		IncludeDir:   true,
+		IncludeModel: true,
+		IncludeTools: true,
+		Language:     i18n.SimplifiedChinese,
+	})
+	if title != "Codex 任务完成" {
+		t.Fatalf("unexpected title: %q", title)
+	}
+	if body != "已完成\n目录：api\n模型：gpt-5\n3 次工具调用 (1 条命令)" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+func TestRenderNotificationWithOptions_TranslatesLifecycleSummaries(t *testing.T) {
+	payload := ClaudeSessionEndHook{Reason: "prompt_input_exit"}.Payload()
+	_, body, _ := RenderNotificationWithOptions(payload, RenderOptions{Language: i18n.SimplifiedChinese})
+	if body != "会话已结束 (prompt input exit)" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+	payload = Payload{Type: TypeSessionEnd, Summary: "Session ended by the user"}
+	if _, body, _ := RenderNotificationWithOptions(payload, RenderOptions{}); body != payload.Summary {
+		t.Fatalf("expected English summary unchanged, got %q", body)
+	}
+}
+
+func TestRenderNotificationWithOptions_MarkupOnlyFallsBackToTranslatedDefault(t *testing.T) {
+	payload := Payload{Type: "agent-turn-complete", LastAssistantMessage: "---"}
+	_, body, _ := RenderNotificationWithOptions(payload, RenderOptions{
+		ContentMode: ContentModeFull,
+		Language:    i18n.SimplifiedChinese,
+	})
+	if body != "任务已完成" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
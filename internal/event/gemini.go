@@ -0,0 +1,128 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Gemini CLI hook event names, as sent in hook_event_name and used as keys
+// under "hooks" in Gemini CLI settings.
+const (
+	GeminiAfterAgent   = "AfterAgent"
+	GeminiNotification = "Notification"
+	GeminiPreCompress  = "PreCompress"
+	GeminiSessionStart = "SessionStart"
+	GeminiSessionEnd   = "SessionEnd"
+	GeminiBeforeAgent  = "BeforeAgent"
+	GeminiBeforeTool   = "BeforeTool"
+	GeminiAfterTool    = "AfterTool"
+)
+
+// GeminiHookEvents lists every Gemini CLI hook event cc-notify understands.
+var GeminiHookEvents = []string{
+	GeminiAfterAgent,
+	GeminiNotification,
+	GeminiPreCompress,
+	GeminiSessionStart,
+	GeminiSessionEnd,
+	GeminiBeforeAgent,
+	GeminiBeforeTool,
+	GeminiAfterTool,
+}
+
+// DefaultGeminiHookEvents are the events installed when the user has not
+// chosen any.
+var DefaultGeminiHookEvents = []string{GeminiAfterAgent, GeminiNotification}
+
+// GeminiHook is a parsed Gemini CLI hook input. Gemini CLI sends one shape
+// for every event, so the fields an event does not use are empty.
+type GeminiHook struct {
+	HookEventName  string `json:"hook_event_name"`
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	CWD            string `json:"cwd"`
+	// Prompt is set for BeforeAgent and AfterAgent, PromptResponse for
+	// AfterAgent.
+	Prompt         string `json:"prompt,omitempty"`
+	PromptResponse string `json:"prompt_response,omitempty"`
+	// Notification fields.
+	NotificationType string `json:"notification_type,omitempty"`
+	Message          string `json:"message,omitempty"`
+	// Trigger is set for PreCompress, Source for SessionStart and Reason
+	// for SessionEnd.
+	Trigger string `json:"trigger,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	// Tool fields for BeforeTool and AfterTool.
+	ToolName  string          `json:"tool_name,omitempty"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+}
+
+// ParseGeminiHook parses Gemini CLI hook input.
+func ParseGeminiHook(raw string) (GeminiHook, error) {
+	var hook GeminiHook
+	if err := json.Unmarshal([]byte(strings.TrimSpace(stripBOM(raw))), &hook); err != nil {
+		return GeminiHook{}, fmt.Errorf("parse gemini hook input: %w", err)
+	}
+	name := canonicalHookEvent(GeminiHookEvents, hook.HookEventName)
+	if !isHookEvent(GeminiHookEvents, name) {
+		return GeminiHook{}, fmt.Errorf("parse gemini hook input: unsupported hook event %q", name)
+	}
+	hook.HookEventName = name
+	return hook, nil
+}
+
+// NormalizeGeminiHookEvents is NormalizeClaudeHookEvents for Gemini CLI.
+func NormalizeGeminiHookEvents(names []string) (events []string, unknown []string) {
+	return normalizeHookEvents(GeminiHookEvents, names)
+}
+
+// Payload converts the hook into the cc-notify event it announces.
+func (h GeminiHook) Payload() Payload {
+	payload := Payload{
+		CWD:            strings.TrimSpace(h.CWD),
+		TranscriptPath: strings.TrimSpace(h.TranscriptPath),
+		SessionID:      strings.TrimSpace(h.SessionID),
+	}
+	switch h.HookEventName {
+	case GeminiAfterAgent:
+		payload.Type = "agent-turn-complete"
+		payload.LastAssistantMessage = strings.TrimSpace(h.PromptResponse)
+		payload.LastUserPrompt = strings.TrimSpace(h.Prompt)
+	case GeminiNotification:
+		payload.Type = TypeAgentNotification
+		payload.Summary = strings.TrimSpace(h.Message)
+	case GeminiPreCompress:
+		payload.Type = TypeContextCompact
+		payload.Summary = "Compacting context"
+		if trigger := strings.TrimSpace(h.Trigger); trigger != "" {
+			payload.Summary += " (" + trigger + ")"
+		}
+	case GeminiSessionStart:
+		payload.Type = TypeSessionStart
+		payload.Summary = "Session started"
+		if source := strings.TrimSpace(h.Source); source != "" {
+			payload.Summary += " (" + source + ")"
+		}
+	case GeminiSessionEnd:
+		payload.Type = TypeSessionEnd
+		payload.Summary = "Session ended"
+		if reason := strings.TrimSpace(h.Reason); reason != "" {
+			payload.Summary += " (" + strings.ReplaceAll(reason, "_", " ") + ")"
+		}
+	case GeminiBeforeAgent:
+		payload.Type = TypePromptSubmit
+		payload.Summary = strings.TrimSpace(h.Prompt)
+	case GeminiBeforeTool, GeminiAfterTool:
+		payload.Type = TypePreToolUse
+		if h.HookEventName == GeminiAfterTool {
+			payload.Type = TypePostToolUse
+		}
+		payload.Summary = strings.TrimSpace(h.ToolName)
+		if detail := toolInputDetail(h.ToolInput); detail != "" {
+			payload.Summary += ": " + detail
+		}
+	}
+	return payload
+}
@@ -0,0 +1,63 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGeminiHook_MapsEveryEvent(t *testing.T) {
+	common := `"session_id":"g-1","transcript_path":"/home/dev/.gemini/tmp/g-1.json","cwd":"/home/dev/api"`
+	cases := []struct {
+		raw  string
+		want Payload
+	}{
+		{raw: `{"hook_event_name":"AfterAgent","prompt":"fix the flaky test","prompt_response":"Fixed the race.","stop_hook_active":false,` + common + `}`,
+			want: Payload{Type: "agent-turn-complete", LastAssistantMessage: "Fixed the race.", LastUserPrompt: "fix the flaky test"}},
+		{raw: `{"hook_event_name":"Notification","notification_type":"ToolPermission","message":"Allow run_shell_command?",` + common + `}`,
+			want: Payload{Type: TypeAgentNotification, Summary: "Allow run_shell_command?"}},
+		{raw: `{"hook_event_name":"PreCompress","trigger":"auto",` + common + `}`,
+			want: Payload{Type: TypeContextCompact, Summary: "Compacting context (auto)"}},
+		{raw: `{"hook_event_name":"SessionStart","source":"resume",` + common + `}`,
+			want: Payload{Type: TypeSessionStart, Summary: "Session started (resume)"}},
+		{raw: `{"hook_event_name":"SessionEnd","reason":"prompt_input_exit",` + common + `}`,
+			want: Payload{Type: TypeSessionEnd, Summary: "Session ended (prompt input exit)"}},
+		{raw: `{"hook_event_name":"BeforeAgent","prompt":"fix the flaky test",` + common + `}`,
+			want: Payload{Type: TypePromptSubmit, Summary: "fix the flaky test"}},
+		{raw: `{"hook_event_name":"BeforeTool","tool_name":"run_shell_command","tool_input":{"command":"go test ./..."},` + common + `}`,
+			want: Payload{Type: TypePreToolUse, Summary: "run_shell_command: go test ./..."}},
+		{raw: `{"hook_event_name":"aftertool","tool_name":"write_file","tool_input":{"file_path":"main.go"},"tool_response":{},` + common + `}`,
+			want: Payload{Type: TypePostToolUse, Summary: "write_file: main.go"}},
+	}
+	for _, tc := range cases {
+		hook, err := ParseGeminiHook(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.raw, err)
+		}
+		want := tc.want
+		want.CWD = "/home/dev/api"
+		want.TranscriptPath = "/home/dev/.gemini/tmp/g-1.json"
+		want.SessionID = "g-1"
+		if got := hook.Payload(); got != want {
+			t.Fatalf("%s:\n got %+v\nwant %+v", tc.raw, got, want)
+		}
+	}
+}
+
+func TestParseGeminiHook_RejectsUnknownEvent(t *testing.T) {
+	if _, err := ParseGeminiHook(`{"hook_event_name":"BeforeModel"}`); err == nil {
+		t.Fatalf("expected error for unsupported event")
+	}
+	if _, err := ParseGeminiHook(`not json`); err == nil {
+		t.Fatalf("expected error for invalid input")
+	}
+}
+
+func TestNormalizeGeminiHookEvents(t *testing.T) {
+	events, unknown := NormalizeGeminiHookEvents([]string{"notification", "AfterAgent", "Stop", "afteragent"})
+	if !reflect.DeepEqual(events, []string{GeminiAfterAgent, GeminiNotification}) {
+		t.Fatalf("unexpected events: %v", events)
+	}
+	if !reflect.DeepEqual(unknown, []string{"Stop"}) {
+		t.Fatalf("unexpected unknown events: %v", unknown)
+	}
+}
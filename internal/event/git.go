@@ -0,0 +1,695 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitInfo describes the repository a payload's directory belongs to.
+type GitInfo struct {
+	// Branch is the checked-out branch, empty for a detached HEAD.
+	Branch string
+	// Head is the short commit of HEAD, empty before the first commit.
+	Head string
+	// Worktree is the name of a linked worktree, empty for the main one.
+	Worktree string
+	// Modified counts tracked files whose content differs from the index,
+	// deleted ones included; Untracked counts files git would list as
+	// untracked. Both are only set when the status was read.
+	Modified  int
+	Untracked int
+}
+
+// ReadGitInfo reads the repository containing dir from its .git directory,
+// without running git. It returns nil outside a repository. With status,
+// the working tree is compared against the index to count modified and
+// untracked files; the error then reports an index cannot be read.
+func ReadGitInfo(dir string, status bool) (*GitInfo, error) {
+	repo, ok := openGitRepo(dir)
+	if !ok {
+		return nil, nil
+	}
+	info := &GitInfo{Worktree: repo.worktreeName()}
+	head, err := os.ReadFile(filepath.Join(repo.gitDir, "HEAD"))
+	if err != nil {
+		return nil, nil
+	}
+	ref := strings.TrimSpace(string(head))
+	commit := ref
+	if name, ok := strings.CutPrefix(ref, "ref: "); ok {
+		name = strings.TrimSpace(name)
+		info.Branch = strings.TrimPrefix(name, "refs/heads/")
+		commit = repo.resolveRef(name)
+	}
+	info.Head = shortCommit(commit)
+	if !status {
+		return info, nil
+	}
+	if err := repo.countChanges(info); err != nil {
+		return info, fmt.Errorf("read git status of %s: %w", repo.root, err)
+	}
+	return info, nil
+}
+
+// gitBranch returns the branch checked out in the repository containing
+// dir, the short commit for a detached HEAD, or "" outside a repository.
+// It reads .git directly instead of running git.
+func gitBranch(dir string) string {
+	info, _ := ReadGitInfo(dir, false)
+	if info == nil {
+		return ""
+	}
+	return firstNonEmpty(info.Branch, info.Head)
+}
+
+func shortCommit(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) < 7 {
+		return ""
+	}
+	if _, err := hex.DecodeString(id[:6]); err != nil {
+		return ""
+	}
+	return id[:7]
+}
+
+// gitRepo locates the parts of a repository: the working tree root, the
+// git directory of the worktree (HEAD, index) and the common directory
+// shared by all worktrees (refs, config, info/exclude).
+type gitRepo struct {
+	root      string
+	gitDir    string
+	commonDir string
+}
+
+func openGitRepo(dir string) (gitRepo, bool) {
+	root, gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return gitRepo{}, false
+	}
+	repo := gitRepo{root: root, gitDir: gitDir, commonDir: gitDir}
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		repo.commonDir = filepath.Clean(common)
+	}
+	return repo, true
+}
+
+// findGitDir walks up from dir to the git directory of its repository and
+// returns it with the working tree root. A .git file, as in worktrees and
+// submodules, points to the real one.
+func findGitDir(dir string) (root string, gitDir string) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", ""
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+	for {
+		candidate := filepath.Join(dir, ".git")
+		info, err := os.Stat(candidate)
+		if err == nil {
+			if info.IsDir() {
+				return dir, candidate
+			}
+			data, err := os.ReadFile(candidate)
+			if err != nil {
+				return "", ""
+			}
+			target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+			if !ok {
+				return "", ""
+			}
+			target = strings.TrimSpace(target)
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			return dir, target
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// worktreeName returns the name of a linked worktree, whose git directory
+// is <common>/worktrees/<name>.
+func (r gitRepo) worktreeName() string {
+	if filepath.Base(filepath.Dir(r.gitDir)) != "worktrees" {
+		return ""
+	}
+	return filepath.Base(r.gitDir)
+}
+
+// resolveRef returns the commit ref points to, from a loose ref or
+// packed-refs, or "" for a branch without commits.
+func (r gitRepo) resolveRef(ref string) string {
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		id, name, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if ok && name == ref {
+			return id
+		}
+	}
+	return ""
+}
+
+// sha256Repo reports whether the repository uses SHA-256 object names.
+func (r gitRepo) sha256Repo() bool {
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "config"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "objectformat") {
+			return strings.EqualFold(strings.TrimSpace(value), "sha256")
+		}
+	}
+	return false
+}
+
+// indexEntry is a stage-0 entry of the git index.
+type indexEntry struct {
+	path      string
+	mode      uint32
+	mtimeSec  uint32
+	mtimeNsec uint32
+	size      uint32
+	id        []byte
+	// skip is set for entries git does not compare with the working tree:
+	// assume-unchanged, skip-worktree and intent-to-add.
+	skip bool
+}
+
+// Index entry flags.
+const (
+	indexAssumeValid  = 0x8000
+	indexExtended     = 0x4000
+	indexStageMask    = 0x3000
+	indexSkipWorktree = 0x4000 // extended
+	indexIntentToAdd  = 0x2000 // extended
+)
+
+// readGitIndex parses index file versions 2 to 4.
+func readGitIndex(data []byte, idSize int) ([]indexEntry, error) {
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, errors.New("not a git index")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	count := binary.BigEndian.Uint32(data[8:12])
+	fixed := 40 + idSize + 2
+	entries := make([]indexEntry, 0, count)
+	pos := 12
+	prev := ""
+	for i := uint32(0); i < count; i++ {
+		start := pos
+		if pos+fixed > len(data) {
+			return nil, errors.New("truncated index")
+		}
+		e := indexEntry{
+			mtimeSec:  binary.BigEndian.Uint32(data[pos+8:]),
+			mtimeNsec: binary.BigEndian.Uint32(data[pos+12:]),
+			mode:      binary.BigEndian.Uint32(data[pos+24:]),
+			size:      binary.BigEndian.Uint32(data[pos+36:]),
+			id:        data[pos+40 : pos+40+idSize],
+		}
+		flags := binary.BigEndian.Uint16(data[pos+40+idSize:])
+		pos += fixed
+		e.skip = flags&indexAssumeValid != 0
+		if flags&indexExtended != 0 {
+			if version < 3 || pos+2 > len(data) {
+				return nil, errors.New("invalid extended index entry")
+			}
+			extended := binary.BigEndian.Uint16(data[pos:])
+			e.skip = e.skip || extended&(indexSkipWorktree|indexIntentToAdd) != 0
+			pos += 2
+		}
+
+		if version == 4 {
+			strip, n := readIndexVarint(data[pos:])
+			if n == 0 || strip > len(prev) {
+				return nil, errors.New("invalid index path")
+			}
+			pos += n
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, errors.New("truncated index")
+			}
+			e.path = prev[:len(prev)-strip] + string(data[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return nil, errors.New("truncated index")
+			}
+			e.path = string(data[pos : pos+end])
+			// Entries are NUL-padded to a multiple of eight bytes.
+			pos = start + ((pos - start + end + 8) &^ 7)
+		}
+		prev = e.path
+		if flags&indexStageMask == 0 {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// readIndexVarint decodes the offset encoding of index version 4.
+func readIndexVarint(data []byte) (value int, n int) {
+	for n < len(data) {
+		c := data[n]
+		n++
+		value = value<<7 | int(c&0x7f)
+		if c&0x80 == 0 {
+			return value, n
+		}
+		value++
+	}
+	return 0, 0
+}
+
+// File modes of index entries.
+const (
+	modeTypeMask = 0o170000
+	modeSymlink  = 0o120000
+	modeGitlink  = 0o160000
+)
+
+// maxStatusEntries bounds how many directory entries the untracked scan
+// visits, so a notification never waits on a huge unignored tree.
+const maxStatusEntries = 100000
+
+// countChanges compares the working tree with the index like git status
+// does for unstaged changes and untracked files.
+func (r gitRepo) countChanges(info *GitInfo) error {
+	entries, newHash, err := r.readIndex()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.skip && e.mode&modeTypeMask != modeGitlink && r.entryModified(e, newHash) {
+			info.Modified++
+		}
+	}
+	info.Untracked = r.scanUntracked(entries, nil)
+	return nil
+}
+
+// readIndex reads the stage-0 entries of the index and returns them with
+// the hash of the repository's object names. A missing index has no
+// entries.
+func (r gitRepo) readIndex() ([]indexEntry, func() hash.Hash, error) {
+	idSize, newHash := sha1.Size, sha1.New
+	if r.sha256Repo() {
+		idSize, newHash = sha256.Size, sha256.New
+	}
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "index"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, newHash, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	entries, err := readGitIndex(data, idSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, newHash, nil
+}
+
+// scanUntracked counts the untracked entries of the working tree, given
+// the tracked ones, and passes every untracked file to visit when it is
+// not nil.
+func (r gitRepo) scanUntracked(entries []indexEntry, visit func(rel string)) int {
+	tracked := make(map[string]bool, len(entries))
+	trackedDirs := map[string]bool{}
+	for _, e := range entries {
+		tracked[e.path] = true
+		for dir := path.Dir(e.path); dir != "."; dir = path.Dir(dir) {
+			if trackedDirs[dir] {
+				break
+			}
+			trackedDirs[dir] = true
+		}
+	}
+	scan := untrackedScan{root: r.root, tracked: tracked, trackedDirs: trackedDirs, visit: visit}
+	scan.walk("", r.excludeRules(), false)
+	return scan.count
+}
+
+// excludeRules returns the ignore rules that apply to the whole working
+// tree: the global excludes file first, then info/exclude, which wins over
+// it as the rules of .gitignore files win over both.
+func (r gitRepo) excludeRules() []ignoreRule {
+	var rules []ignoreRule
+	if name := r.excludesFile(); name != "" {
+		rules = readIgnoreFile(name, "")
+	}
+	return append(rules, readIgnoreFile(filepath.Join(r.commonDir, "info", "exclude"), "")...)
+}
+
+// excludesFile returns the global excludes file: core.excludesFile of the
+// repository or the user's configuration, or git/ignore in the XDG
+// configuration directory when it is not set. Included configuration files
+// are not followed.
+func (r gitRepo) excludesFile() string {
+	home, _ := os.UserHomeDir()
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" && home != "" {
+		xdg = filepath.Join(home, ".config")
+	}
+	var configs []string
+	if xdg != "" {
+		configs = append(configs, filepath.Join(xdg, "git", "config"))
+	}
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+	// Later files override earlier ones, as in git.
+	name := ""
+	for _, config := range append(configs, filepath.Join(r.commonDir, "config")) {
+		if value, ok := readGitConfigValue(config, "core", "excludesfile"); ok {
+			name = value
+		}
+	}
+	if name == "" {
+		if xdg == "" {
+			return ""
+		}
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	if rest, ok := strings.CutPrefix(name, "~/"); ok && home != "" {
+		name = filepath.Join(home, rest)
+	}
+	return name
+}
+
+// readGitConfigValue returns the last value of key in section of the git
+// configuration file name. Section and key names are case-insensitive;
+// subsections are not supported.
+func readGitConfigValue(name, section, key string) (string, bool) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", false
+	}
+	value, found, current := "", false, ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			if end := strings.IndexByte(line, ']'); end > 0 {
+				current = strings.ToLower(strings.TrimSpace(line[1:end]))
+				line = strings.TrimSpace(line[end+1:])
+			}
+		}
+		if current != section {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(k), key) {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, `"`) {
+			v, _, _ = strings.Cut(v[1:], `"`)
+		} else if i := strings.IndexAny(v, "#;"); i >= 0 {
+			v = strings.TrimSpace(v[:i])
+		}
+		value, found = v, true
+	}
+	return value, found
+}
+
+// entryModified reports whether the working tree file of e differs from
+// the index. Files whose size and modification time match the index are
+// taken as unchanged without reading them, as git does.
+func (r gitRepo) entryModified(e indexEntry, newHash func() hash.Hash) bool {
+	name := filepath.Join(r.root, filepath.FromSlash(e.path))
+	st, err := os.Lstat(name)
+	if err != nil {
+		return true
+	}
+	isLink := e.mode&modeTypeMask == modeSymlink
+	if isLink != (st.Mode()&fs.ModeSymlink != 0) {
+		return true
+	}
+	mtime := st.ModTime()
+	if uint32(st.Size()) == e.size && uint32(mtime.Unix()) == e.mtimeSec && uint32(mtime.Nanosecond()) == e.mtimeNsec {
+		return false
+	}
+
+	var content []byte
+	if isLink {
+		target, err := os.Readlink(name)
+		if err != nil {
+			return true
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else if content, err = os.ReadFile(name); err != nil {
+		return true
+	}
+	if bytes.Equal(blobID(newHash, content), e.id) {
+		return false
+	}
+	// With core.autocrlf the index holds LF line endings.
+	if bytes.Contains(content, []byte("\r\n")) {
+		lf := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+		return !bytes.Equal(blobID(newHash, lf), e.id)
+	}
+	return true
+}
+
+// blobID returns the object name of content stored as a blob.
+func blobID(newHash func() hash.Hash, content []byte) []byte {
+	h := newHash()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return h.Sum(nil)
+}
+
+// untrackedScan counts the files under root that are neither tracked nor
+// ignored.
+type untrackedScan struct {
+	root        string
+	tracked     map[string]bool
+	trackedDirs map[string]bool
+	// visit, when set, receives the path of every untracked file.
+	visit   func(rel string)
+	count   int
+	visited int
+}
+
+// walk scans the directory rel, a slash-separated path below root, with
+// the ignore rules of its parents. Within an ignored directory, entered
+// for the tracked files in it, nothing is untracked.
+func (s *untrackedScan) walk(rel string, rules []ignoreRule, inIgnored bool) {
+	dir := filepath.Join(s.root, filepath.FromSlash(rel))
+	rules = append(rules, readIgnoreFile(filepath.Join(dir, ".gitignore"), rel)...)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if s.visited++; s.visited > maxStatusEntries {
+			return
+		}
+		name := entry.Name()
+		if name == ".git" {
+			continue
+		}
+		child := name
+		if rel != "" {
+			child = rel + "/" + name
+		}
+		isDir := entry.IsDir()
+		if s.tracked[child] {
+			continue
+		}
+		skip := inIgnored || ignored(rules, child, isDir)
+		if isDir && s.trackedDirs[child] {
+			s.walk(child, rules, skip)
+			continue
+		}
+		if skip {
+			continue
+		}
+		if isDir {
+			if _, err := os.Lstat(filepath.Join(dir, name, ".git")); err == nil {
+				// A nested repository git lists as a single untracked entry.
+				s.count++
+				continue
+			}
+			s.walk(child, rules, false)
+			continue
+		}
+		s.count++
+		if s.visit != nil {
+			s.visit(child)
+		}
+	}
+}
+
+// ignoreRule is one pattern of a .gitignore file.
+type ignoreRule struct {
+	// base is the directory of the .gitignore file, relative to the root.
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// readIgnoreFile parses the .gitignore-style file at name, whose patterns
+// are relative to base.
+func readIgnoreFile(name, base string) []ignoreRule {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " ")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate, line = true, line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimPrefix(line, "/")
+		if rule.pattern != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// ignored applies rules to rel, a slash-separated path below the root; the
+// last matching rule decides.
+func ignored(rules []ignoreRule, rel string, isDir bool) bool {
+	result := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		target := rel
+		if rule.base != "" {
+			var ok bool
+			if target, ok = strings.CutPrefix(rel, rule.base+"/"); !ok {
+				continue
+			}
+		}
+		var match bool
+		if rule.anchored {
+			match = matchIgnoreSegments(strings.Split(rule.pattern, "/"), strings.Split(target, "/"))
+		} else {
+			match, _ = path.Match(rule.pattern, path.Base(target))
+		}
+		if match {
+			result = !rule.negate
+		}
+	}
+	return result
+}
+
+// matchIgnoreSegments matches path segments against pattern segments,
+// where "**" stands for any number of segments.
+func matchIgnoreSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchIgnoreSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
+
+// gitLine returns the Git line of a notification body for the enabled
+// fields, or "" when there is nothing to show.
+func gitLine(info *GitInfo, opts RenderOptions) string {
+	if info == nil {
+		return ""
+	}
+	lang := opts.Language
+	var parts []string
+	switch {
+	case opts.IncludeGitBranch && opts.IncludeGitHead && info.Branch != "" && info.Head != "":
+		parts = append(parts, info.Branch+"@"+info.Head)
+	case opts.IncludeGitBranch && info.Branch != "":
+		parts = append(parts, info.Branch)
+	case opts.IncludeGitHead && info.Head != "":
+		parts = append(parts, info.Head)
+	}
+	if opts.IncludeGitWorktree && info.Worktree != "" {
+		parts = append(parts, lang.Sprintf("worktree %s", info.Worktree))
+	}
+	if opts.IncludeGitStatus {
+		switch {
+		case info.Modified == 0 && info.Untracked == 0:
+			parts = append(parts, lang.T("clean"))
+		default:
+			if info.Modified > 0 {
+				parts = append(parts, lang.Sprintf("%d modified", info.Modified))
+			}
+			if info.Untracked > 0 {
+				parts = append(parts, lang.Sprintf("%d untracked", info.Untracked))
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return lang.Sprintf("Git: %s", strings.Join(parts, " · "))
+}
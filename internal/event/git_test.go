@@ -0,0 +1,197 @@
+package event
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// gitCmd runs git in dir, skipping the test when git is not installed. Git
+// is only used to build repositories; cc-notify reads them on its own.
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "core.autocrlf=false", "-c", "init.defaultBranch=main"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL="+os.DevNull)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestReadGitInfo_CountsChanges(t *testing.T) {
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	writeTestFile(t, filepath.Join(repo, ".gitignore"), "*.log\n/build/\n!keep.log\n")
+	writeTestFile(t, filepath.Join(repo, "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(repo, "README.md"), "# demo\n")
+	writeTestFile(t, filepath.Join(repo, "docs", "guide.md"), "guide\n")
+	writeTestFile(t, filepath.Join(repo, "vendor", ".gitignore"), "*.tmp\n")
+	writeTestFile(t, filepath.Join(repo, "vendor", "lib.go"), "package lib\n")
+	writeTestFile(t, filepath.Join(repo, "build", "tracked.txt"), "forced\n")
+	gitCmd(t, repo, "add", ".")
+	gitCmd(t, repo, "add", "-f", "build/tracked.txt")
+	gitCmd(t, repo, "commit", "-q", "-m", "init")
+	head := gitCmd(t, repo, "rev-parse", "--short=7", "HEAD")
+
+	// Modified: main.go edited, README.md deleted. docs/guide.md is
+	// rewritten with the same content and a new time, which is not a change.
+	writeTestFile(t, filepath.Join(repo, "main.go"), "package main\n\nfunc main() {}\n")
+	if err := os.Remove(filepath.Join(repo, "README.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	writeTestFile(t, filepath.Join(repo, "docs", "guide.md"), "guide\n")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(repo, "docs", "guide.md"), later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	// Untracked: notes.txt, docs/new.md, keep.log and a file in a new
+	// directory. Ignored: debug.log, vendor/x.tmp, build/out.bin.
+	for _, name := range []string{"notes.txt", "docs/new.md", "keep.log", "scratch/a/b.txt", "debug.log", "vendor/x.tmp", "build/out.bin"} {
+		writeTestFile(t, filepath.Join(repo, filepath.FromSlash(name)), "x\n")
+	}
+
+	want := GitInfo{Branch: "main", Head: head, Modified: 2, Untracked: 4}
+	for _, version := range []string{"2", "4"} {
+		gitCmd(t, repo, "update-index", "--index-version", version)
+		info, err := ReadGitInfo(filepath.Join(repo, "docs"), true)
+		if err != nil {
+			t.Fatalf("index v%s: unexpected error: %v", version, err)
+		}
+		if info == nil || *info != want {
+			t.Fatalf("index v%s:\n got %+v\nwant %+v", version, info, want)
+		}
+	}
+
+	// Refs packed away still resolve, and a linked worktree has a name.
+	gitCmd(t, repo, "pack-refs", "--all")
+	worktree := filepath.Join(t.TempDir(), "wt")
+	gitCmd(t, repo, "worktree", "add", "-q", "-b", "feature/toasts", worktree)
+	info, err := ReadGitInfo(worktree, true)
+	if err != nil {
+		t.Fatalf("worktree: unexpected error: %v", err)
+	}
+	if want := (GitInfo{Branch: "feature/toasts", Head: head, Worktree: "wt"}); info == nil || *info != want {
+		t.Fatalf("worktree:\n got %+v\nwant %+v", info, want)
+	}
+	if info, _ := ReadGitInfo(repo, false); info == nil || info.Head != head {
+		t.Fatalf("expected packed ref to resolve, got %+v", info)
+	}
+}
+
+func TestReadGitInfo_OutsideRepository(t *testing.T) {
+	info, err := ReadGitInfo(t.TempDir(), true)
+	if info != nil || err != nil {
+		t.Fatalf("expected nothing outside a repository, got %+v, %v", info, err)
+	}
+}
+
+func TestIgnored_Patterns(t *testing.T) {
+	rules := []ignoreRule{
+		{pattern: "*.log"},
+		{pattern: "keep.log", negate: true},
+		{pattern: "build", dirOnly: true, anchored: true},
+		{pattern: "docs/**/draft-*.md", anchored: true},
+		{base: "web", pattern: "dist", dirOnly: true},
+	}
+	cases := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{rel: "debug.log", want: true},
+		{rel: "a/b/trace.log", want: true},
+		{rel: "keep.log", want: false},
+		{rel: "build", isDir: true, want: true},
+		{rel: "build", want: false},
+		{rel: "src/build", isDir: true, want: false},
+		{rel: "docs/draft-1.md", want: true},
+		{rel: "docs/x/y/draft-2.md", want: true},
+		{rel: "docs/final.md", want: false},
+		{rel: "notes/draft-1.md", want: false},
+		{rel: "web/dist", isDir: true, want: true},
+		{rel: "web/app/dist", isDir: true, want: true},
+		{rel: "dist", isDir: true, want: false},
+	}
+	for _, tc := range cases {
+		if got := ignored(rules, tc.rel, tc.isDir); got != tc.want {
+			t.Fatalf("ignored(%q, dir=%v) = %v, want %v", tc.rel, tc.isDir, got, tc.want)
+		}
+	}
+}
+
+func TestRenderNotificationWithOptions_GitLine(t *testing.T) {
+	payload := Payload{
+		Type:    "agent-turn-complete",
+		Summary: "done",
+		Git:     &GitInfo{Branch: "main", Head: "a1b2c3d", Worktree: "wt-2", Modified: 3},
+	}
+	opts := RenderOptions{IncludeGitBranch: true, IncludeGitHead: true, IncludeGitStatus: true, IncludeGitWorktree: true}
+	_, body, _ := RenderNotificationWithOptions(payload, opts)
+	if body != "done\nGit: main@a1b2c3d · worktree wt-2 · 3 modified" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	payload.Git = &GitInfo{Head: "a1b2c3d"}
+	_, body, _ = RenderNotificationWithOptions(payload, opts)
+	if body != "done\nGit: a1b2c3d · clean" {
+		t.Fatalf("unexpected detached body: %q", body)
+	}
+
+	tmpl := mustParseTemplate(t, `{{.GitBranch}} +{{.GitModified}} ?{{.GitUntracked}} {{.GitWorktree}}`)
+	payload.Git = &GitInfo{Branch: "main", Modified: 1, Untracked: 2, Worktree: "wt"}
+	_, body, _ = RenderNotificationWithOptions(payload, RenderOptions{BodyTemplate: tmpl})
+	if body != "main +1 ?2 wt" {
+		t.Fatalf("unexpected template body: %q", body)
+	}
+}
+
+func TestReadGitInfo_HonoursGlobalExcludes(t *testing.T) {
+	home, xdg := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	repo := t.TempDir()
+	gitCmd(t, repo, "init", "-q")
+	for _, name := range []string{"main.go", "a.swp", "b.orig", "c.bak"} {
+		writeTestFile(t, filepath.Join(repo, name), "x\n")
+	}
+
+	// Without core.excludesFile the XDG default applies.
+	writeTestFile(t, filepath.Join(xdg, "git", "ignore"), "*.swp\n")
+	if info, _ := ReadGitInfo(repo, true); info == nil || info.Untracked != 3 {
+		t.Fatalf("expected the XDG ignore file to apply, got %+v", info)
+	}
+
+	// core.excludesFile replaces it, and the repository setting wins over
+	// the global one.
+	writeTestFile(t, filepath.Join(home, ".gitconfig"), "[core]\n\texcludesFile = ~/global-ignore ; comment\n")
+	writeTestFile(t, filepath.Join(home, "global-ignore"), "*.orig\n")
+	if info, _ := ReadGitInfo(repo, true); info == nil || info.Untracked != 3 {
+		t.Fatalf("expected the global excludes file to apply, got %+v", info)
+	}
+	writeTestFile(t, filepath.Join(repo, "repo-ignore"), "*.bak\nrepo-ignore\n")
+	gitCmd(t, repo, "config", "core.excludesFile", filepath.Join(repo, "repo-ignore"))
+	if info, _ := ReadGitInfo(repo, true); info == nil || info.Untracked != 3 {
+		t.Fatalf("expected the repository excludes file to apply, got %+v", info)
+	}
+	if got := gitCmd(t, repo, "status", "--porcelain", "--untracked-files=all"); strings.Count(got, "\n")+1 != 3 {
+		t.Fatalf("git disagrees with the expected count:\n%s", got)
+	}
+}
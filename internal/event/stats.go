@@ -0,0 +1,326 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TokenUsage counts the tokens of a turn. Input excludes cached input,
+// which is counted as CacheRead.
+type TokenUsage struct {
+	Input      int64
+	Output     int64
+	CacheRead  int64
+	CacheWrite int64
+}
+
+// Total returns every token of the usage.
+func (u TokenUsage) Total() int64 {
+	return u.Input + u.Output + u.CacheRead + u.CacheWrite
+}
+
+// TurnStats describes the latest turn of a session.
+type TurnStats struct {
+	// Duration is the wall-clock time from the prompt to the last entry.
+	Duration time.Duration
+	Tokens   TokenUsage
+	// ToolCalls counts every tool call; Commands the shell commands among
+	// them.
+	ToolCalls int
+	Commands  int
+	// Model is the model that answered last.
+	Model string
+	// Cost is the estimated cost in USD, zero when the model has no price.
+	Cost float64
+}
+
+// ModelPrice is the USD price per million tokens of a model. Cached input
+// without a price of its own is charged as input.
+type ModelPrice struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheRead  float64 `json:"cache_read,omitempty"`
+	CacheWrite float64 `json:"cache_write,omitempty"`
+}
+
+// PriceTable maps model names to prices. A key also matches model names it
+// is a prefix of, so "claude-sonnet-4-5" covers dated releases; the longest
+// matching key wins.
+type PriceTable map[string]ModelPrice
+
+// Cost estimates what usage cost with model. ok is false when the table has
+// no price for model.
+func (t PriceTable) Cost(model string, usage TokenUsage) (cost float64, ok bool) {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return 0, false
+	}
+	var price ModelPrice
+	best := -1
+	for key, candidate := range t {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "" && strings.HasPrefix(model, key) && len(key) > best {
+			price, best = candidate, len(key)
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	cacheRead, cacheWrite := price.CacheRead, price.CacheWrite
+	if cacheRead == 0 {
+		cacheRead = price.Input
+	}
+	if cacheWrite == 0 {
+		cacheWrite = price.Input
+	}
+	cost = float64(usage.Input)*price.Input +
+		float64(usage.Output)*price.Output +
+		float64(usage.CacheRead)*cacheRead +
+		float64(usage.CacheWrite)*cacheWrite
+	return cost / 1e6, true
+}
+
+// statsEntry is one line of a Claude Code transcript or a Codex rollout
+// file; only the fields of the matching format are set.
+type statsEntry struct {
+	Timestamp   string `json:"timestamp"`
+	Type        string `json:"type"`
+	IsSidechain bool   `json:"isSidechain"`
+	IsMeta      bool   `json:"isMeta"`
+	// Claude Code.
+	Message *struct {
+		ID      string          `json:"id"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+		Usage   *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	// Codex.
+	Payload *struct {
+		Type  string `json:"type"`
+		Name  string `json:"name"`
+		Model string `json:"model"`
+		Info  *struct {
+			Total codexTokenUsage `json:"total_token_usage"`
+		} `json:"info"`
+	} `json:"payload"`
+}
+
+type codexTokenUsage struct {
+	InputTokens       int64 `json:"input_tokens"`
+	CachedInputTokens int64 `json:"cached_input_tokens"`
+	OutputTokens      int64 `json:"output_tokens"`
+}
+
+type claudeToolUse struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// codexCommandTools are the Codex tools that run shell commands.
+var codexCommandTools = map[string]bool{
+	"shell":          true,
+	"container.exec": true,
+	"exec_command":   true,
+	"shell_command":  true,
+}
+
+// ReadTurnStats reads the statistics of the latest turn from a Claude Code
+// transcript or a Codex rollout file, reading backwards from the end.
+func ReadTurnStats(path string) (TurnStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TurnStats{}, fmt.Errorf("read turn stats: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return TurnStats{}, fmt.Errorf("read turn stats: %w", err)
+	}
+
+	var (
+		stats      TurnStats
+		end, start time.Time
+		seen       = map[string]bool{}
+		// Codex reports running totals; the turn used the difference
+		// between the last total and the one before its prompt.
+		codexTotal *codexTokenUsage
+		prompted   bool
+	)
+	err = scanLinesBackward(f, info.Size(), func(line []byte) bool {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			return true
+		}
+		var entry statsEntry
+		if json.Unmarshal(line, &entry) != nil {
+			return true
+		}
+		at, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+
+		if entry.Payload != nil {
+			p := entry.Payload
+			if prompted {
+				// Past the prompt only the total before the turn is
+				// still needed.
+				if p.Type == "token_count" && p.Info != nil {
+					stats.Tokens = codexTurnUsage(codexTotal, &p.Info.Total)
+					return false
+				}
+				return true
+			}
+			if end.IsZero() {
+				end = at
+			}
+			switch {
+			case entry.Type == "turn_context" && stats.Model == "":
+				stats.Model = strings.TrimSpace(p.Model)
+			case p.Type == "token_count" && p.Info != nil && codexTotal == nil:
+				total := p.Info.Total
+				codexTotal = &total
+			case p.Type == "function_call" || p.Type == "custom_tool_call" || p.Type == "local_shell_call":
+				stats.ToolCalls++
+				if p.Type == "local_shell_call" || codexCommandTools[p.Name] {
+					stats.Commands++
+				}
+			case p.Type == "user_message":
+				start, prompted = at, true
+				stats.Tokens = codexTurnUsage(codexTotal, nil)
+			}
+			return true
+		}
+
+		if entry.Message == nil || entry.IsMeta {
+			return true
+		}
+		if end.IsZero() {
+			end = at
+		}
+		msg := entry.Message
+		switch entry.Type {
+		case "assistant":
+			if stats.Model == "" && !entry.IsSidechain {
+				stats.Model = strings.TrimSpace(msg.Model)
+			}
+			// Each content block of a reply is its own line repeating
+			// the usage of the whole reply.
+			if msg.Usage != nil && (msg.ID == "" || !seen[msg.ID]) {
+				seen[msg.ID] = true
+				stats.Tokens.Input += msg.Usage.InputTokens
+				stats.Tokens.Output += msg.Usage.OutputTokens
+				stats.Tokens.CacheRead += msg.Usage.CacheReadInputTokens
+				stats.Tokens.CacheWrite += msg.Usage.CacheCreationInputTokens
+			}
+			var blocks []claudeToolUse
+			if json.Unmarshal(msg.Content, &blocks) == nil {
+				for _, block := range blocks {
+					if block.Type == "tool_use" {
+						stats.ToolCalls++
+						if block.Name == "Bash" {
+							stats.Commands++
+						}
+					}
+				}
+			}
+		case "user":
+			if !entry.IsSidechain && claudeUserPrompt(msg.Content) != "" {
+				start = at
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return TurnStats{}, fmt.Errorf("read turn stats: %w", err)
+	}
+	if !start.IsZero() && end.After(start) {
+		stats.Duration = end.Sub(start)
+	}
+	return stats, nil
+}
+
+// codexTurnUsage returns the usage between the running totals before and
+// after a turn. A nil before means the turn was the first.
+func codexTurnUsage(after, before *codexTokenUsage) TokenUsage {
+	if after == nil {
+		return TokenUsage{}
+	}
+	diff := *after
+	if before != nil {
+		diff.InputTokens -= before.InputTokens
+		diff.CachedInputTokens -= before.CachedInputTokens
+		diff.OutputTokens -= before.OutputTokens
+	}
+	return TokenUsage{
+		Input:     diff.InputTokens - diff.CachedInputTokens,
+		CacheRead: diff.CachedInputTokens,
+		Output:    diff.OutputTokens,
+	}
+}
+
+// statsLine renders the parts of stats selected in opts, e.g.
+// "Done in 7m12s · 48k tokens · 23 commands".
+func statsLine(stats *TurnStats, opts RenderOptions) string {
+	if stats == nil {
+		return ""
+	}
+	lang := opts.Language
+	var parts []string
+	if opts.IncludeDuration && stats.Duration > 0 {
+		parts = append(parts, lang.Sprintf("Done in %s", formatDuration(stats.Duration)))
+	}
+	if opts.IncludeTokens && stats.Tokens.Total() > 0 {
+		parts = append(parts, lang.Sprintf("%s tokens", formatCount(stats.Tokens.Total())))
+	}
+	if opts.IncludeCost && stats.Cost > 0 {
+		if stats.Cost < 0.01 {
+			parts = append(parts, "<$0.01")
+		} else {
+			parts = append(parts, fmt.Sprintf("$%.2f", stats.Cost))
+		}
+	}
+	if opts.IncludeTools && stats.ToolCalls > 0 {
+		commands := lang.Plural(stats.Commands, "1 command", "%d commands")
+		toolCalls := lang.Plural(stats.ToolCalls, "1 tool call", "%d tool calls")
+		switch {
+		case stats.Commands == stats.ToolCalls:
+			parts = append(parts, commands)
+		case stats.Commands > 0:
+			parts = append(parts, fmt.Sprintf("%s (%s)", toolCalls, commands))
+		default:
+			parts = append(parts, toolCalls)
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d/time.Minute), int(d%time.Minute/time.Second))
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+}
+
+func formatCount(n int64) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 999500:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	default:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1e6), ".0") + "M"
+	}
+}
@@ -0,0 +1,122 @@
+package event
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTranscript(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(transcriptLines(lines...)), 0o644); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+	return path
+}
+
+func TestReadTurnStats_Claude(t *testing.T) {
+	path := writeTranscript(t,
+		`{"type":"user","timestamp":"2025-10-01T09:00:00Z","message":{"role":"user","content":"an older prompt"}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T09:01:00Z","message":{"id":"m0","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","name":"Bash"}],"usage":{"input_tokens":999,"output_tokens":999}}}`,
+		`{"type":"user","timestamp":"2025-10-01T10:00:00.000Z","message":{"role":"user","content":"fix the flaky test"}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:00:05.000Z","message":{"id":"m1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Running tests."}],"usage":{"input_tokens":10,"cache_creation_input_tokens":2000,"cache_read_input_tokens":30000,"output_tokens":40}}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:00:06.000Z","message":{"id":"m1","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}],"usage":{"input_tokens":10,"cache_creation_input_tokens":2000,"cache_read_input_tokens":30000,"output_tokens":40}}}`,
+		`{"type":"user","timestamp":"2025-10-01T10:03:00.000Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:03:10.000Z","message":{"id":"m2","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{}}],"usage":{"input_tokens":5,"cache_read_input_tokens":32000,"output_tokens":500}}}`,
+		`{"type":"assistant","timestamp":"2025-10-01T10:07:12.000Z","message":{"id":"m3","model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Done."}],"usage":{"input_tokens":5,"cache_read_input_tokens":33000,"output_tokens":60}}}`,
+	)
+
+	stats, err := ReadTurnStats(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := TurnStats{
+		Duration:  7*time.Minute + 12*time.Second,
+		Tokens:    TokenUsage{Input: 20, Output: 600, CacheRead: 95000, CacheWrite: 2000},
+		ToolCalls: 2,
+		Commands:  1,
+		Model:     "claude-sonnet-4-5",
+	}
+	if stats != want {
+		t.Fatalf("got %+v\nwant %+v", stats, want)
+	}
+}
+
+func TestReadTurnStats_CodexRollout(t *testing.T) {
+	path := writeTranscript(t,
+		`{"timestamp":"2025-10-01T09:00:00.000Z","type":"session_meta","payload":{"id":"abc","cwd":"/work/api"}}`,
+		`{"timestamp":"2025-10-01T09:00:01.000Z","type":"event_msg","payload":{"type":"user_message","message":"first"}}`,
+		`{"timestamp":"2025-10-01T09:00:30.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":5000,"cached_input_tokens":1000,"output_tokens":300,"total_tokens":5300}}}}`,
+		`{"timestamp":"2025-10-01T10:00:00.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"fix it"}]}}`,
+		`{"timestamp":"2025-10-01T10:00:00.000Z","type":"event_msg","payload":{"type":"user_message","message":"fix it"}}`,
+		`{"timestamp":"2025-10-01T10:00:01.000Z","type":"turn_context","payload":{"cwd":"/work/api","model":"gpt-5-codex"}}`,
+		`{"timestamp":"2025-10-01T10:00:02.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{}","call_id":"c1"}}`,
+		`{"timestamp":"2025-10-01T10:00:03.000Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"","call_id":"c2"}}`,
+		`{"timestamp":"2025-10-01T10:00:04.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{}","call_id":"c3"}}`,
+		`{"timestamp":"2025-10-01T10:00:40.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":45000,"cached_input_tokens":21000,"output_tokens":4300,"total_tokens":49300}}}}`,
+		`{"timestamp":"2025-10-01T10:00:45.000Z","type":"event_msg","payload":{"type":"agent_message","message":"Done."}}`,
+	)
+
+	stats, err := ReadTurnStats(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := TurnStats{
+		Duration:  45 * time.Second,
+		Tokens:    TokenUsage{Input: 20000, CacheRead: 20000, Output: 4000},
+		ToolCalls: 3,
+		Commands:  2,
+		Model:     "gpt-5-codex",
+	}
+	if stats != want {
+		t.Fatalf("got %+v\nwant %+v", stats, want)
+	}
+}
+
+func TestPriceTable_Cost(t *testing.T) {
+	prices := PriceTable{
+		"claude-sonnet":     {Input: 100, Output: 100},
+		"claude-sonnet-4-5": {Input: 3, Output: 15, CacheRead: 0.3},
+	}
+	cost, ok := prices.Cost("Claude-Sonnet-4-5-20250929", TokenUsage{Input: 1e6, Output: 1e6, CacheRead: 1e6, CacheWrite: 1e6})
+	if !ok || math.Abs(cost-(3+15+0.3+3)) > 1e-9 {
+		t.Fatalf("unexpected cost %v, %v", cost, ok)
+	}
+	if _, ok := prices.Cost("gpt-5", TokenUsage{Input: 1}); ok {
+		t.Fatalf("expected no price for gpt-5")
+	}
+}
+
+func TestRenderNotificationWithOptions_TurnStats(t *testing.T) {
+	payload := Payload{
+		Type:    "agent-turn-complete",
+		Summary: "done",
+		Stats: &TurnStats{
+			Duration:  7*time.Minute + 12*time.Second,
+			Tokens:    TokenUsage{Input: 20000, CacheRead: 24000, Output: 4000},
+			ToolCalls: 23,
+			Commands:  23,
+			Cost:      0.4199,
+		},
+	}
+	_, body, _ := RenderNotificationWithOptions(payload, RenderOptions{IncludeDuration: true, IncludeTokens: true, IncludeTools: true})
+	if body != "done\nDone in 7m12s · 48k tokens · 23 commands" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	payload.Stats.Commands = 1
+	payload.Stats.Duration = 2*time.Hour + 5*time.Minute
+	_, body, _ = RenderNotificationWithOptions(payload, RenderOptions{IncludeDuration: true, IncludeCost: true, IncludeTools: true})
+	if body != "done\nDone in 2h05m · $0.42 · 23 tool calls (1 command)" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	_, body, _ = RenderNotificationWithOptions(payload, RenderOptions{})
+	if strings.Contains(body, "Done in") {
+		t.Fatalf("stats must be opt-in, got %q", body)
+	}
+}
@@ -0,0 +1,227 @@
+package event
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// TemplateData is what title and body templates render. Every payload
+// field is available, e.g. {{.Summary}} or {{.CWD}}, next to the source
+// and the text cc-notify would show by default.
+type TemplateData struct {
+	Payload
+	// Source is the tool that sent the event, e.g. codex or claude.
+	Source string
+	// Title is the default title of the event.
+	Title string
+	// Text is the message picked by the content mode.
+	Text string
+	// Git context of the repository at CWD, empty when it was not read;
+	// see GitInfo.
+	GitBranch    string
+	GitHead      string
+	GitWorktree  string
+	GitModified  int
+	GitUntracked int
+	// Changes made during the turn, zero when no snapshot was taken; see
+	// ChangeStats.
+	ChangedFiles int
+	LinesAdded   int
+	LinesDeleted int
+}
+
+// newTemplateData returns the data templates render for payload.
+func newTemplateData(payload Payload, source, title, text string) TemplateData {
+	data := TemplateData{Payload: payload, Source: source, Title: title, Text: text}
+	if git := payload.Git; git != nil {
+		data.GitBranch = git.Branch
+		data.GitHead = git.Head
+		data.GitWorktree = git.Worktree
+		data.GitModified = git.Modified
+		data.GitUntracked = git.Untracked
+	}
+	if changes := payload.Changes; changes != nil {
+		data.ChangedFiles = changes.Files
+		data.LinesAdded = changes.Added
+		data.LinesDeleted = changes.Deleted
+	}
+	return data
+}
+
+// gitTemplateFields and gitStatusTemplateFields are the fields of
+// TemplateData that need Payload.Git, and its status, to be read.
+var (
+	gitTemplateFields       = []string{"Git", "GitBranch", "GitHead", "GitWorktree", "GitModified", "GitUntracked"}
+	gitStatusTemplateFields = []string{"Git", "GitModified", "GitUntracked"}
+)
+
+// templateUses reports whether tmpl, or a template it defines, refers to
+// a field with one of names anywhere in a field chain, e.g. .Git.Modified
+// for "Git".
+func templateUses(tmpl *template.Template, names ...string) bool {
+	if tmpl == nil {
+		return false
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && nodeUses(t.Tree.Root, names) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeUses(node parse.Node, names []string) bool {
+	var idents []string
+	switch n := node.(type) {
+	case nil:
+		return false
+	case *parse.FieldNode:
+		idents = n.Ident
+	case *parse.VariableNode:
+		idents = n.Ident
+	case *parse.ChainNode:
+		if nodeUses(n.Node, names) {
+			return true
+		}
+		idents = n.Field
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if nodeUses(child, names) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return nodeUses(n.Pipe, names)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if nodeUses(cmd, names) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if nodeUses(arg, names) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return nodeUses(&n.BranchNode, names)
+	case *parse.RangeNode:
+		return nodeUses(&n.BranchNode, names)
+	case *parse.WithNode:
+		return nodeUses(&n.BranchNode, names)
+	case *parse.BranchNode:
+		return nodeUses(n.Pipe, names) || nodeUses(n.List, names) || nodeUses(n.ElseList, names)
+	case *parse.TemplateNode:
+		return nodeUses(n.Pipe, names)
+	}
+	for _, ident := range idents {
+		for _, name := range names {
+			if ident == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// templateFuncs are the helpers available in templates.
+var templateFuncs = template.FuncMap{
+	"basename":      basename,
+	"truncate":      truncate,
+	"firstBacktick": firstBacktick,
+	"gitBranch":     gitBranch,
+}
+
+// ParseTemplate parses a title or body template with the cc-notify helpers.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+// CheckTemplate renders tmpl with sample data to catch what parsing does
+// not, such as unknown fields or helpers called with the wrong arguments.
+func CheckTemplate(tmpl *template.Template) error {
+	_, err := executeTemplate(tmpl, newTemplateData(
+		Payload{
+			Type:                 "agent-turn-complete",
+			Summary:              "Sample summary",
+			LastAssistantMessage: "Ran `go test ./...` and fixed the flaky test.",
+			CWD:                  ".",
+			Model:                "gpt-5",
+			TranscriptPath:       "session.jsonl",
+			SessionID:            "sample",
+			LastUserPrompt:       "fix the flaky test",
+			Stats:                &TurnStats{Duration: time.Minute, ToolCalls: 1, Commands: 1},
+			Git:                  &GitInfo{Branch: "main", Head: "a1b2c3d", Modified: 2, Untracked: 1},
+			Changes:              &ChangeStats{Files: 2, Added: 12, Deleted: 3},
+		},
+		"codex",
+		"Codex Task Complete",
+		"Sample summary",
+	))
+	return err
+}
+
+func executeTemplate(tmpl *template.Template, data TemplateData) (string, error) {
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// basename returns the last element of a Windows or POSIX path.
+func basename(path string) string {
+	path = strings.TrimRight(strings.TrimSpace(path), `/\`)
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// truncate shortens text to at most limit runes, marking the cut with an
+// ellipsis.
+func truncate(limit int, text string) string {
+	runes := []rune(text)
+	if limit <= 0 || len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// firstBacktick returns the first `quoted` span of text, often the command
+// or file an agent message is about.
+func firstBacktick(text string) string {
+	start := strings.IndexByte(text, '`')
+	if start < 0 {
+		return ""
+	}
+	rest := strings.TrimLeft(text[start:], "`")
+	end := strings.IndexByte(rest, '`')
+	if end < 0 {
+		return ""
+	}
+	return strings.TrimSpace(rest[:end])
+}
+
+// renderTemplate renders tmpl, falling back to def when it fails or
+// renders nothing.
+func renderTemplate(tmpl *template.Template, data TemplateData, def string) string {
+	if tmpl == nil {
+		return def
+	}
+	text, err := executeTemplate(tmpl, data)
+	if err != nil || text == "" {
+		return def
+	}
+	return text
+}
@@ -0,0 +1,127 @@
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func mustParseTemplate(t *testing.T, text string) *template.Template {
+	t.Helper()
+	tmpl, err := ParseTemplate("test", text)
+	if err != nil {
+		t.Fatalf("parse %q: %v", text, err)
+	}
+	return tmpl
+}
+
+func TestRenderNotificationWithOptions_Templates(t *testing.T) {
+	payload := Payload{
+		Type:                 "agent-turn-complete",
+		Summary:              "done",
+		LastAssistantMessage: "Ran `go test ./...` and everything passes now.",
+		CWD:                  `C:\code\demo`,
+		Model:                "gpt-5",
+	}
+	title, body, ok := RenderNotificationWithOptions(payload, RenderOptions{
+		ContentMode:   ContentModeFull,
+		Source:        "codex",
+		TitleTemplate: mustParseTemplate(t, `{{.Source}} · {{basename .CWD}}`),
+		BodyTemplate:  mustParseTemplate(t, `{{firstBacktick .Text}} — {{truncate 12 .LastAssistantMessage}} ({{.Title}})`),
+	})
+	if !ok {
+		t.Fatalf("expected event to render")
+	}
+	if title != "codex · demo" {
+		t.Fatalf("unexpected title: %q", title)
+	}
+	if body != "go test ./... — Ran `go tes… (Codex Task Complete)" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+func TestRenderNotificationWithOptions_TemplateFallsBack(t *testing.T) {
+	payload := Payload{Type: "agent-turn-complete", Summary: "done"}
+	title, body, _ := RenderNotificationWithOptions(payload, RenderOptions{
+		// Stats is nil, so .Stats.Commands fails at render time.
+		TitleTemplate: mustParseTemplate(t, `{{.Stats.Commands}} commands`),
+		BodyTemplate:  mustParseTemplate(t, `{{if .Model}}{{.Model}}{{end}}`),
+	})
+	if title != "Codex Task Complete" || body != "done" {
+		t.Fatalf("expected defaults, got %q / %q", title, body)
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	if err := CheckTemplate(mustParseTemplate(t, `{{.Summary}} {{with .Stats}}{{.Commands}}{{end}} {{gitBranch .CWD}}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := CheckTemplate(mustParseTemplate(t, `{{.Sumary}}`))
+	if err == nil || !strings.Contains(err.Error(), "Sumary") {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+	if _, err := ParseTemplate("body", `{{shout .Summary}}`); err == nil || !strings.Contains(err.Error(), `"shout" not defined`) {
+		t.Fatalf("expected unknown function error, got %v", err)
+	}
+}
+
+func TestGitBranch(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/feature/toasts\n"), 0o644); err != nil {
+		t.Fatalf("write HEAD: %v", err)
+	}
+	sub := filepath.Join(repo, "internal", "app")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if got := gitBranch(sub); got != "feature/toasts" {
+		t.Fatalf("unexpected branch: %q", got)
+	}
+
+	// A worktree has a .git file pointing at its git directory.
+	worktree := t.TempDir()
+	gitDir := filepath.Join(repo, ".git", "worktrees", "wt")
+	if err := os.MkdirAll(gitDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("0123456789abcdef0123456789abcdef01234567\n"), 0o644); err != nil {
+		t.Fatalf("write HEAD: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+gitDir+"\n"), 0o644); err != nil {
+		t.Fatalf("write .git: %v", err)
+	}
+	if got := gitBranch(worktree); got != "0123456" {
+		t.Fatalf("expected short commit for detached HEAD, got %q", got)
+	}
+}
+
+func TestRenderOptions_NeedsGitStatus(t *testing.T) {
+	cases := []struct {
+		text           string
+		git, gitStatus bool
+	}{
+		{`{{.Summary}} {{gitBranch .CWD}}`, false, false},
+		{`{{.GitBranch}}`, true, false},
+		{`{{if .GitModified}}dirty{{end}}`, true, true},
+		{`{{with .Git}}{{.Untracked}}{{end}}`, true, true},
+		{`{{define "s"}}{{$.GitUntracked}}{{end}}{{template "s" .}}`, true, true},
+		{`{{range .Summary}}{{else}}{{.Payload.Git.Head}}{{end}}`, true, true},
+	}
+	for _, tc := range cases {
+		opts := RenderOptions{BodyTemplate: mustParseTemplate(t, tc.text)}
+		if got := opts.NeedsGit(); got != tc.git {
+			t.Fatalf("NeedsGit for %q = %v, want %v", tc.text, got, tc.git)
+		}
+		if got := opts.NeedsGitStatus(); got != tc.gitStatus {
+			t.Fatalf("NeedsGitStatus for %q = %v, want %v", tc.text, got, tc.gitStatus)
+		}
+	}
+	if !(RenderOptions{IncludeGitStatus: true}).NeedsGitStatus() {
+		t.Fatalf("expected IncludeGitStatus to need the status")
+	}
+}
@@ -0,0 +1,206 @@
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// transcriptChunkSize is how much of a transcript is read per step.
+	transcriptChunkSize = 64 << 10
+	// maxTranscriptLine bounds a single transcript entry; longer entries,
+	// such as huge tool results, are skipped.
+	maxTranscriptLine = 8 << 20
+	// maxTranscriptScan bounds how far back from the end a transcript is
+	// searched.
+	maxTranscriptScan = 64 << 20
+)
+
+// ClaudeTranscript holds what cc-notify takes from a Claude Code session
+// transcript about its latest turn.
+type ClaudeTranscript struct {
+	// LastAssistantMessage is the text Claude wrote after the last prompt.
+	LastAssistantMessage string
+	// LastUserPrompt is the last prompt the user typed.
+	LastUserPrompt string
+	// Model is the model that wrote LastAssistantMessage.
+	Model string
+}
+
+// claudeTranscriptEntry is one line of a Claude Code transcript JSONL file.
+type claudeTranscriptEntry struct {
+	Type        string `json:"type"`
+	IsSidechain bool   `json:"isSidechain"`
+	IsMeta      bool   `json:"isMeta"`
+	Message     struct {
+		Role    string          `json:"role"`
+		Model   string          `json:"model"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+type claudeContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ReadClaudeTranscript reads the Claude Code transcript at path from the end
+// and returns the latest turn: the last user prompt and the assistant text
+// written after it. Lines that do not parse, such as a partially written
+// last line, are skipped.
+func ReadClaudeTranscript(path string) (ClaudeTranscript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ClaudeTranscript{}, fmt.Errorf("read claude transcript: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return ClaudeTranscript{}, fmt.Errorf("read claude transcript: %w", err)
+	}
+	transcript, err := readClaudeTranscript(f, info.Size())
+	if err != nil {
+		return ClaudeTranscript{}, fmt.Errorf("read claude transcript: %w", err)
+	}
+	return transcript, nil
+}
+
+func readClaudeTranscript(r io.ReaderAt, size int64) (ClaudeTranscript, error) {
+	var result ClaudeTranscript
+	var assistant []string
+	err := scanLinesBackward(r, size, func(line []byte) bool {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			return true
+		}
+		var entry claudeTranscriptEntry
+		if json.Unmarshal(line, &entry) != nil || entry.IsSidechain || entry.IsMeta {
+			return true
+		}
+		switch entry.Type {
+		case "assistant":
+			// Claude Code writes each content block of a reply as its own
+			// line, so the text of one reply may span several entries.
+			if text := claudeText(entry.Message.Content); text != "" {
+				assistant = append(assistant, text)
+				if result.Model == "" {
+					result.Model = strings.TrimSpace(entry.Message.Model)
+				}
+			}
+		case "user":
+			if prompt := claudeUserPrompt(entry.Message.Content); prompt != "" {
+				result.LastUserPrompt = prompt
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return ClaudeTranscript{}, err
+	}
+	for i := len(assistant) - 1; i >= 0; i-- {
+		if result.LastAssistantMessage != "" {
+			result.LastAssistantMessage += "\n\n"
+		}
+		result.LastAssistantMessage += assistant[i]
+	}
+	return result, nil
+}
+
+// claudeText joins the text blocks of message content, which is either a
+// plain string or a list of content blocks.
+func claudeText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return strings.TrimSpace(text)
+	}
+	var blocks []claudeContentBlock
+	if json.Unmarshal(content, &blocks) != nil {
+		return ""
+	}
+	var parts []string
+	for _, block := range blocks {
+		if block.Type == "text" && strings.TrimSpace(block.Text) != "" {
+			parts = append(parts, strings.TrimSpace(block.Text))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// claudeUserPrompt returns the text of a user entry that the user typed.
+// Tool results and the records of slash commands are user entries too and
+// give an empty prompt.
+func claudeUserPrompt(content json.RawMessage) string {
+	prompt := claudeText(content)
+	for _, prefix := range []string{"<command-name>", "<command-message>", "<local-command-stdout>", "<local-command-stderr>"} {
+		if strings.HasPrefix(prompt, prefix) {
+			return ""
+		}
+	}
+	return prompt
+}
+
+// scanLinesBackward calls fn with the lines of r, last line first, until fn
+// returns false. A last line without a trailing newline is passed as is.
+// Lines longer than maxTranscriptLine are skipped, and scanning stops after
+// maxTranscriptScan bytes.
+func scanLinesBackward(r io.ReaderAt, size int64, fn func(line []byte) bool) error {
+	// carry holds the pieces, in file order, of a line whose start has not
+	// been read yet.
+	var carry [][]byte
+	carryLen := 0
+	oversized := false
+	stop := size - maxTranscriptScan
+	if stop < 0 {
+		stop = 0
+	}
+
+	for pos := size; pos > stop; {
+		n := int64(transcriptChunkSize)
+		if n > pos-stop {
+			n = pos - stop
+		}
+		pos -= n
+		chunk := make([]byte, n)
+		if _, err := r.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return err
+		}
+
+		end := len(chunk)
+		for {
+			i := bytes.LastIndexByte(chunk[:end], '\n')
+			if i < 0 {
+				break
+			}
+			line := chunk[i+1 : end]
+			if len(carry) > 0 {
+				line = bytes.Join(append([][]byte{line}, carry...), nil)
+				carry, carryLen = nil, 0
+			}
+			if oversized {
+				oversized = false
+			} else if !fn(line) {
+				return nil
+			}
+			end = i
+		}
+
+		if oversized {
+			continue
+		}
+		carry = append([][]byte{chunk[:end]}, carry...)
+		carryLen += end
+		if carryLen > maxTranscriptLine {
+			carry, carryLen, oversized = nil, 0, true
+		}
+	}
+
+	if stop == 0 && !oversized && len(carry) > 0 {
+		fn(bytes.Join(carry, nil))
+	}
+	return nil
+}
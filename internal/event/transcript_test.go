@@ -0,0 +1,100 @@
+package event
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func transcriptLines(lines ...string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+const (
+	transcriptPrompt    = `{"type":"user","message":{"role":"user","content":"fix the flaky test"}}`
+	transcriptToolUse   = `{"type":"assistant","message":{"model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}`
+	transcriptResult    = `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`
+	transcriptAnswer    = `{"type":"assistant","message":{"model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Fixed the race in the cache test."}]}}`
+	transcriptSidechain = `{"type":"assistant","isSidechain":true,"message":{"role":"assistant","content":[{"type":"text","text":"subagent notes"}]}}`
+)
+
+func TestReadClaudeTranscript_LastTurn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "s-1.jsonl")
+	content := transcriptLines(
+		`{"type":"user","message":{"role":"user","content":"an older prompt"}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"an older answer"}]}}`,
+		transcriptPrompt,
+		`{"type":"user","isMeta":true,"message":{"role":"user","content":"Caveat: local commands follow"}}`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-5","role":"assistant","content":[{"type":"text","text":"Let me run the tests."}]}}`,
+		transcriptToolUse,
+		transcriptResult,
+		transcriptSidechain,
+		transcriptAnswer,
+		`{"type":"summary","summary":"Cache fixes"}`,
+	)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write transcript: %v", err)
+	}
+
+	got, err := ReadClaudeTranscript(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := ClaudeTranscript{
+		LastAssistantMessage: "Let me run the tests.\n\nFixed the race in the cache test.",
+		LastUserPrompt:       "fix the flaky test",
+		Model:                "claude-sonnet-4-5",
+	}
+	if got != want {
+		t.Fatalf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadClaudeTranscript_PartialAndCommandLines(t *testing.T) {
+	content := transcriptLines(
+		transcriptPrompt,
+		transcriptAnswer,
+		`{"type":"user","message":{"role":"user","content":"<command-name>/cost</command-name>"}}`,
+	) + `{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"half writ`
+
+	got, err := readClaudeTranscript(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.LastAssistantMessage != "Fixed the race in the cache test." || got.LastUserPrompt != "fix the flaky test" {
+		t.Fatalf("unexpected transcript: %+v", got)
+	}
+}
+
+func TestReadClaudeTranscript_LargeEntries(t *testing.T) {
+	long := strings.Repeat("x", 3*transcriptChunkSize+17)
+	huge := strings.Repeat("y", maxTranscriptLine+1)
+	content := transcriptLines(
+		transcriptPrompt,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"`+long+`"}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"`+huge+`"}]}}`,
+		transcriptAnswer,
+	)
+
+	got, err := readClaudeTranscript(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.LastAssistantMessage != long+"\n\nFixed the race in the cache test." {
+		t.Fatalf("unexpected assistant message of %d bytes", len(got.LastAssistantMessage))
+	}
+	if got.LastUserPrompt != "fix the flaky test" {
+		t.Fatalf("unexpected prompt: %q", got.LastUserPrompt)
+	}
+}
+
+func TestReadClaudeTranscript_Missing(t *testing.T) {
+	if _, err := ReadClaudeTranscript(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Fatalf("expected error for missing transcript")
+	}
+	got, err := readClaudeTranscript(strings.NewReader(""), 0)
+	if err != nil || got != (ClaudeTranscript{}) {
+		t.Fatalf("expected empty transcript, got %+v, %v", got, err)
+	}
+}
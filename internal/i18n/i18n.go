@@ -0,0 +1,132 @@
+// Package i18n translates the text cc-notify shows: notification titles
+// and bodies, approval actions and the interactive UI.
+//
+// Messages are looked up by their English text, so code reads as before and
+// a message missing from a catalog falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Language is a supported language tag.
+type Language string
+
+const (
+	English           Language = "en"
+	SimplifiedChinese Language = "zh-CN"
+)
+
+// Auto is the language preference that detects the language from the
+// locale.
+const Auto = "auto"
+
+// catalogs maps English messages to their translations.
+var catalogs = map[Language]map[string]string{
+	SimplifiedChinese: zhCN,
+}
+
+// Languages returns the supported languages, English first.
+func Languages() []Language {
+	return []Language{English, SimplifiedChinese}
+}
+
+// Name returns the language's name in that language.
+func (l Language) Name() string {
+	switch l {
+	case SimplifiedChinese:
+		return "简体中文"
+	default:
+		return "English"
+	}
+}
+
+// T returns the translation of msg, or msg itself when there is none.
+func (l Language) T(msg string) string {
+	if translated, ok := catalogs[l][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format with args.
+func (l Language) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(l.T(format), args...)
+}
+
+// Plural returns one translated for n == 1 and other formatted with n
+// otherwise.
+func (l Language) Plural(n int, one, other string) string {
+	if n == 1 {
+		return l.T(one)
+	}
+	return l.Sprintf(other, n)
+}
+
+// Parse maps a language tag or POSIX locale such as "zh_CN.UTF-8" to a
+// supported language.
+func Parse(value string) (Language, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, ".@"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.ReplaceAll(value, "_", "-")
+	primary, region, _ := strings.Cut(value, "-")
+	switch primary {
+	case "en":
+		return English, true
+	case "zh":
+		// Traditional Chinese is not translated yet; English is the
+		// fallback there.
+		switch region {
+		case "", "cn", "sg", "hans":
+			return SimplifiedChinese, true
+		}
+		if strings.HasPrefix(region, "hans-") {
+			return SimplifiedChinese, true
+		}
+	}
+	return "", false
+}
+
+// Resolve returns the language for a language preference: a tag, or empty
+// or "auto" to detect it.
+func Resolve(pref string) Language {
+	pref = strings.TrimSpace(pref)
+	if pref == "" || strings.EqualFold(pref, Auto) {
+		return Detect()
+	}
+	if lang, ok := Parse(pref); ok {
+		return lang
+	}
+	return English
+}
+
+// Detect returns the language of the user's locale: the first of
+// LANGUAGE, LC_ALL, LC_MESSAGES and LANG that is set, else the system
+// locale. Unsupported locales get English.
+func Detect() Language {
+	for _, key := range []string{"LANGUAGE", "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := strings.TrimSpace(os.Getenv(key))
+		if key == "LANGUAGE" {
+			// A priority list such as "zh_CN:en".
+			value, _, _ = strings.Cut(value, ":")
+		}
+		if value == "" {
+			continue
+		}
+		if value == "C" || value == "POSIX" {
+			break
+		}
+		if lang, ok := Parse(value); ok {
+			return lang
+		}
+		return English
+	}
+	if lang, ok := Parse(systemLocale()); ok {
+		return lang
+	}
+	return English
+}
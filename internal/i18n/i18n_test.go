@@ -0,0 +1,154 @@
+package i18n
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Language
+		ok   bool
+	}{
+		{"en", English, true},
+		{"en_GB.UTF-8", English, true},
+		{"zh-CN", SimplifiedChinese, true},
+		{"zh_CN.UTF-8", SimplifiedChinese, true},
+		{"zh-Hans-CN", SimplifiedChinese, true},
+		{"zh_SG", SimplifiedChinese, true},
+		{"zh-TW", "", false},
+		{"fr_FR", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Fatalf("Parse(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LANGUAGE", "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "zh_CN.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := Detect(); got != SimplifiedChinese {
+		t.Fatalf("expected LC_MESSAGES to win over LANG, got %q", got)
+	}
+	t.Setenv("LANGUAGE", "en:zh_CN")
+	if got := Detect(); got != English {
+		t.Fatalf("expected LANGUAGE to win, got %q", got)
+	}
+	t.Setenv("LANGUAGE", "")
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if got := Detect(); got != English {
+		t.Fatalf("expected English for an unsupported locale, got %q", got)
+	}
+	if got := Resolve("zh"); got != SimplifiedChinese {
+		t.Fatalf("expected preference to override the locale, got %q", got)
+	}
+	if got := Resolve("auto"); got != English {
+		t.Fatalf("expected auto to detect, got %q", got)
+	}
+}
+
+func TestLanguageT(t *testing.T) {
+	if got := SimplifiedChinese.T("Codex Task Complete"); got != "Codex 任务完成" {
+		t.Fatalf("unexpected translation: %q", got)
+	}
+	if got := SimplifiedChinese.T("Not in the catalog"); got != "Not in the catalog" {
+		t.Fatalf("expected English fallback, got %q", got)
+	}
+	if got := English.Sprintf("Dir: %s", "api"); got != "Dir: api" {
+		t.Fatalf("unexpected English text: %q", got)
+	}
+	if got := SimplifiedChinese.Plural(3, "1 command", "%d commands"); got != "3 条命令" {
+		t.Fatalf("unexpected plural: %q", got)
+	}
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			want := formatVerb.FindAllString(msg, -1)
+			got := formatVerb.FindAllString(translated, -1)
+			sort.Strings(want)
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Fatalf("%s: %q has verbs %v, translation %q has %v", lang, msg, want, translated, got)
+			}
+		}
+	}
+}
+
+// TestCatalogsCoverMessages checks that every message passed as a literal
+// to T, Sprintf or Plural outside this package is in every catalog.
+func TestCatalogsCoverMessages(t *testing.T) {
+	fset := token.NewFileSet()
+	var missing []string
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "fmt" {
+				return true
+			}
+			var args []ast.Expr
+			switch {
+			case (sel.Sel.Name == "T" || sel.Sel.Name == "Sprintf") && len(call.Args) > 0:
+				args = call.Args[:1]
+			case sel.Sel.Name == "Plural" && len(call.Args) == 3:
+				args = call.Args[1:]
+			}
+			for _, arg := range args {
+				lit, ok := arg.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				msg, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					continue
+				}
+				for lang, catalog := range catalogs {
+					if _, ok := catalog[msg]; !ok {
+						missing = append(missing, fmt.Sprintf("%s: %q (%s)", lang, msg, fset.Position(lit.Pos())))
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk sources: %v", err)
+	}
+	if len(missing) > 0 {
+		t.Fatalf("messages missing from catalogs:\n%s", strings.Join(missing, "\n"))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package i18n
+
+// systemLocale is empty outside Windows, where the locale environment
+// variables are all there is.
+func systemLocale() string {
+	return ""
+}
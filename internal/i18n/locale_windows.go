@@ -0,0 +1,25 @@
+//go:build windows
+
+package i18n
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const localeNameMaxLength = 85
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procGetUserDefaultLocaleName = kernel32.NewProc("GetUserDefaultLocaleName")
+)
+
+// systemLocale returns the user's Windows locale, e.g. "zh-CN".
+func systemLocale() string {
+	buf := make([]uint16, localeNameMaxLength)
+	n, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
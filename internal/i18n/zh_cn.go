@@ -0,0 +1,234 @@
+package i18n
+
+// zhCN is the Simplified Chinese catalog.
+var zhCN = map[string]string{
+	// Notification titles.
+	"Codex Task Complete":           "Codex 任务完成",
+	"Codex Needs Input":             "Codex 需要你的输入",
+	"Claude Subagent Finished":      "Claude 子 agent 已完成",
+	"Claude Is Waiting for You":     "Claude 正在等你",
+	"Claude Code Notification":      "Claude Code 通知",
+	"Claude Is Compacting Context":  "Claude 正在压缩上下文",
+	"Claude Session Started":        "Claude 会话已开始",
+	"Claude Session Ended":          "Claude 会话已结束",
+	"Claude Prompt Submitted":       "Claude 已提交提示词",
+	"Claude Is Using a Tool":        "Claude 正在使用工具",
+	"Claude Tool Finished":          "Claude 工具调用完成",
+	"Gemini Task Complete":          "Gemini 任务完成",
+	"Gemini CLI Notification":       "Gemini CLI 通知",
+	"Gemini Is Compressing Context": "Gemini 正在压缩上下文",
+	"Gemini Session Started":        "Gemini 会话已开始",
+	"Gemini Session Ended":          "Gemini 会话已结束",
+	"Gemini Prompt Submitted":       "Gemini 已提交提示词",
+	"Gemini Is Using a Tool":        "Gemini 正在使用工具",
+	"Gemini Tool Finished":          "Gemini 工具调用完成",
+	"Aider Is Waiting for You":      "Aider 正在等你",
+	"Command Finished":              "命令已完成",
+	"Command Failed":                "命令失败",
+
+	// Notification bodies and fields.
+	"complete":                  "已完成",
+	"waiting for approval":      "等待审批",
+	"Task completed":            "任务已完成",
+	"Waiting for your approval": "等待你的审批",
+	"Subagent finished":         "子 agent 已完成",
+	"Waiting for your input":    "等待你的输入",
+	"New notification":          "新通知",
+	"Compacting context":        "正在压缩上下文",
+	"Session started":           "会话已开始",
+	"Session ended":             "会话已结束",
+	"Prompt submitted":          "提示词已提交",
+	"Tool call starting":        "工具调用开始",
+	"Tool call finished":        "工具调用完成",
+	"Dir: %s":                   "目录：%s",
+	"Model: %s":                 "模型：%s",
+	"Event: %s":                 "事件：%s",
+	"Done in %s":                "用时 %s",
+	"%s tokens":                 "%s 个 token",
+	"1 command":                 "1 条命令",
+	"%d commands":               "%d 条命令",
+	"1 tool call":               "1 次工具调用",
+	"%d tool calls":             "%d 次工具调用",
+	"Command finished":          "命令已完成",
+	"Command failed":            "命令失败",
+	"Command: %s":               "命令：%s",
+	"Exit code: %d":             "退出码：%d",
+	"Git: %s":                   "Git：%s",
+	"worktree %s":               "工作树 %s",
+	"%d modified":               "%d 个已修改",
+	"%d untracked":              "%d 个未跟踪",
+	"clean":                     "无改动",
+	"Edited 1 file":             "编辑了 1 个文件",
+	"Edited %d files":           "编辑了 %d 个文件",
+	"No files edited":           "未编辑文件",
+
+	// Approval actions and the terminal prompt.
+	"Yes, proceed": "是，继续",
+	"Yes, and don't ask again for this command pattern":  "是，此类命令不再询问",
+	"Yes, don't ask again for `%s`":                      "是，`%s` 不再询问",
+	"No, tell Codex to do differently":                   "否，告诉 Codex 换种做法",
+	"Tell Codex what to do differently":                  "告诉 Codex 应该怎么做",
+	"Would you like to run the following command?":       "是否运行以下命令？",
+	"Choose an option:":                                  "请选择：",
+	"1. Yes, proceed (y)":                                "1. 是，继续 (y)",
+	"2. Yes, and don't ask again for this pattern (p)":   "2. 是，此类命令不再询问 (p)",
+	"3. No, and tell Codex what to do differently (esc)": "3. 否，并告诉 Codex 换种做法 (esc)",
+	"Select 1/2/3 (or y/p/esc).":                         "请输入 1/2/3（或 y/p/esc）。",
+	"Allow":                                              "允许",
+	"Deny":                                               "拒绝",
+	"Tell Claude what to do instead":                     "告诉 Claude 应该怎么做",
+
+	// Legend of the message box that offers approval actions.
+	"Yes -> %s":    "是 -> %s",
+	"No -> %s":     "否 -> %s",
+	"Cancel -> %s": "取消 -> %s",
+
+	// Approval responses.
+	"Codex Approval":    "Codex 审批",
+	"Claude Approval":   "Claude 审批",
+	"Response sent: %s": "已发送回复：%s",
+	"Unable to apply response: request not found or expired.":                                       "无法应用回复：请求不存在或已过期。",
+	"Unable to apply response: request expired.":                                                    "无法应用回复：请求已过期。",
+	"Unable to apply response automatically. Open terminal and answer manually.":                    "无法自动应用回复，请打开终端手动答复。",
+	"The agent is waiting for approval. Answer the prompt on your machine or from another channel.": "agent 正在等待审批，请在本机或通过其他通道答复。",
+
+	// Answers to Telegram button taps.
+	"Response sent":                "已发送回复",
+	"Unknown action":               "未知操作",
+	"Unable to apply response: %v": "无法应用回复：%v",
+
+	// Labels channels add around the message.
+	"Project":  "项目",
+	"Model":    "模型",
+	"Event":    "事件",
+	"Path: %s": "路径：%s",
+	"via %s":   "来自 %s",
+
+	// Interactive UI: frame and navigation.
+	"Notifications for your coding agents":             "编程 agent 的通知工具",
+	"Initial Setup":                                    "初始设置",
+	"First launch detected. Auto-configuring hooks...": "首次启动，正在自动配置 hook...",
+	"note:":                   "注意：",
+	"auto install failed":     "自动安装失败",
+	"save setup state failed": "保存设置状态失败",
+	"save on exit failed":     "退出时保存失败",
+	"Goodbye!":                "再见！",
+	"Default":                 "默认",
+	"tab":                     "切换标签",
+	"navigate":                "移动",
+	"select":                  "选择",
+	"confirm":                 "确认",
+	"back":                    "返回",
+	"toggle":                  "勾选",
+	"quit":                    "退出",
+
+	// Interactive UI: tabs.
+	"Global defaults applied to all tools": "适用于所有工具的全局默认值",
+	"%s notifications":                     "%s 通知",
+	"(all inherited from Default)":         "（全部继承默认设置）",
+	"mode:":                                "模式：",
+	"content:":                             "内容：",
+	"inherit":                              "继承",
+
+	// Interactive UI: menu entries.
+	"Toggle notifications":      "通知开关",
+	"Notification mode":         "通知方式",
+	"Approval prompt mode":      "审批提示方式",
+	"Content mode":              "内容模式",
+	"Configure extra fields":    "配置附加字段",
+	"Language":                  "语言",
+	"Send preview notification": "发送预览通知",
+	"Preview templates":         "预览模板",
+	"%d configured":             "已配置 %d 个",
+	"Save settings now":         "立即保存设置",
+	"Exit":                      "退出",
+	"Toggle %s":                 "%s 开关",
+	"%s mode":                   "%s 通知方式",
+	"%s content":                "%s 内容模式",
+	"Install %s hook":           "安装 %s hook",
+	"Send %s preview":           "发送 %s 预览",
+	"Install Codex hook":        "安装 Codex hook",
+
+	// Interactive UI: option pickers.
+	"Default Mode": "默认通知方式",
+	"Notification delivery method for all tools.": "所有工具的通知发送方式。",
+	"Approval Prompt":                                        "审批提示",
+	"How pause approvals are displayed.":                     "暂停审批的显示方式。",
+	"Default Content":                                        "默认内容",
+	"What to show in the notification body.":                 "通知正文显示的内容。",
+	"Extra Fields":                                           "附加字段",
+	"Toggle additional info in notifications.":               "选择通知中附加的信息。",
+	"Language of notifications and this UI.":                 "通知和本界面使用的语言。",
+	"%s Mode":                                                "%s 通知方式",
+	"%s Content":                                             "%s 内容模式",
+	"Choose mode or 'global' to inherit Default.":            "选择通知方式，或选 global 继承默认设置。",
+	"Choose content mode or 'global' to inherit Default.":    "选择内容模式，或选 global 继承默认设置。",
+	"toast first, popup fallback":                            "优先 toast，失败时弹窗",
+	"Windows system notification":                            "Windows 系统通知",
+	"popup dialog":                                           "弹出对话框",
+	"escape sequence to the agent terminal":                  "向 agent 终端发送转义序列",
+	"escape sequence to the agent terminal (works over SSH)": "向 agent 终端发送转义序列（SSH 下可用）",
+	"desktop popup dialog":                                   "桌面弹窗",
+	"Windows notification buttons":                           "Windows 通知按钮",
+	"inline prompt inside Codex/Claude terminal":             "在 Codex/Claude 终端内提示",
+	"inline prompt in terminal":                              "在终端内提示",
+	"short summary":                                          "简短摘要",
+	"full assistant message":                                 "完整的 assistant 消息",
+	"minimal text":                                           "最简文本",
+	"use Default setting":                                    "使用默认设置",
+	"follow the system locale":                               "跟随系统区域设置",
+	"Include project directory":                              "显示项目目录",
+	"Include model name":                                     "显示模型名称",
+	"Include event type":                                     "显示事件类型",
+	"Include turn duration":                                  "显示本轮耗时",
+	"Include token count":                                    "显示 token 数",
+	"Include estimated cost":                                 "显示预估费用",
+	"Include tool calls":                                     "显示工具调用次数",
+	"Include git branch":                                     "显示 git 分支",
+	"Include git commit":                                     "显示 git 提交",
+	"Include git changes":                                    "显示 git 改动数",
+	"Include git worktree":                                   "显示 git 工作树",
+	"Include files edited this turn":                         "显示本轮编辑的文件",
+	"Toast AppId (blank = default): ":                        "Toast AppId（留空使用默认值）：",
+	"Press Enter to continue ":                               "按回车继续 ",
+
+	// Interactive UI: results.
+	"Saved.":                                    "已保存。",
+	"Save failed:":                              "保存失败：",
+	"save failed":                               "保存失败",
+	"Preview sent.":                             "预览已发送。",
+	"Preview failed:":                           "预览失败：",
+	"preview failed":                            "预览失败",
+	"Install failed:":                           "安装失败：",
+	"Codex install failed":                      "Codex 安装失败",
+	"Claude install failed":                     "Claude 安装失败",
+	"%s preview sent.":                          "%s 预览已发送。",
+	"%s hook installed.":                        "%s hook 已安装。",
+	"Codex hook installed.":                     "Codex hook 已安装。",
+	"Claude Code hook installed.":               "Claude Code hook 已安装。",
+	"Invalid template:":                         "模板无效：",
+	"Templates rendered.":                       "模板已渲染。",
+	"No templates configured in settings.json.": "settings.json 中没有配置模板。",
+	"Notification Mode Selected":                "已选择通知方式",
+	"Mode: %s (%s)":                             "通知方式：%s（%s）",
+
+	// Line-based interactive UI.
+	"Status: %s  Mode: %s  Content: %s":            "状态：%s  通知方式：%s  内容：%s",
+	"Settings auto-saved to disk on every change.": "每次修改都会自动保存到磁盘。",
+	"Settings":                   "设置",
+	"Actions":                    "操作",
+	"Cycle notification mode":    "切换通知方式",
+	"Cycle content mode":         "切换内容模式",
+	"Cycle approval prompt mode": "切换审批提示方式",
+	"Set Toast AppId":            "设置 Toast AppId",
+	"Cycle language":             "切换语言",
+	"Install Claude Code hook":   "安装 Claude Code hook",
+	"Mode":                       "通知方式",
+	"Prompt":                     "审批提示",
+	"Unknown option. Choose 1-9, L or 0 to exit.": "未知选项。请选择 1-9、L，或输入 0 退出。",
+
+	// Samples for previews.
+	"Sample summary: work completed.":                                                 "示例摘要：工作已完成。",
+	"Sample full answer: ran `go test ./...` and all requested changes are finished.": "示例完整回复：已运行 `go test ./...`，所有修改均已完成。",
+	"Sample prompt: fix the flaky test.":                                              "示例提示词：修复不稳定的测试。",
+}
@@ -0,0 +1,132 @@
+package notifier
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChatWebhookConfig configures a Slack, Discord or Teams incoming webhook.
+type ChatWebhookConfig struct {
+	URL     string
+	Timeout time.Duration
+}
+
+// chatWebhook posts a client-specific document built by format.
+type chatWebhook struct {
+	name   string
+	url    string
+	client *http.Client
+	format func(Message) interface{}
+}
+
+func newChatWebhook(name string, cfg ChatWebhookConfig, format func(Message) interface{}) (Service, error) {
+	target, err := validateHTTPURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &chatWebhook{
+		name:   name,
+		url:    target,
+		client: &http.Client{Timeout: timeout},
+		format: format,
+	}, nil
+}
+
+func (c *chatWebhook) Notify(title, body string) error {
+	return c.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (c *chatWebhook) NotifyMessage(msg Message) error {
+	if err := postJSON(c.client, c.url, nil, c.format(msg)); err != nil {
+		return fmt.Errorf("send %s notification: %w", c.name, err)
+	}
+	return nil
+}
+
+// textSegment is a run of prose or a fenced code block within a message.
+type textSegment struct {
+	Code bool
+	Lang string
+	Text string
+}
+
+// splitCodeSegments splits Markdown text on ``` fences. An unterminated
+// fence runs to the end of the text, which is how chat clients render it.
+func splitCodeSegments(text string) []textSegment {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var segments []textSegment
+	var buf []string
+	inCode := false
+	lang := ""
+
+	flush := func() {
+		joined := strings.Join(buf, "\n")
+		if !inCode {
+			joined = strings.TrimSpace(joined)
+		}
+		if strings.TrimSpace(joined) != "" {
+			segments = append(segments, textSegment{Code: inCode, Lang: lang, Text: joined})
+		}
+		buf = buf[:0]
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			if inCode {
+				inCode, lang = false, ""
+			} else {
+				inCode, lang = true, strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			}
+			continue
+		}
+		buf = append(buf, line)
+	}
+	flush()
+	return segments
+}
+
+// chatMessageText is the text chat formatters display: the full message when
+// the caller provided it, otherwise the rendered body.
+func chatMessageText(msg Message) string {
+	if strings.TrimSpace(msg.Text) != "" {
+		return msg.Text
+	}
+	return msg.Body
+}
+
+// projectName returns the last element of a Windows or POSIX working directory.
+func projectName(cwd string) string {
+	cwd = strings.TrimRight(strings.TrimSpace(cwd), `/\`)
+	if i := strings.LastIndexAny(cwd, `/\`); i >= 0 {
+		cwd = cwd[i+1:]
+	}
+	return cwd
+}
+
+// chatFact is a labelled context value shown next to the message.
+type chatFact struct {
+	Name  string
+	Value string
+}
+
+func chatFacts(msg Message) []chatFact {
+	var facts []chatFact
+	if name := projectName(msg.CWD); name != "" {
+		facts = append(facts, chatFact{Name: msg.Language.T("Project"), Value: name})
+	}
+	if model := strings.TrimSpace(msg.Model); model != "" {
+		facts = append(facts, chatFact{Name: msg.Language.T("Model"), Value: model})
+	}
+	if eventType := strings.TrimSpace(msg.EventType); eventType != "" {
+		facts = append(facts, chatFact{Name: msg.Language.T("Event"), Value: eventType})
+	}
+	return facts
+}
@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cc-notify/internal/i18n"
+	"unicode/utf8"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func chatFixture() Message {
+	return Message{
+		Title:     "Codex Task Complete",
+		Body:      "Fixed the flaky test\nDir: cc-notify",
+		Text:      "Fixed the flaky test in `notifier` & tightened <timeouts>.\n\n```go\nfunc TestX(t *testing.T) {\n\tt.Parallel()\n}\n```\n\nRun `go test ./...` to confirm.",
+		Source:    "codex",
+		EventType: "agent-turn-complete",
+		CWD:       `C:\src\cc-notify`,
+		Model:     "gpt-5-codex",
+	}
+}
+
+func pausedChatFixture() Message {
+	return Message{
+		Title:     "Claude Needs Input",
+		Body:      "Claude needs your permission to use Bash",
+		Source:    "claude",
+		EventType: "agent-turn-paused",
+		CWD:       "/home/dev/api",
+	}
+}
+
+func assertGolden(t *testing.T, name string, value interface{}) {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		t.Fatalf("encode %s: %v", name, err)
+	}
+	path := filepath.Join("testdata", name+".golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run with -update to create): %v", err)
+	}
+	if !bytes.Equal(bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n")), buf.Bytes()) {
+		t.Fatalf("%s does not match golden file %s:\n%s", name, path, buf.String())
+	}
+}
+
+func TestSlackDocument_Golden(t *testing.T) {
+	assertGolden(t, "slack_complete", slackDocument(chatFixture()))
+	assertGolden(t, "slack_paused", slackDocument(pausedChatFixture()))
+}
+
+func TestSlackEscapeLimit_CutsBeforeEscaping(t *testing.T) {
+	cases := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{text: "a & b", limit: 10, want: "a &amp; b"},
+		// The entity of the & would cross the limit, so the cut lands
+		// before it rather than inside "&amp;".
+		{text: "abc & <d>", limit: 7, want: "abc …"},
+		{text: "<<<<", limit: 10, want: "&lt;&lt;…"},
+	}
+	for _, tc := range cases {
+		got := slackEscapeLimit(tc.text, tc.limit)
+		if got != tc.want || utf8.RuneCountInString(got) > tc.limit {
+			t.Fatalf("slackEscapeLimit(%q, %d) = %q, want %q", tc.text, tc.limit, got, tc.want)
+		}
+	}
+}
+
+func TestDiscordDocument_Golden(t *testing.T) {
+	assertGolden(t, "discord_complete", discordDocument(chatFixture()))
+	assertGolden(t, "discord_paused", discordDocument(pausedChatFixture()))
+}
+
+func TestTeamsDocument_Golden(t *testing.T) {
+	assertGolden(t, "teams_complete", teamsDocument(chatFixture()))
+	assertGolden(t, "teams_paused", teamsDocument(pausedChatFixture()))
+}
+
+func TestSplitCodeSegments(t *testing.T) {
+	got := splitCodeSegments("intro\n```sh\nls -la\n\necho hi\n```\noutro\n```\nunterminated")
+	want := []textSegment{
+		{Text: "intro"},
+		{Code: true, Lang: "sh", Text: "ls -la\n\necho hi"},
+		{Text: "outro"},
+		{Code: true, Text: "unterminated"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d segments, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("segment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDiscordClip_ClosesOpenFence(t *testing.T) {
+	text := "```go\n" + string(bytes.Repeat([]byte("x"), 5000))
+	got := discordClip(text, discordDescriptionLimit)
+	if n := len([]rune(got)); n > discordDescriptionLimit {
+		t.Fatalf("clipped description has %d runes", n)
+	}
+	if got[len(got)-3:] != "```" {
+		t.Fatalf("expected clipped code fence to be closed, got tail %q", got[len(got)-10:])
+	}
+}
+
+func TestChatWebhook_PostsFormattedDocument(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	svc, err := NewDiscord(ChatWebhookConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Send(svc, chatFixture()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	embeds, _ := got["embeds"].([]interface{})
+	if len(embeds) != 1 {
+		t.Fatalf("expected one embed, got %v", got)
+	}
+
+	if _, err := NewSlack(ChatWebhookConfig{URL: "not a url"}); err == nil {
+		t.Fatalf("expected invalid url to be rejected")
+	}
+}
+
+func TestMessageLabels_FollowLanguage(t *testing.T) {
+	msg := pausedChatFixture()
+	msg.Language = i18n.SimplifiedChinese
+
+	var names []string
+	for _, fact := range chatFacts(msg) {
+		names = append(names, fact.Name)
+	}
+	if got := strings.Join(names, ","); got != "项目,事件" {
+		t.Fatalf("unexpected fact names: %q", got)
+	}
+	if got := emailApprovalNote(msg); got != "agent 正在等待审批，请在本机或通过其他通道答复。" {
+		t.Fatalf("unexpected approval note: %q", got)
+	}
+	if got := ToastForMessage(msg, ToastOptions{}).Attribution; got != "来自 Claude Code" {
+		t.Fatalf("unexpected attribution: %q", got)
+	}
+}
@@ -4,4 +4,34 @@ package notifier
 type Config struct {
 	Mode       string
 	ToastAppID string
+	// Toast customizes Windows toasts.
+	Toast ToastOptions
+
+	// TerminalStyle picks the escape sequence for mode "terminal": auto,
+	// osc9, osc777 or bell.
+	TerminalStyle string
+	// TerminalPID is the process whose terminal receives mode "terminal"
+	// notifications. Zero means the parent of the current process.
+	TerminalPID int
+
+	// ActionHandler receives the URI of an action the user picked, for
+	// backends that observe clicks in-process instead of through the
+	// cc-notify:// protocol handler. Nil means re-launch the executable
+	// with the URI, the same way protocol activation does.
+	ActionHandler func(uri string) error
+}
+
+// ToastOptions customizes Windows toasts beyond title, body and actions.
+// Toasts always carry the agent as attribution and are grouped under a
+// header per project directory.
+type ToastOptions struct {
+	// Logo and HeroImage are image paths or URIs, see Toast.
+	Logo       string
+	LogoCircle bool
+	HeroImage  string
+	// Audio is a sound name or ms-winsoundevent: URI, or "silent".
+	Audio string
+	// ApprovalScenario is the scenario of toasts with approval buttons.
+	// Empty means reminder, which keeps the toast up until it is answered.
+	ApprovalScenario string
 }
@@ -0,0 +1,898 @@
+//go:build linux
+
+package notifier
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// This file implements the small subset of the D-Bus wire protocol that the
+// freedesktop notifier needs: EXTERNAL auth over a unix socket, method calls,
+// replies, errors and signals, with little-endian marshalling of the basic,
+// array, struct, dict-entry and variant types.
+
+const (
+	dbusMethodCall   byte = 1
+	dbusMethodReturn byte = 2
+	dbusError        byte = 3
+	dbusSignal       byte = 4
+
+	dbusFieldPath        byte = 1
+	dbusFieldInterface   byte = 2
+	dbusFieldMember      byte = 3
+	dbusFieldErrorName   byte = 4
+	dbusFieldReplySerial byte = 5
+	dbusFieldDestination byte = 6
+	dbusFieldSender      byte = 7
+	dbusFieldSignature   byte = 8
+
+	dbusMaxMessageSize = 128 << 20
+)
+
+// dbusObjectPath marks a string that is marshalled with the 'o' type code.
+type dbusObjectPath string
+
+// dbusSignature marks a string that is marshalled with the 'g' type code.
+type dbusSignature string
+
+// dbusVariant is a value tagged with its own signature ('v').
+type dbusVariant struct {
+	Sig   string
+	Value interface{}
+}
+
+// dbusMessage is a decoded D-Bus message.
+type dbusMessage struct {
+	Type        byte
+	Flags       byte
+	Serial      uint32
+	Path        dbusObjectPath
+	Interface   string
+	Member      string
+	ErrorName   string
+	ReplySerial uint32
+	Destination string
+	Sender      string
+	Signature   string
+	Body        []interface{}
+}
+
+// dbusCallTimeout bounds the wait for the bus, from authentication to the
+// reply of every call, so a stuck bus cannot hold a hook.
+const dbusCallTimeout = 10 * time.Second
+
+// dbusConn is a minimal D-Bus client connection.
+type dbusConn struct {
+	conn net.Conn
+
+	writeMu sync.Mutex
+	serial  uint32
+
+	mu      sync.Mutex
+	pending map[uint32]chan *dbusMessage
+	signals chan *dbusMessage
+	closed  bool
+	readErr error
+
+	done chan struct{}
+}
+
+// sessionBusAddress resolves the session bus address from the environment.
+func sessionBusAddress(getenv func(string) string) (string, error) {
+	if addr := strings.TrimSpace(getenv("DBUS_SESSION_BUS_ADDRESS")); addr != "" {
+		return addr, nil
+	}
+	if runtimeDir := strings.TrimSpace(getenv("XDG_RUNTIME_DIR")); runtimeDir != "" {
+		path := filepath.Join(runtimeDir, "bus")
+		if _, err := os.Stat(path); err == nil {
+			return "unix:path=" + path, nil
+		}
+	}
+	return "", errors.New("no session bus address (DBUS_SESSION_BUS_ADDRESS is not set)")
+}
+
+// dialDBus connects to the first usable unix transport in a D-Bus address
+// list, authenticates and sends Hello.
+func dialDBus(address string) (*dbusConn, error) {
+	var lastErr error
+	for _, entry := range strings.Split(address, ";") {
+		network, target, err := parseDBusAddress(entry)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		raw, err := net.Dial(network, target)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn, err := newDBusConn(raw)
+		if err != nil {
+			_ = raw.Close()
+			lastErr = err
+			continue
+		}
+		return conn, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("empty address")
+	}
+	return nil, fmt.Errorf("connect to session bus: %w", lastErr)
+}
+
+func parseDBusAddress(entry string) (network, target string, err error) {
+	entry = strings.TrimSpace(entry)
+	transport, rawParams, ok := strings.Cut(entry, ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid bus address %q", entry)
+	}
+	if transport != "unix" {
+		return "", "", fmt.Errorf("unsupported bus transport %q", transport)
+	}
+	params := map[string]string{}
+	for _, kv := range strings.Split(rawParams, ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		params[key] = unescapeDBusAddressValue(value)
+	}
+	if path := params["path"]; path != "" {
+		return "unix", path, nil
+	}
+	if abstract := params["abstract"]; abstract != "" {
+		return "unix", "@" + abstract, nil
+	}
+	return "", "", fmt.Errorf("bus address %q has no socket path", entry)
+}
+
+func unescapeDBusAddressValue(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	var out strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '%' && i+2 < len(value) {
+			if b, err := hex.DecodeString(value[i+1 : i+3]); err == nil {
+				out.WriteByte(b[0])
+				i += 2
+				continue
+			}
+		}
+		out.WriteByte(value[i])
+	}
+	return out.String()
+}
+
+// newDBusConn authenticates an established transport and registers on the bus.
+func newDBusConn(raw net.Conn) (*dbusConn, error) {
+	reader := bufio.NewReader(raw)
+	_ = raw.SetDeadline(time.Now().Add(dbusCallTimeout))
+	if err := dbusAuthenticate(raw, reader); err != nil {
+		return nil, err
+	}
+	_ = raw.SetDeadline(time.Time{})
+
+	c := &dbusConn{
+		conn:    raw,
+		pending: map[uint32]chan *dbusMessage{},
+		signals: make(chan *dbusMessage, 16),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop(reader)
+
+	if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", ""); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("bus hello: %w", err)
+	}
+	return c, nil
+}
+
+func dbusAuthenticate(w io.Writer, r *bufio.Reader) error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := io.WriteString(w, "\x00AUTH EXTERNAL "+uid+"\r\n"); err != nil {
+		return fmt.Errorf("bus auth: %w", err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("bus auth: %w", err)
+	}
+	if !strings.HasPrefix(line, "OK") {
+		return fmt.Errorf("bus auth rejected: %s", strings.TrimSpace(line))
+	}
+	if _, err := io.WriteString(w, "BEGIN\r\n"); err != nil {
+		return fmt.Errorf("bus auth: %w", err)
+	}
+	return nil
+}
+
+// Close shuts the connection down and wakes up any waiting callers.
+func (c *dbusConn) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// Signals delivers every signal received on the connection. The channel is
+// closed when the connection goes away.
+func (c *dbusConn) Signals() <-chan *dbusMessage {
+	return c.signals
+}
+
+func (c *dbusConn) readLoop(r *bufio.Reader) {
+	defer close(c.done)
+	defer close(c.signals)
+	for {
+		msg, err := readDBusMessage(r)
+		if err != nil {
+			c.mu.Lock()
+			c.readErr = err
+			for serial, ch := range c.pending {
+				close(ch)
+				delete(c.pending, serial)
+			}
+			c.mu.Unlock()
+			return
+		}
+		switch msg.Type {
+		case dbusMethodReturn, dbusError:
+			c.mu.Lock()
+			ch, ok := c.pending[msg.ReplySerial]
+			delete(c.pending, msg.ReplySerial)
+			c.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+		case dbusSignal:
+			select {
+			case c.signals <- msg:
+			default:
+				// Nobody is listening fast enough; drop rather than stall replies.
+			}
+		}
+	}
+}
+
+// call sends a method call and waits up to dbusCallTimeout for its reply
+// body.
+func (c *dbusConn) call(dest string, path dbusObjectPath, iface, member, sig string, args ...interface{}) ([]interface{}, error) {
+	ch := make(chan *dbusMessage, 1)
+	serial, err := c.send(&dbusMessage{
+		Type:        dbusMethodCall,
+		Path:        path,
+		Interface:   iface,
+		Member:      member,
+		Destination: dest,
+		Signature:   sig,
+		Body:        args,
+	}, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(dbusCallTimeout)
+	defer timer.Stop()
+	var reply *dbusMessage
+	var ok bool
+	select {
+	case reply, ok = <-ch:
+	case <-timer.C:
+		c.mu.Lock()
+		delete(c.pending, serial)
+		c.mu.Unlock()
+		return nil, fmt.Errorf("%s.%s: no reply within %s", iface, member, dbusCallTimeout)
+	}
+	if !ok {
+		c.mu.Lock()
+		readErr := c.readErr
+		delete(c.pending, serial)
+		c.mu.Unlock()
+		if readErr == nil {
+			readErr = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("%s.%s: %w", iface, member, readErr)
+	}
+	if reply.Type == dbusError {
+		detail := ""
+		if len(reply.Body) > 0 {
+			if s, ok := reply.Body[0].(string); ok {
+				detail = ": " + s
+			}
+		}
+		return nil, fmt.Errorf("%s.%s: %s%s", iface, member, reply.ErrorName, detail)
+	}
+	return reply.Body, nil
+}
+
+func (c *dbusConn) send(msg *dbusMessage, reply chan *dbusMessage) (uint32, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.serial++
+	msg.Serial = c.serial
+	data, err := encodeDBusMessage(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	if reply != nil {
+		c.mu.Lock()
+		if c.closed || c.readErr != nil {
+			c.mu.Unlock()
+			return 0, errors.New("bus connection closed")
+		}
+		c.pending[msg.Serial] = reply
+		c.mu.Unlock()
+	}
+	if _, err := c.conn.Write(data); err != nil {
+		if reply != nil {
+			c.mu.Lock()
+			delete(c.pending, msg.Serial)
+			c.mu.Unlock()
+		}
+		return 0, fmt.Errorf("write bus message: %w", err)
+	}
+	return msg.Serial, nil
+}
+
+// dbusEncoder appends aligned little-endian values to a buffer. Alignment is
+// computed relative to the start of the message.
+type dbusEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *dbusEncoder) align(n int) {
+	for e.buf.Len()%n != 0 {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *dbusEncoder) uint32(v uint32) {
+	e.align(4)
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	e.buf.Write(b[:])
+}
+
+func (e *dbusEncoder) encode(sig string, value interface{}) error {
+	if sig == "" {
+		return errors.New("empty signature")
+	}
+	switch sig[0] {
+	case 'y':
+		v, ok := value.(byte)
+		if !ok {
+			return dbusTypeError(sig, value)
+		}
+		e.buf.WriteByte(v)
+	case 'b':
+		v, ok := value.(bool)
+		if !ok {
+			return dbusTypeError(sig, value)
+		}
+		if v {
+			e.uint32(1)
+		} else {
+			e.uint32(0)
+		}
+	case 'i':
+		v, ok := value.(int32)
+		if !ok {
+			return dbusTypeError(sig, value)
+		}
+		e.uint32(uint32(v))
+	case 'u':
+		v, ok := value.(uint32)
+		if !ok {
+			return dbusTypeError(sig, value)
+		}
+		e.uint32(v)
+	case 's', 'o':
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case dbusObjectPath:
+			s = string(v)
+		default:
+			return dbusTypeError(sig, value)
+		}
+		e.uint32(uint32(len(s)))
+		e.buf.WriteString(s)
+		e.buf.WriteByte(0)
+	case 'g':
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case dbusSignature:
+			s = string(v)
+		default:
+			return dbusTypeError(sig, value)
+		}
+		if len(s) > 255 {
+			return fmt.Errorf("signature too long: %d", len(s))
+		}
+		e.buf.WriteByte(byte(len(s)))
+		e.buf.WriteString(s)
+		e.buf.WriteByte(0)
+	case 'v':
+		v, ok := value.(dbusVariant)
+		if !ok {
+			return dbusTypeError(sig, value)
+		}
+		if err := e.encode("g", v.Sig); err != nil {
+			return err
+		}
+		return e.encode(v.Sig, v.Value)
+	case 'a':
+		return e.encodeArray(sig[1:], value)
+	case '(':
+		fields, ok := value.([]interface{})
+		if !ok {
+			return dbusTypeError(sig, value)
+		}
+		inner := sig[1 : len(sig)-1]
+		parts, err := splitDBusSignature(inner)
+		if err != nil {
+			return err
+		}
+		if len(parts) != len(fields) {
+			return fmt.Errorf("struct %s expects %d fields, got %d", sig, len(parts), len(fields))
+		}
+		e.align(8)
+		for i, part := range parts {
+			if err := e.encode(part, fields[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported signature %q", sig)
+	}
+	return nil
+}
+
+func (e *dbusEncoder) encodeArray(elemSig string, value interface{}) error {
+	e.align(4)
+	lenPos := e.buf.Len()
+	e.buf.Write([]byte{0, 0, 0, 0})
+	e.align(dbusAlignment(elemSig))
+	start := e.buf.Len()
+
+	switch v := value.(type) {
+	case []string:
+		for _, item := range v {
+			if err := e.encode(elemSig, item); err != nil {
+				return err
+			}
+		}
+	case map[string]dbusVariant:
+		if elemSig != "{sv}" {
+			return dbusTypeError("a"+elemSig, value)
+		}
+		for _, key := range sortedVariantKeys(v) {
+			e.align(8)
+			if err := e.encode("s", key); err != nil {
+				return err
+			}
+			if err := e.encode("v", v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if elemSig[0] == '{' {
+				pair, ok := item.([]interface{})
+				if !ok || len(pair) != 2 {
+					return dbusTypeError("a"+elemSig, item)
+				}
+				if err := e.encode("("+elemSig[1:len(elemSig)-1]+")", pair); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := e.encode(elemSig, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return dbusTypeError("a"+elemSig, value)
+	}
+
+	size := e.buf.Len() - start
+	binary.LittleEndian.PutUint32(e.buf.Bytes()[lenPos:], uint32(size))
+	return nil
+}
+
+func sortedVariantKeys(m map[string]dbusVariant) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func dbusTypeError(sig string, value interface{}) error {
+	return fmt.Errorf("cannot marshal %T as %q", value, sig)
+}
+
+func dbusAlignment(sig string) int {
+	switch sig[0] {
+	case 'y', 'g', 'v':
+		return 1
+	case 'n', 'q':
+		return 2
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	default:
+		return 4
+	}
+}
+
+// splitDBusSignature splits a signature into complete types.
+func splitDBusSignature(sig string) ([]string, error) {
+	var parts []string
+	for len(sig) > 0 {
+		n, err := dbusTypeLength(sig)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, sig[:n])
+		sig = sig[n:]
+	}
+	return parts, nil
+}
+
+func dbusTypeLength(sig string) (int, error) {
+	if sig == "" {
+		return 0, errors.New("truncated signature")
+	}
+	switch sig[0] {
+	case 'a':
+		n, err := dbusTypeLength(sig[1:])
+		return n + 1, err
+	case '(', '{':
+		closer := byte(')')
+		if sig[0] == '{' {
+			closer = '}'
+		}
+		i := 1
+		for i < len(sig) && sig[i] != closer {
+			n, err := dbusTypeLength(sig[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+		if i >= len(sig) {
+			return 0, fmt.Errorf("unterminated container in signature %q", sig)
+		}
+		return i + 1, nil
+	case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'v', 'h':
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("unsupported signature %q", sig)
+	}
+}
+
+// dbusDecoder reads aligned little-endian values from a message.
+type dbusDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *dbusDecoder) align(n int) error {
+	for d.pos%n != 0 {
+		d.pos++
+	}
+	if d.pos > len(d.data) {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func (d *dbusDecoder) take(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *dbusDecoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.take(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func (d *dbusDecoder) decode(sig string) (interface{}, error) {
+	switch sig[0] {
+	case 'y':
+		b, err := d.take(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0], nil
+	case 'b':
+		v, err := d.uint32()
+		return v != 0, err
+	case 'n', 'q':
+		if err := d.align(2); err != nil {
+			return nil, err
+		}
+		b, err := d.take(2)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint16(b)
+		if sig[0] == 'n' {
+			return int16(v), nil
+		}
+		return v, nil
+	case 'i':
+		v, err := d.uint32()
+		return int32(v), err
+	case 'u', 'h':
+		return d.uint32()
+	case 'x', 't', 'd':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		b, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		v := binary.LittleEndian.Uint64(b)
+		if sig[0] == 'x' {
+			return int64(v), nil
+		}
+		return v, nil
+	case 's', 'o':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.take(int(n) + 1)
+		if err != nil {
+			return nil, err
+		}
+		s := string(b[:n])
+		if sig[0] == 'o' {
+			return dbusObjectPath(s), nil
+		}
+		return s, nil
+	case 'g':
+		b, err := d.take(1)
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.take(int(b[0]) + 1)
+		if err != nil {
+			return nil, err
+		}
+		return dbusSignature(s[:b[0]]), nil
+	case 'v':
+		raw, err := d.decode("g")
+		if err != nil {
+			return nil, err
+		}
+		inner := string(raw.(dbusSignature))
+		if _, err := dbusTypeLength(inner); err != nil {
+			return nil, err
+		}
+		value, err := d.decode(inner)
+		if err != nil {
+			return nil, err
+		}
+		return dbusVariant{Sig: inner, Value: value}, nil
+	case 'a':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		elemSig := sig[1:]
+		if err := d.align(dbusAlignment(elemSig)); err != nil {
+			return nil, err
+		}
+		end := d.pos + int(n)
+		if end > len(d.data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		var items []interface{}
+		for d.pos < end {
+			item, err := d.decode(elemSig)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case '(', '{':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		n, err := dbusTypeLength(sig)
+		if err != nil {
+			return nil, err
+		}
+		parts, err := splitDBusSignature(sig[1 : n-1])
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			v, err := d.decode(part)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, v)
+		}
+		return fields, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature %q", sig)
+	}
+}
+
+func encodeDBusMessage(msg *dbusMessage) ([]byte, error) {
+	var body dbusEncoder
+	if msg.Signature != "" {
+		parts, err := splitDBusSignature(msg.Signature)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) != len(msg.Body) {
+			return nil, fmt.Errorf("signature %q expects %d arguments, got %d", msg.Signature, len(parts), len(msg.Body))
+		}
+		for i, part := range parts {
+			if err := body.encode(part, msg.Body[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var fields []interface{}
+	addField := func(code byte, sig string, value interface{}) {
+		fields = append(fields, []interface{}{code, dbusVariant{Sig: sig, Value: value}})
+	}
+	if msg.Path != "" {
+		addField(dbusFieldPath, "o", msg.Path)
+	}
+	if msg.Interface != "" {
+		addField(dbusFieldInterface, "s", msg.Interface)
+	}
+	if msg.Member != "" {
+		addField(dbusFieldMember, "s", msg.Member)
+	}
+	if msg.ErrorName != "" {
+		addField(dbusFieldErrorName, "s", msg.ErrorName)
+	}
+	if msg.ReplySerial != 0 {
+		addField(dbusFieldReplySerial, "u", msg.ReplySerial)
+	}
+	if msg.Destination != "" {
+		addField(dbusFieldDestination, "s", msg.Destination)
+	}
+	if msg.Sender != "" {
+		addField(dbusFieldSender, "s", msg.Sender)
+	}
+	if msg.Signature != "" {
+		addField(dbusFieldSignature, "g", dbusSignature(msg.Signature))
+	}
+
+	var header dbusEncoder
+	header.buf.Write([]byte{'l', msg.Type, msg.Flags, 1})
+	header.uint32(uint32(body.buf.Len()))
+	header.uint32(msg.Serial)
+	if err := header.encode("a(yv)", fields); err != nil {
+		return nil, err
+	}
+	header.align(8)
+	header.buf.Write(body.buf.Bytes())
+	return header.buf.Bytes(), nil
+}
+
+func readDBusMessage(r io.Reader) (*dbusMessage, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, err
+	}
+	if fixed[0] != 'l' {
+		return nil, fmt.Errorf("unsupported bus message endianness %q", fixed[0])
+	}
+	bodyLen := binary.LittleEndian.Uint32(fixed[4:8])
+	fieldsLen := binary.LittleEndian.Uint32(fixed[12:16])
+	headerLen := 16 + int(fieldsLen)
+	padded := (headerLen + 7) &^ 7
+	total := padded + int(bodyLen)
+	if total > dbusMaxMessageSize {
+		return nil, fmt.Errorf("bus message too large: %d bytes", total)
+	}
+
+	data := make([]byte, total)
+	copy(data, fixed)
+	if _, err := io.ReadFull(r, data[16:]); err != nil {
+		return nil, err
+	}
+
+	msg := &dbusMessage{
+		Type:   data[1],
+		Flags:  data[2],
+		Serial: binary.LittleEndian.Uint32(data[8:12]),
+	}
+	d := &dbusDecoder{data: data[:headerLen], pos: 12}
+	rawFields, err := d.decode("a(yv)")
+	if err != nil {
+		return nil, fmt.Errorf("decode bus header: %w", err)
+	}
+	for _, raw := range rawFields.([]interface{}) {
+		field := raw.([]interface{})
+		code := field[0].(byte)
+		value := field[1].(dbusVariant).Value
+		switch code {
+		case dbusFieldPath:
+			msg.Path, _ = value.(dbusObjectPath)
+		case dbusFieldInterface:
+			msg.Interface, _ = value.(string)
+		case dbusFieldMember:
+			msg.Member, _ = value.(string)
+		case dbusFieldErrorName:
+			msg.ErrorName, _ = value.(string)
+		case dbusFieldReplySerial:
+			msg.ReplySerial, _ = value.(uint32)
+		case dbusFieldDestination:
+			msg.Destination, _ = value.(string)
+		case dbusFieldSender:
+			msg.Sender, _ = value.(string)
+		case dbusFieldSignature:
+			if s, ok := value.(dbusSignature); ok {
+				msg.Signature = string(s)
+			}
+		}
+	}
+
+	if msg.Signature != "" {
+		parts, err := splitDBusSignature(msg.Signature)
+		if err != nil {
+			return nil, err
+		}
+		// Body offsets are aligned relative to the body start, which is itself
+		// 8-aligned, so decoding from a fresh slice keeps the alignment intact.
+		bd := &dbusDecoder{data: data[padded:]}
+		for _, part := range parts {
+			v, err := bd.decode(part)
+			if err != nil {
+				return nil, fmt.Errorf("decode bus body: %w", err)
+			}
+			msg.Body = append(msg.Body, v)
+		}
+	}
+	return msg, nil
+}
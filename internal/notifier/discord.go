@@ -0,0 +1,84 @@
+package notifier
+
+import "strings"
+
+const (
+	// Embed limits: title 256, description 4096, field value 1024.
+	discordTitleLimit       = 256
+	discordDescriptionLimit = 4096
+	discordFieldLimit       = 1024
+
+	discordColorComplete = 0x2ECC71
+	discordColorPaused   = 0xF1C40F
+)
+
+// NewDiscord creates a Discord webhook channel that renders an embed.
+func NewDiscord(cfg ChatWebhookConfig) (Service, error) {
+	return newChatWebhook("discord", cfg, func(msg Message) interface{} { return discordDocument(msg) })
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordPayload struct {
+	Username string         `json:"username"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+func discordDocument(msg Message) discordPayload {
+	embed := discordEmbed{
+		Title: truncateRunes(strings.TrimSpace(msg.Title), discordTitleLimit),
+		Color: discordColorComplete,
+	}
+	if msg.EventType == "agent-turn-paused" {
+		embed.Color = discordColorPaused
+	}
+	for _, fact := range chatFacts(msg) {
+		embed.Fields = append(embed.Fields, discordField{
+			Name:   fact.Name,
+			Value:  truncateRunes(fact.Value, discordFieldLimit),
+			Inline: true,
+		})
+	}
+	if source := strings.TrimSpace(msg.Source); source != "" {
+		embed.Footer = &discordFooter{Text: source}
+	}
+
+	var parts []string
+	for _, seg := range splitCodeSegments(chatMessageText(msg)) {
+		if seg.Code {
+			parts = append(parts, "```"+seg.Lang+"\n"+strings.ReplaceAll(seg.Text, "```", "`\u200b``")+"\n```")
+		} else {
+			parts = append(parts, seg.Text)
+		}
+	}
+	embed.Description = discordClip(strings.Join(parts, "\n\n"), discordDescriptionLimit)
+	return discordPayload{Username: "cc-notify", Embeds: []discordEmbed{embed}}
+}
+
+// discordClip truncates Markdown and closes a code fence left open by the cut.
+func discordClip(text string, limit int) string {
+	if len([]rune(text)) <= limit {
+		return text
+	}
+	clipped := truncateRunes(text, limit-4)
+	if strings.Count(clipped, "```")%2 == 1 {
+		clipped += "\n```"
+	}
+	return clipped
+}
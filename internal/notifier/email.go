@@ -0,0 +1,324 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Email security modes.
+const (
+	EmailSecurityStartTLS = "starttls"
+	EmailSecurityTLS      = "tls"
+	EmailSecurityNone     = "none"
+)
+
+const defaultEmailTimeout = 30 * time.Second
+
+// EmailConfig configures the SMTP email channel.
+type EmailConfig struct {
+	Host string
+	// Port defaults to 465 for implicit TLS and 587 otherwise.
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	// Security is starttls (default), tls for implicit TLS, or none.
+	Security string
+	Timeout  time.Duration
+}
+
+type emailNotifier struct {
+	host      string
+	addr      string
+	username  string
+	password  string
+	from      *mail.Address
+	to        []*mail.Address
+	security  string
+	timeout   time.Duration
+	tlsConfig *tls.Config
+	now       func() time.Time
+}
+
+// NewEmail creates a channel that sends each notification as an email.
+func NewEmail(cfg EmailConfig) (Service, error) {
+	host := strings.TrimSpace(cfg.Host)
+	if host == "" {
+		return nil, fmt.Errorf("email: smtp host is required")
+	}
+	security := strings.ToLower(strings.TrimSpace(cfg.Security))
+	switch security {
+	case "":
+		security = EmailSecurityStartTLS
+	case EmailSecurityStartTLS, EmailSecurityTLS, EmailSecurityNone:
+	default:
+		return nil, fmt.Errorf("email: unknown security mode %q (use starttls, tls or none)", cfg.Security)
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if security == EmailSecurityTLS {
+			port = 465
+		}
+	}
+	if port < 1 || port > 65535 {
+		return nil, fmt.Errorf("email: invalid port %d", cfg.Port)
+	}
+	from, err := mail.ParseAddress(strings.TrimSpace(cfg.From))
+	if err != nil {
+		return nil, fmt.Errorf("email: invalid from address: %w", err)
+	}
+	var to []*mail.Address
+	for _, raw := range cfg.To {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("email: invalid recipient %q: %w", raw, err)
+		}
+		to = append(to, addr)
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("email: at least one recipient is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultEmailTimeout
+	}
+	return &emailNotifier{
+		host:      host,
+		addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		username:  cfg.Username,
+		password:  cfg.Password,
+		from:      from,
+		to:        to,
+		security:  security,
+		timeout:   timeout,
+		tlsConfig: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12},
+		now:       time.Now,
+	}, nil
+}
+
+func (n *emailNotifier) Notify(title, body string) error {
+	return n.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (n *emailNotifier) NotifyMessage(msg Message) error {
+	data, err := n.buildMail(msg)
+	if err != nil {
+		return fmt.Errorf("send email notification: %w", err)
+	}
+	if err := n.send(data); err != nil {
+		return fmt.Errorf("send email notification: %w", err)
+	}
+	return nil
+}
+
+func (n *emailNotifier) send(data []byte) error {
+	conn, err := n.dial()
+	if err != nil {
+		return err
+	}
+	// One deadline covers the whole SMTP exchange.
+	_ = conn.SetDeadline(time.Now().Add(n.timeout))
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Hello("localhost"); err != nil {
+		return err
+	}
+	if n.security == EmailSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not offer STARTTLS", n.addr)
+		}
+		if err := client.StartTLS(n.tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(n.from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range n.to {
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("recipient %s: %w", rcpt.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (n *emailNotifier) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: n.timeout}
+	if n.security == EmailSecurityTLS {
+		return tls.DialWithDialer(dialer, "tcp", n.addr, n.tlsConfig)
+	}
+	return dialer.Dial("tcp", n.addr)
+}
+
+// buildMail renders msg as a multipart/alternative message with a plain-text
+// and an HTML part.
+func (n *emailNotifier) buildMail(msg Message) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{contentType: "text/plain; charset=utf-8", content: emailPlainText(msg)},
+		{contentType: "text/html; charset=utf-8", content: emailHTML(msg)},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		pw, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	recipients := make([]string, 0, len(n.to))
+	for _, rcpt := range n.to {
+		recipients = append(recipients, rcpt.String())
+	}
+
+	var out bytes.Buffer
+	writeHeader := func(key, value string) {
+		out.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", n.from.String())
+	writeHeader("To", strings.Join(recipients, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", emailSubject(msg)))
+	writeHeader("Date", n.now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", "<"+randomToken()+"@cc-notify>")
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", "multipart/alternative; boundary="+mw.Boundary())
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func emailSubject(msg Message) string {
+	subject := strings.TrimSpace(msg.Title)
+	if subject == "" {
+		subject = "cc-notify"
+	}
+	if name := projectName(msg.CWD); name != "" {
+		subject += " · " + name
+	}
+	return subject
+}
+
+func emailPlainText(msg Message) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(msg.Title))
+	b.WriteString("\n\n")
+	for _, fact := range chatFacts(msg) {
+		b.WriteString(fact.Name + ": " + fact.Value + "\n")
+	}
+	if cwd := strings.TrimSpace(msg.CWD); cwd != "" {
+		b.WriteString(msg.Language.Sprintf("Path: %s", cwd) + "\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.TrimSpace(chatMessageText(msg)))
+	b.WriteString("\n")
+	if note := emailApprovalNote(msg); note != "" {
+		b.WriteString("\n" + note + "\n")
+	}
+	return b.String()
+}
+
+func emailHTML(msg Message) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><body style=\"font-family:-apple-system,Segoe UI,sans-serif;font-size:14px;color:#1f2328\">\n")
+	b.WriteString("<h2 style=\"margin:0 0 12px\">" + html.EscapeString(strings.TrimSpace(msg.Title)) + "</h2>\n")
+	if facts := chatFacts(msg); len(facts) > 0 {
+		b.WriteString("<table style=\"border-collapse:collapse;margin-bottom:12px\">\n")
+		for _, fact := range facts {
+			b.WriteString("<tr><td style=\"padding:2px 12px 2px 0;color:#656d76\">" + html.EscapeString(fact.Name) +
+				"</td><td style=\"padding:2px 0\">" + html.EscapeString(fact.Value) + "</td></tr>\n")
+		}
+		b.WriteString("</table>\n")
+	}
+	for _, seg := range splitCodeSegments(chatMessageText(msg)) {
+		if seg.Code {
+			b.WriteString("<pre style=\"background:#f6f8fa;padding:12px;border-radius:6px;overflow:auto\"><code>" +
+				html.EscapeString(seg.Text) + "</code></pre>\n")
+			continue
+		}
+		for _, para := range strings.Split(seg.Text, "\n\n") {
+			if para = strings.TrimSpace(para); para != "" {
+				b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(para), "\n", "<br>") + "</p>\n")
+			}
+		}
+	}
+	if note := emailApprovalNote(msg); note != "" {
+		b.WriteString("<p style=\"color:#9a6700\"><strong>" + html.EscapeString(note) + "</strong></p>\n")
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+// emailApprovalNote explains how to answer a paused prompt, since mail
+// clients cannot open cc-notify:// action links.
+func emailApprovalNote(msg Message) string {
+	if msg.EventType != "agent-turn-paused" {
+		return ""
+	}
+	return msg.Language.T("The agent is waiting for approval. Answer the prompt on your machine or from another channel.")
+}
+
+func randomToken() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
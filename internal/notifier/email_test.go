@@ -0,0 +1,327 @@
+package notifier
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an in-process SMTP stand-in that accepts one mail per session.
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	implicit bool
+	starttls bool
+
+	mu       sync.Mutex
+	usedTLS  bool
+	authLine string
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func newFakeSMTP(t *testing.T, implicit, starttls bool) (*fakeSMTP, *x509.CertPool) {
+	t.Helper()
+	cert, pool := selfSignedCert(t)
+	srv := &fakeSMTP{
+		tls:      &tls.Config{Certificates: []tls.Certificate{cert}},
+		implicit: implicit,
+		starttls: starttls,
+		done:     make(chan struct{}),
+	}
+	var err error
+	if implicit {
+		srv.listener, err = tls.Listen("tcp", "127.0.0.1:0", srv.tls)
+	} else {
+		srv.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatalf("listen smtp: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.listener.Close() })
+	go srv.serve()
+	return srv, pool
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer close(s.done)
+	defer conn.Close()
+
+	isTLS := s.implicit
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-fake")
+			if s.starttls && !isTLS {
+				_ = tp.PrintfLine("250-STARTTLS")
+			}
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, isTLS = tlsConn, true
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			s.mu.Lock()
+			s.authLine = line
+			s.mu.Unlock()
+			_ = tp.PrintfLine("235 ok")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = line
+			s.usedTLS = isTLS
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, line)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 unsupported")
+		}
+	}
+}
+
+func (s *fakeSMTP) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("smtp session did not finish")
+	}
+}
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake smtp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func newTestEmail(t *testing.T, srv *fakeSMTP, pool *x509.CertPool, cfg EmailConfig) *emailNotifier {
+	t.Helper()
+	cfg.Host = "127.0.0.1"
+	cfg.Port = srv.port()
+	svc, err := NewEmail(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := svc.(*emailNotifier)
+	n.tlsConfig.RootCAs = pool
+	return n
+}
+
+func TestEmail_StartTLSWithAuth(t *testing.T) {
+	srv, pool := newFakeSMTP(t, false, true)
+	n := newTestEmail(t, srv, pool, EmailConfig{
+		Username: "bot",
+		Password: "pw",
+		From:     "cc-notify <bot@example.com>",
+		To:       []string{"dev@example.com", "Ops Team <ops@example.com>"},
+	})
+
+	msg := chatFixture()
+	if err := Send(n, msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv.wait(t)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.usedTLS {
+		t.Fatalf("expected the session to be upgraded with STARTTLS")
+	}
+	auth := strings.TrimPrefix(srv.authLine, "AUTH PLAIN ")
+	decoded, _ := base64.StdEncoding.DecodeString(auth)
+	if string(decoded) != "\x00bot\x00pw" {
+		t.Fatalf("unexpected auth payload: %q", decoded)
+	}
+	if srv.from != "MAIL FROM:<bot@example.com>" {
+		t.Fatalf("unexpected MAIL FROM: %q", srv.from)
+	}
+	if len(srv.rcpts) != 2 || !strings.Contains(srv.rcpts[1], "<ops@example.com>") {
+		t.Fatalf("unexpected recipients: %v", srv.rcpts)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(srv.data))
+	if err != nil {
+		t.Fatalf("parse mail: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if subject != "Codex Task Complete · cc-notify" {
+		t.Fatalf("unexpected subject: %q", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type: %q (%v)", mediaType, err)
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	bodies := map[string]string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		raw, _ := io.ReadAll(part)
+		media, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[media] = string(raw)
+	}
+	if !strings.Contains(bodies["text/plain"], "Model: gpt-5-codex") || !strings.Contains(bodies["text/plain"], "t.Parallel()") {
+		t.Fatalf("unexpected plain body: %q", bodies["text/plain"])
+	}
+	html := bodies["text/html"]
+	if !strings.Contains(html, "<pre") || !strings.Contains(html, "func TestX(t *testing.T) {") {
+		t.Fatalf("expected code block in html body: %q", html)
+	}
+	if !strings.Contains(html, "&amp; tightened &lt;timeouts&gt;") {
+		t.Fatalf("expected escaped prose in html body: %q", html)
+	}
+}
+
+func TestEmail_ImplicitTLS(t *testing.T) {
+	srv, pool := newFakeSMTP(t, true, false)
+	n := newTestEmail(t, srv, pool, EmailConfig{
+		Security: EmailSecurityTLS,
+		From:     "bot@example.com",
+		To:       []string{"dev@example.com"},
+	})
+	if err := n.Notify("Claude Needs Input", "Claude needs your permission to use Bash"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv.wait(t)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.usedTLS || srv.authLine != "" {
+		t.Fatalf("expected implicit TLS without auth, tls=%v auth=%q", srv.usedTLS, srv.authLine)
+	}
+}
+
+func TestEmail_StartTLSRequiredButNotOffered(t *testing.T) {
+	srv, pool := newFakeSMTP(t, false, false)
+	n := newTestEmail(t, srv, pool, EmailConfig{From: "bot@example.com", To: []string{"dev@example.com"}})
+	err := n.Notify("title", "body")
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected missing STARTTLS error, got %v", err)
+	}
+}
+
+func TestEmail_PlainSessionWithPausedNote(t *testing.T) {
+	srv, pool := newFakeSMTP(t, false, false)
+	n := newTestEmail(t, srv, pool, EmailConfig{
+		Security: EmailSecurityNone,
+		From:     "bot@example.com",
+		To:       []string{"dev@example.com"},
+	})
+	if err := Send(n, pausedChatFixture()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	srv.wait(t)
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.usedTLS {
+		t.Fatalf("security none must not use TLS")
+	}
+	if !strings.Contains(srv.data, "waiting for approval") {
+		t.Fatalf("expected approval note in mail: %q", srv.data)
+	}
+}
+
+func TestNewEmail_Validation(t *testing.T) {
+	cases := []EmailConfig{
+		{From: "a@example.com", To: []string{"b@example.com"}},
+		{Host: "smtp.example.com", From: "not an address", To: []string{"b@example.com"}},
+		{Host: "smtp.example.com", From: "a@example.com"},
+		{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}, Security: "ssl3"},
+		{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}, Port: 70000},
+	}
+	for i, cfg := range cases {
+		if _, err := NewEmail(cfg); err == nil {
+			t.Fatalf("case %d: expected validation error", i)
+		}
+	}
+
+	svc, err := NewEmail(EmailConfig{Host: "smtp.example.com", Security: "tls", From: "a@example.com", To: []string{"b@example.com"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if addr := svc.(*emailNotifier).addr; addr != "smtp.example.com:465" {
+		t.Fatalf("unexpected default implicit TLS address: %s", addr)
+	}
+}
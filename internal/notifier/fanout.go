@@ -0,0 +1,208 @@
+package notifier
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Channel is a named notification target within a Fanout.
+type Channel struct {
+	Name    string
+	Service Service
+	// Format, when set, adapts each message before it is sent on this
+	// channel, e.g. to fit a shorter body.
+	Format func(Message) Message
+}
+
+// Route sends matching messages to a fixed set of channels. Empty match
+// fields match anything. Source, Dir and Model are globs where * matches
+// within one path element, ** matches across elements and ? matches one
+// character. Event accepts a full event type or the short forms "complete"
+// and "paused". A Dir pattern without a slash is matched against the last
+// path element only, and a leading ~/ expands to the home directory.
+type Route struct {
+	Source   string
+	Event    string
+	Dir      string
+	Model    string
+	Channels []string
+}
+
+// ChannelError is the failure of one channel during a fan-out.
+type ChannelError struct {
+	Channel string
+	Err     error
+}
+
+func (e *ChannelError) Error() string {
+	return e.Channel + ": " + e.Err.Error()
+}
+
+func (e *ChannelError) Unwrap() error {
+	return e.Err
+}
+
+// Fanout dispatches each message to several channels at once.
+type Fanout struct {
+	channels []Channel
+	routes   []Route
+}
+
+// NewFanout creates a composite service over channels. Routes are tried in
+// order and the first match picks the channels; a message that matches no
+// route goes to every channel. Route channel names that are not configured
+// are skipped.
+func NewFanout(channels []Channel, routes []Route) *Fanout {
+	return &Fanout{channels: channels, routes: routes}
+}
+
+func (f *Fanout) Notify(title, body string) error {
+	return f.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (f *Fanout) NotifyMessage(msg Message) error {
+	_, err := f.Dispatch(msg)
+	return err
+}
+
+// Targets returns the channels msg is routed to, in configuration order.
+func (f *Fanout) Targets(msg Message) []Channel {
+	for _, route := range f.routes {
+		if !route.matches(msg) {
+			continue
+		}
+		wanted := map[string]bool{}
+		for _, name := range route.Channels {
+			wanted[strings.ToLower(strings.TrimSpace(name))] = true
+		}
+		var targets []Channel
+		for _, ch := range f.channels {
+			if wanted[strings.ToLower(ch.Name)] {
+				targets = append(targets, ch)
+			}
+		}
+		return targets
+	}
+	return f.channels
+}
+
+// Dispatch sends msg to every routed channel concurrently and returns the
+// names of the channels that accepted it, in configuration order. Failures
+// are returned together as ChannelErrors and never stop other channels.
+func (f *Fanout) Dispatch(msg Message) ([]string, error) {
+	targets := f.Targets(msg)
+	results := make([]error, len(targets))
+
+	var wg sync.WaitGroup
+	for i, ch := range targets {
+		wg.Add(1)
+		go func(i int, ch Channel) {
+			defer wg.Done()
+			msg := msg
+			if ch.Format != nil {
+				msg = ch.Format(msg)
+			}
+			if err := Send(ch.Service, msg); err != nil {
+				results[i] = &ChannelError{Channel: ch.Name, Err: err}
+			}
+		}(i, ch)
+	}
+	wg.Wait()
+
+	var sent []string
+	for i, ch := range targets {
+		if results[i] == nil {
+			sent = append(sent, ch.Name)
+		}
+	}
+	return sent, errors.Join(results...)
+}
+
+func (r Route) matches(msg Message) bool {
+	if r.Source != "" && !globMatch(strings.ToLower(r.Source), strings.ToLower(msg.Source)) {
+		return false
+	}
+	if r.Event != "" && !matchEventType(r.Event, msg.EventType) {
+		return false
+	}
+	if r.Dir != "" && !matchDir(r.Dir, msg.CWD) {
+		return false
+	}
+	if r.Model != "" && !globMatch(strings.ToLower(r.Model), strings.ToLower(msg.Model)) {
+		return false
+	}
+	return true
+}
+
+func matchEventType(pattern, eventType string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	eventType = strings.ToLower(eventType)
+	return globMatch(pattern, eventType) || globMatch("agent-turn-"+pattern, eventType)
+}
+
+// matchDir compares case-insensitively with forward slashes so the same
+// pattern works for Windows and POSIX paths.
+func matchDir(pattern, cwd string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if strings.HasPrefix(pattern, "~/") || strings.HasPrefix(pattern, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			pattern = home + pattern[1:]
+		}
+	}
+	pattern = strings.ToLower(strings.ReplaceAll(pattern, `\`, "/"))
+	cwd = strings.TrimRight(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(cwd), `\`, "/")), "/")
+	if cwd == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		return globMatch(pattern, cwd[strings.LastIndex(cwd, "/")+1:])
+	}
+	return globMatch(strings.TrimRight(pattern, "/"), cwd)
+}
+
+// globMatch reports whether value matches pattern, where * stops at '/',
+// ** crosses it ("a/**/b" also matches "a/b") and ? matches any single
+// character except '/'.
+func globMatch(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch {
+		case strings.HasPrefix(pattern, "**"):
+			rest := strings.TrimLeft(pattern, "*")
+			if strings.HasPrefix(rest, "/") && globMatch(rest[1:], value) {
+				return true
+			}
+			for i := 0; i <= len(value); i++ {
+				if globMatch(rest, value[i:]) {
+					return true
+				}
+			}
+			return false
+		case pattern[0] == '*':
+			rest := pattern[1:]
+			for i := 0; i <= len(value); i++ {
+				if globMatch(rest, value[i:]) {
+					return true
+				}
+				if i < len(value) && value[i] == '/' {
+					return false
+				}
+			}
+			return false
+		case pattern[0] == '?':
+			if value == "" || value[0] == '/' {
+				return false
+			}
+			_, size := utf8.DecodeRuneInString(value)
+			pattern, value = pattern[1:], value[size:]
+		default:
+			if value == "" || pattern[0] != value[0] {
+				return false
+			}
+			pattern, value = pattern[1:], value[1:]
+		}
+	}
+	return value == ""
+}
@@ -0,0 +1,153 @@
+package notifier
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fanoutProbe struct {
+	mu    sync.Mutex
+	msgs  []Message
+	err   error
+	delay time.Duration
+}
+
+func (r *fanoutProbe) Notify(title, body string) error {
+	return r.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (r *fanoutProbe) NotifyMessage(msg Message) error {
+	time.Sleep(r.delay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+	return r.err
+}
+
+func (r *fanoutProbe) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.msgs)
+}
+
+func TestFanoutDispatch_AggregatesChannelErrors(t *testing.T) {
+	desktop := &fanoutProbe{delay: 20 * time.Millisecond}
+	webhook := &fanoutProbe{err: errors.New("503")}
+	phone := &fanoutProbe{}
+	fan := NewFanout([]Channel{
+		{Name: "desktop", Service: desktop},
+		{Name: "webhook", Service: webhook},
+		{Name: "ntfy", Service: phone},
+	}, nil)
+
+	sent, err := fan.Dispatch(Message{Title: "t", Body: "b", EventType: "agent-turn-complete"})
+	if len(sent) != 2 || sent[0] != "desktop" || sent[1] != "ntfy" {
+		t.Fatalf("unexpected sent channels: %v", sent)
+	}
+	var chErr *ChannelError
+	if !errors.As(err, &chErr) || chErr.Channel != "webhook" || chErr.Error() != "webhook: 503" {
+		t.Fatalf("expected webhook channel error, got %v", err)
+	}
+	if desktop.count() != 1 || webhook.count() != 1 || phone.count() != 1 {
+		t.Fatalf("every channel should be attempted")
+	}
+}
+
+func TestFanoutDispatch_FormatsPerChannel(t *testing.T) {
+	desktop := &fanoutProbe{}
+	phone := &fanoutProbe{}
+	fan := NewFanout([]Channel{
+		{Name: "desktop", Service: desktop},
+		{Name: "ntfy", Service: phone, Format: func(msg Message) Message {
+			msg.Body = "short"
+			return msg
+		}},
+	}, nil)
+
+	if _, err := fan.Dispatch(Message{Title: "t", Body: "a longer body"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if desktop.msgs[0].Body != "a longer body" || phone.msgs[0].Body != "short" {
+		t.Fatalf("unexpected bodies: %q / %q", desktop.msgs[0].Body, phone.msgs[0].Body)
+	}
+	if phone.msgs[0].Title != "t" {
+		t.Fatalf("format should keep other fields, got %+v", phone.msgs[0])
+	}
+}
+
+func TestFanoutTargets_FirstMatchingRouteWins(t *testing.T) {
+	channels := []Channel{
+		{Name: "desktop", Service: &fanoutProbe{}},
+		{Name: "telegram", Service: &fanoutProbe{}},
+		{Name: "slack", Service: &fanoutProbe{}},
+	}
+	fan := NewFanout(channels, []Route{
+		{Source: "codex", Event: "paused", Channels: []string{"Telegram", "missing"}},
+		{Dir: "~/work/**", Model: "gpt-5*", Channels: []string{"slack", "desktop"}},
+		{Dir: "scratch", Channels: nil},
+	})
+
+	cases := []struct {
+		name string
+		msg  Message
+		want []string
+	}{
+		{name: "paused codex", msg: Message{Source: "codex", EventType: "agent-turn-paused"}, want: []string{"telegram"}},
+		{name: "paused claude falls through", msg: Message{Source: "claude", EventType: "agent-turn-paused", CWD: "/tmp/x"}, want: []string{"desktop", "telegram", "slack"}},
+		{name: "work dir and model", msg: Message{Source: "claude", EventType: "agent-turn-complete", CWD: homeDir(t) + "/work/api/svc", Model: "GPT-5-codex"}, want: []string{"desktop", "slack"}},
+		{name: "model mismatch", msg: Message{Source: "claude", CWD: homeDir(t) + "/work/api", Model: "o3"}, want: []string{"desktop", "telegram", "slack"}},
+		{name: "muted basename", msg: Message{Source: "codex", CWD: `C:\Users\dev\Scratch\`}, want: nil},
+	}
+	for _, tc := range cases {
+		var got []string
+		for _, ch := range fan.Targets(tc.msg) {
+			got = append(got, ch.Name)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: targets = %v, want %v", tc.name, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%s: targets = %v, want %v", tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"*", "codex", true},
+		{"*", "a/b", false},
+		{"/home/*/src", "/home/dev/src", true},
+		{"/home/**", "/home/dev/src/app", true},
+		{"/home/**/app", "/home/dev/src/app", true},
+		{"/home/**/app", "/home/app", true},
+		{"/home/**/app", "/home/webapp", false},
+		{"c:/src/*", "c:/src/api", true},
+		{"gpt-?", "gpt-5", true},
+		{"项目?", "项目一", true},
+		{"gpt-?", "gpt-55", false},
+		{"claude", "codex", false},
+	}
+	for _, tc := range cases {
+		if got := globMatch(tc.pattern, tc.value); got != tc.want {
+			t.Fatalf("globMatch(%q, %q) = %v, want %v", tc.pattern, tc.value, got, tc.want)
+		}
+	}
+}
+
+func homeDir(t *testing.T) string {
+	t.Helper()
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+	return strings.ReplaceAll(home, `\`, "/")
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package notifier
+
+import (
+	"errors"
+	"io"
+)
+
+// RunActionListener only runs on Linux, whose desktop notifications wait
+// for their actions in a listener process.
+func RunActionListener(_ io.Reader, _ io.Writer, _ func(uri string) error) error {
+	return errors.New("notification listener is only supported on linux")
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import "cc-notify/internal/i18n"
+
+// Message is a rendered notification together with the event it describes.
+// Channels that only show text use Title and Body; richer channels can use
+// the remaining context.
+type Message struct {
+	Title string
+	Body  string
+	// Text is the full, untruncated agent message for channels that can
+	// show long content. It may be empty.
+	Text           string
+	Source         string
+	EventType      string
+	CWD            string
+	Model          string
+	TranscriptPath string
+	Actions        []Action
+	// Tag and Group identify the notification on backends that can update
+	// it in place: a new message with the same tag and group replaces the
+	// previous one, and ActionService.Dismiss removes it. See SessionTag and
+	// ProjectGroup.
+	Tag   string
+	Group string
+	// Pending is the file of the approval that Actions answer. Backends
+	// that wait for the click in the background stop once it is removed,
+	// which happens when the approval is answered elsewhere or times out.
+	Pending string
+	// Language translates the labels a backend adds around the message,
+	// such as chat fact names.
+	Language i18n.Language
+}
+
+// MessageService accepts the full message context instead of title/body only.
+type MessageService interface {
+	Service
+	NotifyMessage(msg Message) error
+}
+
+// Send delivers msg through svc using the richest interface svc implements.
+func Send(svc Service, msg Message) error {
+	if ms, ok := svc.(MessageService); ok {
+		return ms.NotifyMessage(msg)
+	}
+	if len(msg.Actions) > 0 {
+		if as, ok := svc.(ActionService); ok {
+			return as.NotifyWithActions(msg.Title, msg.Body, msg.Actions)
+		}
+	}
+	return svc.Notify(msg.Title, msg.Body)
+}
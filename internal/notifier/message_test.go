@@ -0,0 +1,35 @@
+package notifier
+
+import "testing"
+
+type recordingService struct {
+	plain   int
+	actions int
+}
+
+func (r *recordingService) Notify(_, _ string) error {
+	r.plain++
+	return nil
+}
+
+func (r *recordingService) NotifyWithActions(_, _ string, _ []Action) error {
+	r.actions++
+	return nil
+}
+
+func (r *recordingService) Dismiss(_, _ string) error {
+	return nil
+}
+
+func TestSend_PrefersActionsWhenPresent(t *testing.T) {
+	svc := &recordingService{}
+	if err := Send(svc, Message{Title: "t", Body: "b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Send(svc, Message{Title: "t", Body: "b", Actions: []Action{{Label: "Yes", URI: "cc-notify://respond"}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if svc.plain != 1 || svc.actions != 1 {
+		t.Fatalf("unexpected dispatch counts: plain=%d actions=%d", svc.plain, svc.actions)
+	}
+}
@@ -1,10 +1,15 @@
 package notifier
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 	"unicode/utf16"
+
+	"cc-notify/internal/i18n"
 )
 
 // Service sends user-facing notifications.
@@ -16,102 +21,164 @@ type Service interface {
 type Action struct {
 	Label string
 	URI   string
+	// Input, when set, asks for free text before the action runs and is
+	// the placeholder of the text box. Backends that can collect text add
+	// it to URI as the FeedbackParam query parameter; others ignore it.
+	Input string
+}
+
+// FeedbackParam is the URI query parameter that carries the text typed for
+// an Action with Input.
+const FeedbackParam = "feedback"
+
+// ListenArg is the hidden cc-notify argument that runs the listener of a
+// Linux desktop notification with actions; see RunActionListener.
+const ListenArg = "--listen-notification"
+
+// hasInput reports whether any action asks for text.
+func hasInput(actions []Action) bool {
+	for _, action := range actions {
+		if action.Input != "" {
+			return true
+		}
+	}
+	return false
 }
 
 // ActionService supports actionable notifications with buttons.
 type ActionService interface {
 	Service
 	NotifyWithActions(title, body string, actions []Action) error
+	// Dismiss removes the notification sent with tag and group, typically
+	// an approval prompt answered elsewhere. Backends that cannot remove
+	// notifications return nil.
+	Dismiss(tag, group string) error
 }
 
-func buildToastScript(title, body, appID string) string {
-	return buildToastScriptWithActions(title, body, appID, nil)
+// SessionTag derives a notification tag from a session key such as a
+// session ID or transcript path. Tags are short hashes because Windows
+// limits them to 64 characters. An empty key gives an empty tag.
+func SessionTag(key string) string {
+	return shortHash(strings.TrimSpace(key))
 }
 
-func buildToastScriptWithActions(title, body, appID string, actions []Action) string {
-	titleB64 := base64.StdEncoding.EncodeToString([]byte(title))
-	bodyB64 := base64.StdEncoding.EncodeToString([]byte(body))
-	appIDB64 := base64.StdEncoding.EncodeToString([]byte(appID))
-	labelArray := base64ArrayFromActions(actions, func(a Action) string { return a.Label })
-	uriArray := base64ArrayFromActions(actions, func(a Action) string { return a.URI })
+// ProjectGroup derives a notification group from a project directory.
+func ProjectGroup(cwd string) string {
+	return shortHash(normalizeDir(cwd))
+}
+
+// normalizeDir makes Windows paths compare equal regardless of case and
+// separator.
+func normalizeDir(dir string) string {
+	dir = strings.TrimRight(strings.TrimSpace(dir), `/\`)
+	return strings.ToLower(strings.ReplaceAll(dir, `\`, "/"))
+}
 
+func shortHash(key string) string {
+	if key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// buildToastXMLScript shows a finished toast document under appID. A non-empty
+// tag and group make the toast replace the previous one with the same pair.
+// All values travel base64-encoded so PowerShell never interprets them.
+func buildToastXMLScript(toastXML, appID, tag, group string) string {
 	return fmt.Sprintf(
 		`$ErrorActionPreference = 'Stop'
 $null = [Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime]
 $null = [Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime]
-$title = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
-$body = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
+$toastXml = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
 $appId = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
-$actionLabels = %s
-$actionUris = %s
-$xmlContent = "<toast><visual><binding template='ToastGeneric'><text></text><text></text></binding></visual></toast>"
+$tag = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
+$group = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
 $xml = New-Object Windows.Data.Xml.Dom.XmlDocument
-$xml.LoadXml($xmlContent)
-$textNodes = $xml.GetElementsByTagName('text')
-$null = $textNodes.Item(0).AppendChild($xml.CreateTextNode($title))
-$null = $textNodes.Item(1).AppendChild($xml.CreateTextNode($body))
-if ($actionLabels.Count -eq $actionUris.Count -and $actionLabels.Count -gt 0) {
-  $actionsNode = $xml.CreateElement('actions')
-  for ($i = 0; $i -lt $actionLabels.Count; $i++) {
-    $label = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionLabels[$i]))
-    $uri = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionUris[$i]))
-    if ([string]::IsNullOrWhiteSpace($label) -or [string]::IsNullOrWhiteSpace($uri)) {
-      continue
-    }
-    $actionNode = $xml.CreateElement('action')
-    $null = $actionNode.SetAttribute('content', $label)
-    $null = $actionNode.SetAttribute('activationType', 'protocol')
-    $null = $actionNode.SetAttribute('arguments', $uri)
-    $null = $actionsNode.AppendChild($actionNode)
-  }
-  if ($actionsNode.HasChildNodes) {
-    $null = $xml.DocumentElement.AppendChild($actionsNode)
-  }
-}
+$xml.LoadXml($toastXml)
 $toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+if ($tag -ne '') { $toast.Tag = $tag }
+if ($group -ne '') { $toast.Group = $group }
 [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($appId).Show($toast)
 `,
-		titleB64,
-		bodyB64,
-		appIDB64,
-		labelArray,
-		uriArray,
+		base64.StdEncoding.EncodeToString([]byte(toastXML)),
+		base64.StdEncoding.EncodeToString([]byte(appID)),
+		base64.StdEncoding.EncodeToString([]byte(tag)),
+		base64.StdEncoding.EncodeToString([]byte(group)),
+	)
+}
+
+// buildToastRemoveScript removes the toast with tag and group from Action
+// Center. Removing a toast that is already gone is not an error.
+func buildToastRemoveScript(appID, tag, group string) string {
+	return fmt.Sprintf(
+		`$ErrorActionPreference = 'Stop'
+$null = [Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime]
+$appId = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
+$tag = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
+$group = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
+[Windows.UI.Notifications.ToastNotificationManager]::History.Remove($tag, $group, $appId)
+`,
+		base64.StdEncoding.EncodeToString([]byte(appID)),
+		base64.StdEncoding.EncodeToString([]byte(tag)),
+		base64.StdEncoding.EncodeToString([]byte(group)),
 	)
 }
 
 func buildPopupScript(title, body string) string {
-	return buildPopupScriptWithActions(title, body, nil)
+	return buildPopupScriptWithActions(title, body, nil, "")
 }
 
-func buildPopupScriptWithActions(title, body string, actions []Action) string {
+// buildPopupScriptWithActions shows a message box. Two actions map to its
+// Yes and No buttons and three to Yes, No and Cancel; the box cannot
+// relabel them, so a legend in lang below body tells which button does
+// what. An action with Input asks for its text in a second box.
+func buildPopupScriptWithActions(title, body string, actions []Action, lang i18n.Language) string {
 	titleB64 := base64.StdEncoding.EncodeToString([]byte(title))
 	bodyB64 := base64.StdEncoding.EncodeToString([]byte(body))
-	labelArray := base64ArrayFromActions(actions, func(a Action) string { return a.Label })
 	uriArray := base64ArrayFromActions(actions, func(a Action) string { return a.URI })
+	inputArray := base64ArrayFromActions(actions, func(a Action) string { return a.Input })
+	var legend []string
+	if len(actions) == 2 || len(actions) == 3 {
+		legend = append(legend,
+			lang.Sprintf("Yes -> %s", actions[0].Label),
+			lang.Sprintf("No -> %s", actions[1].Label))
+	}
+	if len(actions) == 3 {
+		legend = append(legend, lang.Sprintf("Cancel -> %s", actions[2].Label))
+	}
 
 	return fmt.Sprintf(
 		`$ErrorActionPreference = 'Stop'
+function Decode([string]$value) { [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($value)) }
 $title = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
 $body = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String('%s'))
-$actionLabels = %s
+$legend = Decode '%s'
 $actionUris = %s
+$actionInputs = %s
 $wshell = New-Object -ComObject WScript.Shell
-if ($actionLabels.Count -eq 3 -and $actionUris.Count -eq 3) {
-  $yesLabel = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionLabels[0]))
-  $noLabel = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionLabels[1]))
-  $cancelLabel = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionLabels[2]))
+function Invoke-Action([int]$index) {
+  $uri = Decode $actionUris[$index]
+  if ([string]::IsNullOrWhiteSpace($uri)) { return }
+  $prompt = Decode $actionInputs[$index]
+  if ($prompt -ne '') {
+    Add-Type -AssemblyName Microsoft.VisualBasic
+    $text = [Microsoft.VisualBasic.Interaction]::InputBox($prompt, $title, '')
+    if (-not [string]::IsNullOrWhiteSpace($text)) { $uri = $uri + '&%s=' + [System.Uri]::EscapeDataString($text) }
+  }
+  Start-Process $uri | Out-Null
+}
+if ($actionUris.Count -eq 2 -or $actionUris.Count -eq 3) {
   $nl = [Environment]::NewLine
-  $msg = $body + $nl + $nl + "Yes -> " + $yesLabel + $nl + "No -> " + $noLabel + $nl + "Cancel -> " + $cancelLabel
-  $choice = $wshell.Popup($msg, 25, $title, 0x43)
+  $buttons = 0x44
+  if ($actionUris.Count -eq 3) { $buttons = 0x43 }
+  $choice = $wshell.Popup($body + $nl + $nl + $legend, 25, $title, $buttons)
   if ($choice -eq 6) {
-    $uri = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionUris[0]))
-    if (-not [string]::IsNullOrWhiteSpace($uri)) { Start-Process $uri | Out-Null }
+    Invoke-Action 0
   } elseif ($choice -eq 7) {
-    $uri = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionUris[1]))
-    if (-not [string]::IsNullOrWhiteSpace($uri)) { Start-Process $uri | Out-Null }
+    Invoke-Action 1
   } elseif ($choice -eq 2) {
-    $uri = [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($actionUris[2]))
-    if (-not [string]::IsNullOrWhiteSpace($uri)) { Start-Process $uri | Out-Null }
+    Invoke-Action 2
   }
 } else {
   $null = $wshell.Popup($body, 8, $title, 0x40)
@@ -119,8 +186,84 @@ if ($actionLabels.Count -eq 3 -and $actionUris.Count -eq 3) {
 `,
 		titleB64,
 		bodyB64,
-		labelArray,
+		base64.StdEncoding.EncodeToString([]byte(strings.Join(legend, "\r\n"))),
 		uriArray,
+		inputArray,
+		FeedbackParam,
+	)
+}
+
+// buildToastListenScript shows a toast with a text box and stays alive until
+// it is clicked or wait elapses. Windows drops toast input on protocol
+// activation, so buttons that take the text use foreground activation and
+// this script launches their URI itself with the text appended as
+// FeedbackParam. When no app id can show the toast, fallback runs instead.
+// A non-empty pending is the Windows path of the approval file; once it is
+// gone the approval was answered elsewhere or expired, and the toast is
+// hidden.
+func buildToastListenScript(toastXML string, appIDs []string, tag, group, pending, fallback string, wait time.Duration) string {
+	ids := make([]string, 0, len(appIDs))
+	for _, id := range appIDs {
+		ids = append(ids, "'"+base64.StdEncoding.EncodeToString([]byte(id))+"'")
+	}
+	return fmt.Sprintf(
+		`$ErrorActionPreference = 'Stop'
+$null = [Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime]
+$null = [Windows.UI.Notifications.ToastActivatedEventArgs, Windows.UI.Notifications, ContentType = WindowsRuntime]
+$null = [Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime]
+function Decode([string]$value) { [System.Text.Encoding]::UTF8.GetString([System.Convert]::FromBase64String($value)) }
+$toastXml = Decode '%s'
+$appIds = @(%s)
+$tag = Decode '%s'
+$group = Decode '%s'
+$pending = Decode '%s'
+$fallback = Decode '%s'
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml($toastXml)
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+if ($tag -ne '') { $toast.Tag = $tag }
+if ($group -ne '') { $toast.Group = $group }
+$null = Register-ObjectEvent -InputObject $toast -EventName Activated -SourceIdentifier ccNotifyActivated
+$toastNotifier = $null
+foreach ($id in $appIds) {
+  try {
+    $candidate = [Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier((Decode $id))
+    $candidate.Show($toast)
+    $toastNotifier = $candidate
+    break
+  } catch {}
+}
+if ($null -eq $toastNotifier) {
+  & ([scriptblock]::Create($fallback))
+  exit 0
+}
+$deadline = (Get-Date).AddSeconds(%d)
+$activated = $null
+while ($null -eq $activated -and (Get-Date) -lt $deadline) {
+  $activated = Wait-Event -SourceIdentifier ccNotifyActivated -Timeout 1
+  if ($null -eq $activated -and $pending -ne '' -and -not (Test-Path -LiteralPath $pending)) {
+    try { $toastNotifier.Hide($toast) } catch {}
+    exit 0
+  }
+}
+if ($null -eq $activated) { exit 0 }
+$activation = [Windows.UI.Notifications.ToastActivatedEventArgs]$activated.SourceArgs[1]
+$uri = $activation.Arguments
+if ($uri -notlike 'cc-notify:*') { exit 0 }
+$text = ''
+try { $text = [string]$activation.UserInput['%s'] } catch {}
+if (-not [string]::IsNullOrWhiteSpace($text)) { $uri = $uri + '&%s=' + [System.Uri]::EscapeDataString($text) }
+Start-Process $uri | Out-Null
+`,
+		base64.StdEncoding.EncodeToString([]byte(toastXML)),
+		strings.Join(ids, ","),
+		base64.StdEncoding.EncodeToString([]byte(tag)),
+		base64.StdEncoding.EncodeToString([]byte(group)),
+		base64.StdEncoding.EncodeToString([]byte(pending)),
+		base64.StdEncoding.EncodeToString([]byte(fallback)),
+		int(wait/time.Second),
+		FeedbackParam,
+		FeedbackParam,
 	)
 }
 
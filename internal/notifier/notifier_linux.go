@@ -0,0 +1,358 @@
+//go:build linux
+
+package notifier
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	notificationsBusName   = "org.freedesktop.Notifications"
+	notificationsPath      = dbusObjectPath("/org/freedesktop/Notifications")
+	notificationsInterface = "org.freedesktop.Notifications"
+
+	// defaultActionWait matches the lifetime of a pending approval.
+	defaultActionWait = 15 * time.Minute
+
+	// pendingPoll is how often a listener checks that its approval is
+	// still pending.
+	pendingPoll = time.Second
+
+	// listenReady is the line a listener prints once its notification is
+	// on screen; anything else is the error showing it.
+	listenReady = "ready"
+)
+
+// freedesktopNotifier sends notifications through the org.freedesktop.Notifications
+// service on the session bus.
+type freedesktopNotifier struct {
+	dial       func() (*dbusConn, error)
+	appName    string
+	onAction   func(uri string) error
+	actionWait time.Duration
+	// listen hands a notification with actions to a process that shows it
+	// and waits for the click, so the caller does not block; nil shows it
+	// and waits here.
+	listen func(req listenRequest) error
+}
+
+// listenRequest is the notification a listener process shows, passed to
+// it as JSON on stdin.
+type listenRequest struct {
+	Title   string   `json:"title"`
+	Body    string   `json:"body"`
+	Actions []Action `json:"actions"`
+	Pending string   `json:"pending,omitempty"`
+}
+
+// New creates a Linux notifier backed by the freedesktop notification service.
+func New() Service {
+	return NewWithConfig(Config{
+		Mode:          os.Getenv("CC_NOTIFY_MODE"),
+		ToastAppID:    os.Getenv("CC_NOTIFY_TOAST_APP_ID"),
+		TerminalStyle: os.Getenv("CC_NOTIFY_TERMINAL_STYLE"),
+	})
+}
+
+// NewWithConfig creates a Linux notifier with explicit config values. Mode
+// "terminal" selects the escape-sequence backend instead of D-Bus, and inside
+// WSL notifications go to the Windows host.
+func NewWithConfig(cfg Config) Service {
+	if IsTerminalMode(cfg.Mode) {
+		return newTerminalNotifier(cfg)
+	}
+	if host, ok := DetectWSL(); ok {
+		return newWSLNotifier(host, cfg)
+	}
+	onAction := cfg.ActionHandler
+	if onAction == nil {
+		onAction = launchActionURI
+	}
+	return &freedesktopNotifier{
+		dial:       dialSessionBus,
+		appName:    "cc-notify",
+		onAction:   onAction,
+		actionWait: defaultActionWait,
+		listen:     startActionListener,
+	}
+}
+
+func dialSessionBus() (*dbusConn, error) {
+	addr, err := sessionBusAddress(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return dialDBus(addr)
+}
+
+// launchActionURI hands an action URI to a fresh cc-notify process, mirroring
+// what the Windows protocol handler does for toast buttons.
+func launchActionURI(uri string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve executable: %w", err)
+	}
+	output, err := exec.Command(exe, uri).CombinedOutput()
+	if err != nil {
+		text := strings.TrimSpace(string(output))
+		if text == "" {
+			return err
+		}
+		return fmt.Errorf("%w: %s", err, text)
+	}
+	return nil
+}
+
+// startActionListener starts a detached cc-notify process that shows req
+// and waits for its action, and returns once the notification is shown.
+func startActionListener(req listenRequest) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("send desktop notification: resolve executable: %w", err)
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("send desktop notification: %w", err)
+	}
+	cmd := exec.Command(exe, ListenArg)
+	cmd.Stdin = bytes.NewReader(data)
+	// A session of its own keeps the listener alive when the agent ends
+	// the hook that started it.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("send desktop notification: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("send desktop notification: start listener: %w", err)
+	}
+
+	line := make(chan string, 1)
+	go func() {
+		text, _ := bufio.NewReader(out).ReadString('\n')
+		line <- strings.TrimSpace(text)
+	}()
+	select {
+	case text := <-line:
+		switch text {
+		case listenReady:
+			return cmd.Process.Release()
+		case "":
+			text = "listener exited"
+		}
+		_ = cmd.Wait()
+		return errors.New(text)
+	case <-time.After(2 * dbusCallTimeout):
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("send desktop notification: listener did not start")
+	}
+}
+
+// RunActionListener is the body of the listener process started for a
+// notification with actions: it shows the notification read from stdin,
+// reports on stdout that it is shown, and hands the action clicked to
+// onAction. It gives up when the approval answered by the actions is no
+// longer pending.
+func RunActionListener(stdin io.Reader, stdout io.Writer, onAction func(uri string) error) error {
+	var req listenRequest
+	if err := json.NewDecoder(stdin).Decode(&req); err != nil {
+		return fmt.Errorf("read notification: %w", err)
+	}
+	n := &freedesktopNotifier{
+		dial:       dialSessionBus,
+		appName:    "cc-notify",
+		onAction:   onAction,
+		actionWait: defaultActionWait,
+	}
+	shown := false
+	err := n.show(req, func() {
+		shown = true
+		fmt.Fprintln(stdout, listenReady)
+	})
+	if err != nil && !shown {
+		fmt.Fprintln(stdout, err)
+	}
+	return err
+}
+
+func (n *freedesktopNotifier) Notify(title, body string) error {
+	return n.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (n *freedesktopNotifier) NotifyWithActions(title, body string, actions []Action) error {
+	return n.NotifyMessage(Message{Title: title, Body: body, Actions: actions})
+}
+
+func (n *freedesktopNotifier) NotifyMessage(msg Message) error {
+	req := listenRequest{Title: msg.Title, Body: msg.Body, Actions: msg.Actions, Pending: msg.Pending}
+	if len(req.Actions) > 0 && n.listen != nil {
+		return n.listen(req)
+	}
+	return n.show(req, nil)
+}
+
+// Dismiss does nothing; freedesktop notifications are not tracked after
+// they are sent.
+func (n *freedesktopNotifier) Dismiss(_, _ string) error {
+	return nil
+}
+
+// show sends the notification of req and, when it has actions, waits for
+// the click. shown, when set, is called once the notification is on screen.
+func (n *freedesktopNotifier) show(req listenRequest, shown func()) error {
+	title, body, actions := req.Title, req.Body, req.Actions
+	conn, err := n.dial()
+	if err != nil {
+		return fmt.Errorf("send desktop notification: %w", err)
+	}
+	defer conn.Close()
+
+	caps, err := notificationCapabilities(conn)
+	if err != nil {
+		return fmt.Errorf("send desktop notification: %w", err)
+	}
+	if caps["body-markup"] {
+		body = escapeNotificationMarkup(body)
+	}
+
+	var actionList []string
+	keyToURI := map[string]string{}
+	if caps["actions"] {
+		for i, action := range actions {
+			if strings.TrimSpace(action.Label) == "" || strings.TrimSpace(action.URI) == "" {
+				continue
+			}
+			key := "action-" + strconv.Itoa(i)
+			keyToURI[key] = action.URI
+			actionList = append(actionList, key, action.Label)
+		}
+	}
+	if actionList == nil {
+		actionList = []string{}
+	}
+
+	urgency := byte(1)
+	expire := int32(-1)
+	if len(keyToURI) > 0 {
+		// Approval prompts stay on screen until answered.
+		urgency = 2
+		expire = 0
+		if _, err := conn.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "AddMatch", "s",
+			"type='signal',interface='"+notificationsInterface+"'"); err != nil {
+			return fmt.Errorf("send desktop notification: %w", err)
+		}
+	}
+	hints := map[string]dbusVariant{
+		"urgency":       {Sig: "y", Value: urgency},
+		"desktop-entry": {Sig: "s", Value: n.appName},
+	}
+
+	reply, err := conn.call(notificationsBusName, notificationsPath, notificationsInterface, "Notify", "susssasa{sv}i",
+		n.appName, uint32(0), "", title, body, actionList, hints, expire)
+	if err != nil {
+		return fmt.Errorf("send desktop notification: %w", err)
+	}
+	if shown != nil {
+		shown()
+	}
+	if len(keyToURI) == 0 {
+		return nil
+	}
+	id, ok := firstUint32(reply)
+	if !ok {
+		return fmt.Errorf("send desktop notification: unexpected Notify reply %v", reply)
+	}
+	return n.waitForAction(conn, id, keyToURI, req.Pending)
+}
+
+// waitForAction blocks until the user picks an action, the notification is
+// dismissed, the approval at pending is gone, or the wait times out.
+func (n *freedesktopNotifier) waitForAction(conn *dbusConn, id uint32, keyToURI map[string]string, pending string) error {
+	timer := time.NewTimer(n.actionWait)
+	defer timer.Stop()
+	ticker := time.NewTicker(pendingPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if pending == "" {
+				continue
+			}
+			if _, err := os.Stat(pending); errors.Is(err, os.ErrNotExist) {
+				_, _ = conn.call(notificationsBusName, notificationsPath, notificationsInterface, "CloseNotification", "u", id)
+				return nil
+			}
+		case sig, ok := <-conn.Signals():
+			if !ok {
+				return fmt.Errorf("wait for notification action: bus connection closed")
+			}
+			if sig.Interface != notificationsInterface {
+				continue
+			}
+			if sigID, ok := firstUint32(sig.Body); !ok || sigID != id {
+				continue
+			}
+			switch sig.Member {
+			case "ActionInvoked":
+				if len(sig.Body) < 2 {
+					continue
+				}
+				key, _ := sig.Body[1].(string)
+				uri, ok := keyToURI[key]
+				if !ok {
+					continue
+				}
+				_, _ = conn.call(notificationsBusName, notificationsPath, notificationsInterface, "CloseNotification", "u", id)
+				return n.onAction(uri)
+			case "NotificationClosed":
+				return nil
+			}
+		case <-timer.C:
+			_, _ = conn.call(notificationsBusName, notificationsPath, notificationsInterface, "CloseNotification", "u", id)
+			return nil
+		}
+	}
+}
+
+func notificationCapabilities(conn *dbusConn) (map[string]bool, error) {
+	reply, err := conn.call(notificationsBusName, notificationsPath, notificationsInterface, "GetCapabilities", "")
+	if err != nil {
+		return nil, err
+	}
+	caps := map[string]bool{}
+	if len(reply) > 0 {
+		items, _ := reply[0].([]interface{})
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				caps[s] = true
+			}
+		}
+	}
+	return caps, nil
+}
+
+func firstUint32(values []interface{}) (uint32, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	v, ok := values[0].(uint32)
+	return v, ok
+}
+
+func escapeNotificationMarkup(text string) string {
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(text)
+}
@@ -0,0 +1,395 @@
+//go:build linux
+
+package notifier
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBus is a private stand-in for dbus-daemon plus a notification server.
+// It accepts one client at a time and answers the calls cc-notify makes.
+type fakeBus struct {
+	listener net.Listener
+	addr     string
+	caps     []string
+	// invoke is the action key the fake server "clicks" after Notify.
+	invoke string
+
+	mu     sync.Mutex
+	calls  []*dbusMessage
+	closed []uint32
+}
+
+func newFakeBus(t *testing.T, caps ...string) *fakeBus {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bus")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen fake bus: %v", err)
+	}
+	bus := &fakeBus{listener: listener, addr: "unix:path=" + path, caps: caps}
+	t.Cleanup(func() { _ = listener.Close() })
+	go bus.serve()
+	return bus
+}
+
+func (b *fakeBus) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *fakeBus) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(strings.TrimPrefix(line, "\x00"), "AUTH EXTERNAL") {
+		return
+	}
+	_, _ = conn.Write([]byte("OK 0123456789abcdef0123456789abcdef\r\n"))
+	if line, err = r.ReadString('\n'); err != nil || strings.TrimSpace(line) != "BEGIN" {
+		return
+	}
+
+	serial := uint32(100)
+	reply := func(call *dbusMessage, sig string, body ...interface{}) {
+		serial++
+		data, err := encodeDBusMessage(&dbusMessage{
+			Type:        dbusMethodReturn,
+			Serial:      serial,
+			ReplySerial: call.Serial,
+			Signature:   sig,
+			Body:        body,
+		})
+		if err == nil {
+			_, _ = conn.Write(data)
+		}
+	}
+	signal := func(member, sig string, body ...interface{}) {
+		serial++
+		data, err := encodeDBusMessage(&dbusMessage{
+			Type:      dbusSignal,
+			Serial:    serial,
+			Path:      notificationsPath,
+			Interface: notificationsInterface,
+			Member:    member,
+			Signature: sig,
+			Body:      body,
+		})
+		if err == nil {
+			_, _ = conn.Write(data)
+		}
+	}
+
+	for {
+		msg, err := readDBusMessage(r)
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.calls = append(b.calls, msg)
+		b.mu.Unlock()
+
+		switch msg.Member {
+		case "Hello":
+			reply(msg, "s", ":1.42")
+		case "AddMatch":
+			reply(msg, "")
+		case "GetCapabilities":
+			reply(msg, "as", append([]string{}, b.caps...))
+		case "Notify":
+			reply(msg, "u", uint32(7))
+			if b.invoke != "" {
+				signal("ActionInvoked", "us", uint32(99), "action-0")
+				signal("ActionInvoked", "us", uint32(7), b.invoke)
+			}
+		case "CloseNotification":
+			if id, ok := firstUint32(msg.Body); ok {
+				b.mu.Lock()
+				b.closed = append(b.closed, id)
+				b.mu.Unlock()
+			}
+			reply(msg, "")
+		}
+	}
+}
+
+func (b *fakeBus) call(member string) *dbusMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, msg := range b.calls {
+		if msg.Member == member {
+			return msg
+		}
+	}
+	return nil
+}
+
+func newTestFreedesktopNotifier(bus *fakeBus, onAction func(string) error) *freedesktopNotifier {
+	return &freedesktopNotifier{
+		dial:       func() (*dbusConn, error) { return dialDBus(bus.addr) },
+		appName:    "cc-notify",
+		onAction:   onAction,
+		actionWait: 2 * time.Second,
+	}
+}
+
+func TestFreedesktopNotifierNotify_SendsNotifyCall(t *testing.T) {
+	bus := newFakeBus(t, "body", "body-markup")
+	n := newTestFreedesktopNotifier(bus, nil)
+
+	if err := n.Notify("Codex Task Complete", "fixed <b> & tests"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := bus.call("Notify")
+	if call == nil {
+		t.Fatalf("expected Notify call on the bus")
+	}
+	if call.Destination != notificationsBusName || call.Path != notificationsPath {
+		t.Fatalf("unexpected Notify target: %s %s", call.Destination, call.Path)
+	}
+	if call.Signature != "susssasa{sv}i" {
+		t.Fatalf("unexpected Notify signature: %q", call.Signature)
+	}
+	if got := call.Body[3]; got != "Codex Task Complete" {
+		t.Fatalf("unexpected summary: %v", got)
+	}
+	if got := call.Body[4]; got != "fixed &lt;b&gt; &amp; tests" {
+		t.Fatalf("expected markup-escaped body, got %v", got)
+	}
+	if got := call.Body[7]; got != int32(-1) {
+		t.Fatalf("expected default expire timeout, got %v", got)
+	}
+	if bus.call("AddMatch") != nil {
+		t.Fatalf("plain notifications should not subscribe to signals")
+	}
+}
+
+func TestFreedesktopNotifierNotifyWithActions_DispatchesInvokedAction(t *testing.T) {
+	bus := newFakeBus(t, "actions", "body")
+	bus.invoke = "action-1"
+	var got []string
+	n := newTestFreedesktopNotifier(bus, func(uri string) error {
+		got = append(got, uri)
+		return nil
+	})
+
+	err := n.NotifyWithActions("Codex Needs Input", "Run `ls`?", []Action{
+		{Label: "Yes, proceed", URI: "cc-notify://respond?decision=proceed&id=0123456789abcdef"},
+		{Label: "Yes, always", URI: "cc-notify://respond?decision=proceed-always&id=0123456789abcdef"},
+		{Label: "No", URI: "cc-notify://respond?decision=reject&id=0123456789abcdef"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "cc-notify://respond?decision=proceed-always&id=0123456789abcdef" {
+		t.Fatalf("unexpected dispatched actions: %v", got)
+	}
+
+	call := bus.call("Notify")
+	actions, _ := call.Body[5].([]interface{})
+	if len(actions) != 6 || actions[0] != "action-0" || actions[1] != "Yes, proceed" {
+		t.Fatalf("unexpected action list: %v", actions)
+	}
+	if got := call.Body[7]; got != int32(0) {
+		t.Fatalf("expected approval prompt to never expire, got %v", got)
+	}
+	if bus.call("AddMatch") == nil {
+		t.Fatalf("expected signal subscription before Notify")
+	}
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if len(bus.closed) != 1 || bus.closed[0] != 7 {
+		t.Fatalf("expected answered notification to be closed, got %v", bus.closed)
+	}
+}
+
+func TestFreedesktopNotifierNotifyWithActions_WithoutActionSupport(t *testing.T) {
+	bus := newFakeBus(t, "body")
+	called := false
+	n := newTestFreedesktopNotifier(bus, func(string) error {
+		called = true
+		return nil
+	})
+
+	err := n.NotifyWithActions("title", "body", []Action{{Label: "Yes", URI: "cc-notify://respond?id=1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if called {
+		t.Fatalf("no action should be dispatched without server support")
+	}
+	actions, _ := bus.call("Notify").Body[5].([]interface{})
+	if len(actions) != 0 {
+		t.Fatalf("expected no actions to be advertised, got %v", actions)
+	}
+}
+
+func TestFreedesktopNotifierNotifyMessage_HandsActionsToListener(t *testing.T) {
+	bus := newFakeBus(t, "actions", "body")
+	n := newTestFreedesktopNotifier(bus, nil)
+	var got []listenRequest
+	n.listen = func(req listenRequest) error {
+		got = append(got, req)
+		return nil
+	}
+
+	msg := Message{Title: "Codex Needs Input", Body: "Run `ls`?", Pending: "/tmp/approvals/0123456789abcdef.json",
+		Actions: []Action{{Label: "Yes", URI: "cc-notify://respond?id=0123456789abcdef"}}}
+	if err := n.NotifyMessage(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Title != msg.Title || got[0].Pending != msg.Pending || len(got[0].Actions) != 1 {
+		t.Fatalf("unexpected listener requests: %+v", got)
+	}
+	if bus.call("Notify") != nil {
+		t.Fatal("expected the listener, not the caller, to show the prompt")
+	}
+}
+
+func TestRunActionListener_StopsWhenApprovalIsGone(t *testing.T) {
+	bus := newFakeBus(t, "actions", "body")
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", bus.addr)
+	pending := filepath.Join(t.TempDir(), "0123456789abcdef.json")
+	if err := os.WriteFile(pending, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("write pending: %v", err)
+	}
+	input, _ := json.Marshal(listenRequest{Title: "t", Body: "b", Pending: pending,
+		Actions: []Action{{Label: "Yes", URI: "cc-notify://respond?id=0123456789abcdef"}}})
+
+	var stdout bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- RunActionListener(bytes.NewReader(input), &stdout, func(string) error {
+			t.Error("no action was clicked")
+			return nil
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := os.Remove(pending); err != nil {
+		t.Fatalf("remove pending: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the listener to stop once the approval is gone")
+	}
+	if stdout.String() != listenReady+"\n" {
+		t.Fatalf("unexpected listener output: %q", stdout.String())
+	}
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	if len(bus.closed) != 1 || bus.closed[0] != 7 {
+		t.Fatalf("expected the prompt to be closed, got %v", bus.closed)
+	}
+}
+
+func TestFreedesktopNotifierNotify_WrapsDialError(t *testing.T) {
+	n := &freedesktopNotifier{
+		dial: func() (*dbusConn, error) { return dialDBus("unix:path=" + filepath.Join(t.TempDir(), "missing")) },
+	}
+	err := n.Notify("title", "body")
+	if err == nil || !strings.Contains(err.Error(), "send desktop notification") {
+		t.Fatalf("expected wrapped dial error, got %v", err)
+	}
+}
+
+func TestDBusMessage_RoundTrip(t *testing.T) {
+	msg := &dbusMessage{
+		Type:        dbusMethodCall,
+		Serial:      3,
+		Path:        notificationsPath,
+		Interface:   notificationsInterface,
+		Member:      "Notify",
+		Destination: notificationsBusName,
+		Signature:   "susssasa{sv}i",
+		Body: []interface{}{
+			"cc-notify", uint32(0), "", "标题", "body", []string{"k", "v"},
+			map[string]dbusVariant{"urgency": {Sig: "y", Value: byte(2)}, "x": {Sig: "s", Value: "y"}},
+			int32(-1),
+		},
+	}
+	data, err := encodeDBusMessage(msg)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if data[0] != 'l' {
+		t.Fatalf("expected little-endian message, got %q", data[0])
+	}
+	got, err := readDBusMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Member != "Notify" || got.Signature != msg.Signature || got.Serial != 3 {
+		t.Fatalf("unexpected header: %+v", got)
+	}
+	if got.Body[3] != "标题" {
+		t.Fatalf("unexpected summary: %v", got.Body[3])
+	}
+	hints, _ := got.Body[6].([]interface{})
+	if len(hints) != 2 {
+		t.Fatalf("unexpected hints: %v", got.Body[6])
+	}
+	entry := hints[0].([]interface{})
+	if entry[0] != "urgency" || entry[1].(dbusVariant).Value != byte(2) {
+		t.Fatalf("unexpected first hint: %v", entry)
+	}
+	if got.Body[7] != int32(-1) {
+		t.Fatalf("unexpected expire timeout: %v", got.Body[7])
+	}
+}
+
+func TestParseDBusAddress(t *testing.T) {
+	cases := []struct {
+		in     string
+		target string
+		ok     bool
+	}{
+		{in: "unix:path=/run/user/1000/bus", target: "/run/user/1000/bus", ok: true},
+		{in: "unix:abstract=/tmp/dbus-abc,guid=123", target: "@/tmp/dbus-abc", ok: true},
+		{in: "unix:path=/tmp/with%20space", target: "/tmp/with space", ok: true},
+		{in: "tcp:host=localhost,port=1", ok: false},
+		{in: "garbage", ok: false},
+	}
+	for _, tc := range cases {
+		_, target, err := parseDBusAddress(tc.in)
+		if (err == nil) != tc.ok {
+			t.Fatalf("parseDBusAddress(%q) error = %v, want ok=%v", tc.in, err, tc.ok)
+		}
+		if tc.ok && target != tc.target {
+			t.Fatalf("parseDBusAddress(%q) = %q, want %q", tc.in, target, tc.target)
+		}
+	}
+}
+
+func TestSessionBusAddress_PrefersEnvironment(t *testing.T) {
+	got, err := sessionBusAddress(func(key string) string {
+		if key == "DBUS_SESSION_BUS_ADDRESS" {
+			return "unix:path=/tmp/bus"
+		}
+		return ""
+	})
+	if err != nil || got != "unix:path=/tmp/bus" {
+		t.Fatalf("unexpected address %q (err=%v)", got, err)
+	}
+	if _, err := sessionBusAddress(func(string) string { return "" }); err == nil {
+		t.Fatalf("expected error without any bus address")
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows && !linux
+
+package notifier
+
+import "os"
+
+type noopNotifier struct{}
+
+// New returns a notifier for platforms without a desktop backend. Only the
+// terminal mode delivers anything there.
+func New() Service {
+	return NewWithConfig(Config{
+		Mode:          os.Getenv("CC_NOTIFY_MODE"),
+		TerminalStyle: os.Getenv("CC_NOTIFY_TERMINAL_STYLE"),
+	})
+}
+
+// NewWithConfig returns the terminal notifier for mode "terminal" and a no-op
+// notifier otherwise.
+func NewWithConfig(cfg Config) Service {
+	if IsTerminalMode(cfg.Mode) {
+		return newTerminalNotifier(cfg)
+	}
+	return noopNotifier{}
+}
+
+func (noopNotifier) Notify(_, _ string) error {
+	return nil
+}
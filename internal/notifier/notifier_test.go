@@ -5,29 +5,44 @@ import (
 	"strings"
 	"testing"
 	"unicode/utf16"
+
+	"cc-notify/internal/i18n"
 )
 
-func TestBuildToastScript_EmbedsBase64Payload(t *testing.T) {
-	title := `$env:PATH $(Get-Process)`
-	body := "line1 & line2"
+func TestBuildToastXMLScript_EmbedsBase64Payload(t *testing.T) {
+	toastXML := `<toast><visual><binding template="ToastGeneric"><text>$env:PATH $(Get-Process)</text></binding></visual></toast>`
 	appID := "Windows PowerShell"
-	script := buildToastScript(title, body, appID)
+	tag := SessionTag("C:\\Users\\dev\\.claude\\projects\\x\\session.jsonl")
+	script := buildToastXMLScript(toastXML, appID, tag, ProjectGroup(`C:\src\cc-notify`))
 
-	titleB64 := base64.StdEncoding.EncodeToString([]byte(title))
-	bodyB64 := base64.StdEncoding.EncodeToString([]byte(body))
+	xmlB64 := base64.StdEncoding.EncodeToString([]byte(toastXML))
 	appIDB64 := base64.StdEncoding.EncodeToString([]byte(appID))
+	tagB64 := base64.StdEncoding.EncodeToString([]byte(tag))
 
-	if !strings.Contains(script, "FromBase64String('"+titleB64+"')") {
-		t.Fatalf("title base64 payload missing in script: %q", script)
-	}
-	if !strings.Contains(script, "FromBase64String('"+bodyB64+"')") {
-		t.Fatalf("body base64 payload missing in script: %q", script)
+	if !strings.Contains(script, "FromBase64String('"+xmlB64+"')") {
+		t.Fatalf("toast xml base64 payload missing in script: %q", script)
 	}
 	if !strings.Contains(script, "FromBase64String('"+appIDB64+"')") {
 		t.Fatalf("appID base64 payload missing in script: %q", script)
 	}
-	if strings.Contains(script, title) {
-		t.Fatalf("raw title should not be embedded directly: %q", script)
+	if !strings.Contains(script, "FromBase64String('"+tagB64+"')") || !strings.Contains(script, "$toast.Tag = $tag") {
+		t.Fatalf("tag payload missing in script: %q", script)
+	}
+	if strings.Contains(script, "Get-Process") {
+		t.Fatalf("raw payload should not be embedded directly: %q", script)
+	}
+}
+
+func TestSessionTagAndProjectGroup(t *testing.T) {
+	tag := SessionTag("0199a1b2-thread")
+	if len(tag) != 16 || tag != SessionTag(" 0199a1b2-thread ") || tag == SessionTag("other-thread") {
+		t.Fatalf("unexpected session tag %q", tag)
+	}
+	if SessionTag("") != "" || ProjectGroup(" ") != "" {
+		t.Fatalf("expected empty keys to give empty tags")
+	}
+	if ProjectGroup(`C:\Src\cc-notify\`) != ProjectGroup("c:/src/cc-notify") {
+		t.Fatalf("expected project group to ignore case and separators")
 	}
 }
 
@@ -69,33 +84,37 @@ func TestBuildPopupScript_EmbedsBase64Payload(t *testing.T) {
 	}
 }
 
-func TestBuildToastScriptWithActions_EmbedsActionPayload(t *testing.T) {
-	uri := "cc-notify://respond?id=1&decision=proceed"
-	uriB64 := base64.StdEncoding.EncodeToString([]byte(uri))
-	script := buildToastScriptWithActions("title", "body", "app", []Action{
-		{Label: "Yes, proceed", URI: uri},
-		{Label: "No", URI: "cc-notify://respond?id=1&decision=reject"},
-	})
-
-	if !strings.Contains(script, "activationType") {
-		t.Fatalf("expected toast actions in script: %q", script)
-	}
-	if !strings.Contains(script, uriB64) {
-		t.Fatalf("expected encoded action uri payload in script: %q", script)
-	}
-}
-
 func TestBuildPopupScriptWithActions_UsesYesNoCancelFlow(t *testing.T) {
 	script := buildPopupScriptWithActions("title", "body", []Action{
 		{Label: "Yes, proceed", URI: "cc-notify://respond?id=1&decision=proceed"},
 		{Label: "Yes, don't ask again", URI: "cc-notify://respond?id=1&decision=proceed-always"},
 		{Label: "No", URI: "cc-notify://respond?id=1&decision=reject"},
-	})
+	}, i18n.SimplifiedChinese)
 
-	if !strings.Contains(script, "Yes ->") || !strings.Contains(script, "Cancel ->") {
-		t.Fatalf("expected mapped button legend in popup script: %q", script)
+	legend := "是 -> Yes, proceed\r\n否 -> Yes, don't ask again\r\n取消 -> No"
+	if !strings.Contains(script, "Decode '"+base64.StdEncoding.EncodeToString([]byte(legend))+"'") {
+		t.Fatalf("expected translated button legend in popup script: %q", script)
 	}
 	if !strings.Contains(script, "Start-Process") {
 		t.Fatalf("expected protocol launch in popup script: %q", script)
 	}
 }
+
+func TestBuildPopupScriptWithActions_OffersYesNoForTwoActions(t *testing.T) {
+	script := buildPopupScriptWithActions("title", "body", []Action{
+		{Label: "Allow", URI: "cc-notify://respond?id=1&decision=proceed"},
+		{Label: "Deny", URI: "cc-notify://respond?id=1&decision=reject", Input: "Tell Claude what to do instead"},
+	}, "")
+
+	legend := "Yes -> Allow\r\nNo -> Deny"
+	if !strings.Contains(script, "Decode '"+base64.StdEncoding.EncodeToString([]byte(legend))+"'") {
+		t.Fatalf("expected Yes/No legend in popup script: %q", script)
+	}
+	if !strings.Contains(script, "$actionUris.Count -eq 2") || !strings.Contains(script, "$buttons = 0x44") {
+		t.Fatalf("expected a Yes/No message box for two actions: %q", script)
+	}
+	input := base64.StdEncoding.EncodeToString([]byte("Tell Claude what to do instead"))
+	if !strings.Contains(script, "$actionInputs = @('','"+input+"')") || !strings.Contains(script, "InputBox") {
+		t.Fatalf("expected Deny to ask for feedback: %q", script)
+	}
+}
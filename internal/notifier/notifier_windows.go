@@ -2,157 +2,21 @@
 
 package notifier
 
-import (
-	"fmt"
-	"os"
-	"os/exec"
-	"strings"
-)
-
-type commandRunner interface {
-	Run(name string, args ...string) error
-}
-
-type execRunner struct{}
-
-func (execRunner) Run(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		text := strings.TrimSpace(string(output))
-		if text == "" {
-			return err
-		}
-		return fmt.Errorf("%w: %s", err, text)
-	}
-	return nil
-}
-
-type windowsNotifier struct {
-	shell  string
-	runner commandRunner
-	mode   notifyMode
-	appID  string
-}
-
-type notifyMode int
-
-const (
-	modeAuto notifyMode = iota
-	modeToast
-	modePopup
-)
-
-const (
-	defaultToastAppID = "cc-notify.desktop"
-	legacyToastAppID  = "Windows PowerShell"
-)
+import "os"
 
 // New creates a Windows notifier backed by PowerShell.
 func New() Service {
 	return NewWithConfig(Config{
-		Mode:       os.Getenv("CC_NOTIFY_MODE"),
-		ToastAppID: os.Getenv("CC_NOTIFY_TOAST_APP_ID"),
+		Mode:          os.Getenv("CC_NOTIFY_MODE"),
+		ToastAppID:    os.Getenv("CC_NOTIFY_TOAST_APP_ID"),
+		TerminalStyle: os.Getenv("CC_NOTIFY_TERMINAL_STYLE"),
 	})
 }
 
 // NewWithConfig creates a Windows notifier with explicit config values.
 func NewWithConfig(cfg Config) Service {
-	appID := strings.TrimSpace(cfg.ToastAppID)
-	if appID == "" || appID == legacyToastAppID || appID == "codex-notified.desktop" {
-		appID = defaultToastAppID
-	}
-	return &windowsNotifier{
-		shell:  "powershell.exe",
-		runner: execRunner{},
-		mode:   parseNotifyMode(cfg.Mode),
-		appID:  appID,
-	}
-}
-
-func (n *windowsNotifier) Notify(title, body string) error {
-	return n.notify(title, body, nil)
-}
-
-func (n *windowsNotifier) NotifyWithActions(title, body string, actions []Action) error {
-	return n.notify(title, body, actions)
-}
-
-func (n *windowsNotifier) notify(title, body string, actions []Action) error {
-	switch n.mode {
-	case modeToast:
-		if err := n.sendToastWithLegacyFallback(title, body, actions); err != nil {
-			if isToastAccessDenied(err) {
-				if fallbackErr := n.runPowerShell(buildPopupScriptWithActions(title, body, actions)); fallbackErr != nil {
-					return fmt.Errorf("send windows notification (toast): %v; popup fallback failed: %w", err, fallbackErr)
-				}
-				return nil
-			}
-			return fmt.Errorf("send windows notification (toast): %w", err)
-		}
-		return nil
-	case modePopup:
-		if err := n.runPowerShell(buildPopupScriptWithActions(title, body, actions)); err != nil {
-			return fmt.Errorf("send windows notification (popup): %w", err)
-		}
-		return nil
-	default:
-		if err := n.sendToastWithLegacyFallback(title, body, actions); err != nil {
-			fallbackErr := n.runPowerShell(buildPopupScriptWithActions(title, body, actions))
-			if fallbackErr != nil {
-				return fmt.Errorf("send windows notification: toast failed: %v; popup fallback failed: %w", err, fallbackErr)
-			}
-		}
-		return nil
-	}
-}
-
-func isToastAccessDenied(err error) bool {
-	if err == nil {
-		return false
-	}
-	msg := strings.ToLower(err.Error())
-	return strings.Contains(msg, "e_accessdenied") ||
-		strings.Contains(msg, "0x80070005") ||
-		strings.Contains(msg, "access is denied")
-}
-
-func (n *windowsNotifier) sendToastWithLegacyFallback(title, body string, actions []Action) error {
-	primaryErr := n.runPowerShell(buildToastScriptWithActions(title, body, n.appID, actions))
-	if primaryErr == nil {
-		return nil
-	}
-
-	if strings.EqualFold(strings.TrimSpace(n.appID), legacyToastAppID) {
-		return primaryErr
-	}
-
-	legacyErr := n.runPowerShell(buildToastScriptWithActions(title, body, legacyToastAppID, actions))
-	if legacyErr == nil {
-		return nil
-	}
-
-	return fmt.Errorf("primary app id %q failed: %v; legacy app id fallback failed: %w", n.appID, primaryErr, legacyErr)
-}
-
-func parseNotifyMode(raw string) notifyMode {
-	switch strings.ToLower(strings.TrimSpace(raw)) {
-	case "toast":
-		return modeToast
-	case "popup":
-		return modePopup
-	default:
-		return modeAuto
-	}
-}
-
-func (n *windowsNotifier) runPowerShell(script string) error {
-	encoded := encodePowerShellCommand(script)
-	args := []string{
-		"-NoProfile",
-		"-NonInteractive",
-		"-ExecutionPolicy", "Bypass",
-		"-EncodedCommand", encoded,
+	if IsTerminalMode(cfg.Mode) {
+		return newTerminalNotifier(cfg)
 	}
-	return n.runner.Run(n.shell, args...)
+	return newPowerShellNotifier("powershell.exe", cfg)
 }
@@ -0,0 +1,209 @@
+package notifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultNtfyServer   = "https://ntfy.sh"
+	ntfyPausedPriority  = 4
+	ntfyMaxPriority     = 5
+	ntfyMaxActions      = 3
+	respondCallbackPath = "/respond"
+
+	// CallbackTokenParam is the callback URL query parameter that carries
+	// the ApprovalToken of the approval answered.
+	CallbackTokenParam = "token"
+)
+
+// ApprovalToken derives the token that authorizes answering the approval
+// id from the responder secret. Callback URLs published to a topic carry it
+// instead of the secret, so whoever reads the topic can answer only the
+// approvals published there.
+func ApprovalToken(secret, id string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(id))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// NtfyConfig configures the ntfy publisher.
+type NtfyConfig struct {
+	Server   string
+	Topic    string
+	Token    string
+	Priority int
+	Tags     []string
+	Timeout  time.Duration
+
+	// CallbackURL is the base URL of a reachable `cc-notify serve` endpoint.
+	// When set, approval actions become ntfy http actions that POST to
+	// CallbackURL + "/respond". CallbackToken is the responder secret; the
+	// URLs carry the ApprovalToken derived from it, never the secret.
+	CallbackURL   string
+	CallbackToken string
+}
+
+// ntfyDocument is the JSON publish request understood by ntfy servers.
+type ntfyDocument struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title,omitempty"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority,omitempty"`
+	Tags     []string     `json:"tags,omitempty"`
+	Actions  []ntfyAction `json:"actions,omitempty"`
+}
+
+type ntfyAction struct {
+	Action  string            `json:"action"`
+	Label   string            `json:"label"`
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Clear   bool              `json:"clear,omitempty"`
+}
+
+type ntfyNotifier struct {
+	server        string
+	topic         string
+	token         string
+	priority      int
+	tags          []string
+	callbackURL   string
+	callbackToken string
+	client        *http.Client
+}
+
+// NewNtfy creates a channel that publishes to an ntfy topic.
+func NewNtfy(cfg NtfyConfig) (Service, error) {
+	server := strings.TrimSpace(cfg.Server)
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	server, err := validateHTTPURL(server)
+	if err != nil {
+		return nil, fmt.Errorf("ntfy server: %w", err)
+	}
+	topic := strings.TrimSpace(cfg.Topic)
+	if topic == "" || strings.ContainsAny(topic, "/?# ") {
+		return nil, fmt.Errorf("ntfy: invalid topic %q", cfg.Topic)
+	}
+	if cfg.Priority < 0 || cfg.Priority > ntfyMaxPriority {
+		return nil, fmt.Errorf("ntfy: priority must be between 1 and 5, got %d", cfg.Priority)
+	}
+	callbackURL := strings.TrimSpace(cfg.CallbackURL)
+	if callbackURL != "" {
+		if callbackURL, err = validateHTTPURL(callbackURL); err != nil {
+			return nil, fmt.Errorf("ntfy callback: %w", err)
+		}
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &ntfyNotifier{
+		server:        strings.TrimRight(server, "/"),
+		topic:         topic,
+		token:         strings.TrimSpace(cfg.Token),
+		priority:      cfg.Priority,
+		tags:          cfg.Tags,
+		callbackURL:   strings.TrimRight(callbackURL, "/"),
+		callbackToken: strings.TrimSpace(cfg.CallbackToken),
+		client:        &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (n *ntfyNotifier) Notify(title, body string) error {
+	return n.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (n *ntfyNotifier) NotifyWithActions(title, body string, actions []Action) error {
+	return n.NotifyMessage(Message{Title: title, Body: body, Actions: actions})
+}
+
+// Dismiss does nothing; ntfy messages cannot be withdrawn by tag.
+func (n *ntfyNotifier) Dismiss(_, _ string) error {
+	return nil
+}
+
+func (n *ntfyNotifier) NotifyMessage(msg Message) error {
+	doc := n.document(msg)
+	var headers map[string]string
+	if n.token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + n.token}
+	}
+	if err := postJSON(n.client, n.server+"/", headers, doc); err != nil {
+		return fmt.Errorf("send ntfy notification: %w", err)
+	}
+	return nil
+}
+
+func (n *ntfyNotifier) document(msg Message) ntfyDocument {
+	doc := ntfyDocument{
+		Topic:    n.topic,
+		Title:    msg.Title,
+		Message:  msg.Body,
+		Priority: n.priority,
+		Tags:     append([]string{}, n.tags...),
+	}
+	if msg.EventType == "agent-turn-paused" {
+		if doc.Priority < ntfyPausedPriority {
+			doc.Priority = ntfyPausedPriority
+		}
+		doc.Tags = append(doc.Tags, "warning")
+	} else {
+		doc.Tags = append(doc.Tags, "white_check_mark")
+	}
+	doc.Actions = n.httpActions(msg.Actions)
+	return doc
+}
+
+// httpActions maps cc-notify://respond actions onto ntfy http actions that
+// call back into `cc-notify serve`.
+func (n *ntfyNotifier) httpActions(actions []Action) []ntfyAction {
+	if n.callbackURL == "" {
+		return nil
+	}
+	var out []ntfyAction
+	for _, action := range actions {
+		query, ok := respondQuery(action.URI)
+		if !ok || strings.TrimSpace(action.Label) == "" {
+			continue
+		}
+		if n.callbackToken != "" {
+			query.Set(CallbackTokenParam, ApprovalToken(n.callbackToken, query.Get("id")))
+		}
+		out = append(out, ntfyAction{
+			Action: "http",
+			Label:  action.Label,
+			URL:    n.callbackURL + respondCallbackPath + "?" + query.Encode(),
+			Method: http.MethodPost,
+			Clear:  true,
+		})
+		if len(out) == ntfyMaxActions {
+			break
+		}
+	}
+	return out
+}
+
+// respondQuery returns the query of a cc-notify://respond URI.
+func respondQuery(raw string) (url.Values, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !strings.EqualFold(u.Scheme, "cc-notify") {
+		return nil, false
+	}
+	if strings.Trim(strings.ToLower(u.Host+u.Path), "/") != "respond" {
+		return nil, false
+	}
+	if u.RawQuery == "" {
+		return nil, false
+	}
+	return u.Query(), true
+}
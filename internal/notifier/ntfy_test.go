@@ -0,0 +1,130 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNtfyNotifier_PublishesCompletion(t *testing.T) {
+	var doc ntfyDocument
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("Authorization")
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &doc)
+	}))
+	defer server.Close()
+
+	svc, err := NewNtfy(NtfyConfig{Server: server.URL, Topic: "agents", Token: "tk_abc", Priority: 2, Tags: []string{"robot"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := Send(svc, Message{Title: "Codex Task Complete", Body: "done", EventType: "agent-turn-complete"}); err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	if path != "/" {
+		t.Fatalf("expected JSON publish to server root, got %q", path)
+	}
+	if auth != "Bearer tk_abc" {
+		t.Fatalf("unexpected auth header: %q", auth)
+	}
+	if doc.Topic != "agents" || doc.Title != "Codex Task Complete" || doc.Message != "done" {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+	if doc.Priority != 2 {
+		t.Fatalf("expected configured priority, got %d", doc.Priority)
+	}
+	if len(doc.Tags) != 2 || doc.Tags[0] != "robot" || doc.Tags[1] != "white_check_mark" {
+		t.Fatalf("unexpected tags: %v", doc.Tags)
+	}
+	if len(doc.Actions) != 0 {
+		t.Fatalf("completion should not carry actions: %+v", doc.Actions)
+	}
+}
+
+func TestNtfyNotifier_MapsApprovalActionsToHTTPCallbacks(t *testing.T) {
+	var doc ntfyDocument
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &doc)
+	}))
+	defer server.Close()
+
+	svc, err := NewNtfy(NtfyConfig{
+		Server:        server.URL,
+		Topic:         "agents",
+		CallbackURL:   "https://box.example.com:8787/",
+		CallbackToken: "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = Send(svc, Message{
+		Title:     "Codex Needs Input",
+		Body:      "Run `ls`?",
+		EventType: "agent-turn-paused",
+		Actions: []Action{
+			{Label: "Yes, proceed", URI: "cc-notify://respond?decision=proceed&id=0123456789abcdef"},
+			{Label: "Yes, always", URI: "cc-notify://respond?decision=proceed-always&id=0123456789abcdef"},
+			{Label: "No", URI: "cc-notify://respond?decision=reject&id=0123456789abcdef"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	if doc.Priority != ntfyPausedPriority {
+		t.Fatalf("expected paused events to be high priority, got %d", doc.Priority)
+	}
+	if len(doc.Actions) != 3 {
+		t.Fatalf("expected 3 actions, got %+v", doc.Actions)
+	}
+	first := doc.Actions[0]
+	if first.Action != "http" || first.Method != http.MethodPost || !first.Clear {
+		t.Fatalf("unexpected action shape: %+v", first)
+	}
+	want := "https://box.example.com:8787/respond?decision=proceed&id=0123456789abcdef&token=" + ApprovalToken("s3cret", "0123456789abcdef")
+	if first.URL != want {
+		t.Fatalf("unexpected callback url:\n got %q\nwant %q", first.URL, want)
+	}
+	// Everyone subscribed to the topic sees the actions, so the responder
+	// secret itself must not be among them.
+	if raw, _ := json.Marshal(doc); len(first.Headers) != 0 || strings.Contains(string(raw), "s3cret") {
+		t.Fatalf("expected the responder secret to stay private, got %s", raw)
+	}
+	if doc.Actions[2].Label != "No" {
+		t.Fatalf("unexpected action order: %+v", doc.Actions)
+	}
+}
+
+func TestNtfyNotifier_OmitsActionsWithoutCallback(t *testing.T) {
+	n := &ntfyNotifier{topic: "agents"}
+	doc := n.document(Message{EventType: "agent-turn-paused", Actions: []Action{{Label: "Yes", URI: "cc-notify://respond?id=1&decision=proceed"}}})
+	if len(doc.Actions) != 0 {
+		t.Fatalf("expected no actions without callback url, got %+v", doc.Actions)
+	}
+}
+
+func TestNewNtfy_Validation(t *testing.T) {
+	cases := []NtfyConfig{
+		{Topic: ""},
+		{Topic: "a/b"},
+		{Topic: "ok", Priority: 9},
+		{Topic: "ok", Server: "ftp://x"},
+		{Topic: "ok", CallbackURL: "nope"},
+	}
+	for _, cfg := range cases {
+		if _, err := NewNtfy(cfg); err == nil {
+			t.Fatalf("expected error for %+v", cfg)
+		}
+	}
+	if _, err := NewNtfy(NtfyConfig{Topic: "ok"}); err != nil {
+		t.Fatalf("default server should be valid: %v", err)
+	}
+}
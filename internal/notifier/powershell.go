@@ -0,0 +1,224 @@
+//go:build windows || linux
+
+package notifier
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type commandRunner interface {
+	Run(name string, args ...string) error
+	// Start launches the command without waiting for it to exit.
+	Start(name string, args ...string) error
+}
+
+type execRunner struct{}
+
+func (execRunner) Run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		text := strings.TrimSpace(string(output))
+		if text == "" {
+			return err
+		}
+		return fmt.Errorf("%w: %s", err, text)
+	}
+	return nil
+}
+
+func (execRunner) Start(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+type windowsNotifier struct {
+	shell  string
+	runner commandRunner
+	mode   notifyMode
+	appID  string
+	toast  ToastOptions
+	// hostPath converts a local path for the PowerShell on the Windows
+	// side; nil keeps it. It returns "" when there is no such path.
+	hostPath func(string) string
+}
+
+type notifyMode int
+
+const (
+	modeAuto notifyMode = iota
+	modeToast
+	modePopup
+)
+
+const (
+	defaultToastAppID = "cc-notify.desktop"
+	legacyToastAppID  = "Windows PowerShell"
+)
+
+// toastInputWait is the longest a toast with a text box waits for its
+// click, matching how long approvals stay pending. The listener quits as
+// soon as the approval is gone.
+const toastInputWait = 15 * time.Minute
+
+// newPowerShellNotifier creates the toast/popup backend that runs scripts
+// through shell, a Windows PowerShell executable.
+func newPowerShellNotifier(shell string, cfg Config) *windowsNotifier {
+	return &windowsNotifier{
+		shell:  shell,
+		runner: execRunner{},
+		mode:   parseNotifyMode(cfg.Mode),
+		appID:  toastAppID(cfg.ToastAppID),
+		toast:  cfg.Toast,
+	}
+}
+
+// toastAppID returns the AppUserModelID to show toasts under, migrating
+// the legacy defaults.
+func toastAppID(raw string) string {
+	appID := strings.TrimSpace(raw)
+	if appID == "" || appID == legacyToastAppID || appID == "codex-notified.desktop" {
+		return defaultToastAppID
+	}
+	return appID
+}
+
+func (n *windowsNotifier) Notify(title, body string) error {
+	return n.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (n *windowsNotifier) NotifyWithActions(title, body string, actions []Action) error {
+	return n.NotifyMessage(Message{Title: title, Body: body, Actions: actions})
+}
+
+func (n *windowsNotifier) NotifyMessage(msg Message) error {
+	title, body, actions := msg.Title, msg.Body, msg.Actions
+	popup := buildPopupScriptWithActions(title, body, actions, msg.Language)
+	toastXML, err := ToastForMessage(msg, n.toast).XML()
+	if err != nil {
+		return fmt.Errorf("send windows notification: %w", err)
+	}
+
+	if hasInput(actions) && n.mode != modePopup {
+		return n.startToastListener(toastXML, msg.Tag, msg.Group, n.windowsPath(msg.Pending), popup)
+	}
+
+	switch n.mode {
+	case modeToast:
+		if err := n.sendToastWithLegacyFallback(toastXML, msg.Tag, msg.Group); err != nil {
+			if isToastAccessDenied(err) {
+				if fallbackErr := n.runPowerShell(popup); fallbackErr != nil {
+					return fmt.Errorf("send windows notification (toast): %v; popup fallback failed: %w", err, fallbackErr)
+				}
+				return nil
+			}
+			return fmt.Errorf("send windows notification (toast): %w", err)
+		}
+		return nil
+	case modePopup:
+		if err := n.runPowerShell(popup); err != nil {
+			return fmt.Errorf("send windows notification (popup): %w", err)
+		}
+		return nil
+	default:
+		if err := n.sendToastWithLegacyFallback(toastXML, msg.Tag, msg.Group); err != nil {
+			fallbackErr := n.runPowerShell(popup)
+			if fallbackErr != nil {
+				return fmt.Errorf("send windows notification: toast failed: %v; popup fallback failed: %w", err, fallbackErr)
+			}
+		}
+		return nil
+	}
+}
+
+// Dismiss removes a toast from Action Center. Popups cannot be removed once
+// shown, so popup mode does nothing.
+func (n *windowsNotifier) Dismiss(tag, group string) error {
+	if n.mode == modePopup || strings.TrimSpace(tag) == "" {
+		return nil
+	}
+	if err := n.runPowerShell(buildToastRemoveScript(n.appID, tag, group)); err != nil {
+		return fmt.Errorf("dismiss windows notification: %w", err)
+	}
+	return nil
+}
+
+func isToastAccessDenied(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "e_accessdenied") ||
+		strings.Contains(msg, "0x80070005") ||
+		strings.Contains(msg, "access is denied")
+}
+
+func (n *windowsNotifier) sendToastWithLegacyFallback(toastXML, tag, group string) error {
+	primaryErr := n.runPowerShell(buildToastXMLScript(toastXML, n.appID, tag, group))
+	if primaryErr == nil {
+		return nil
+	}
+
+	if strings.EqualFold(strings.TrimSpace(n.appID), legacyToastAppID) {
+		return primaryErr
+	}
+
+	legacyErr := n.runPowerShell(buildToastXMLScript(toastXML, legacyToastAppID, tag, group))
+	if legacyErr == nil {
+		return nil
+	}
+
+	return fmt.Errorf("primary app id %q failed: %v; legacy app id fallback failed: %w", n.appID, primaryErr, legacyErr)
+}
+
+// startToastListener shows a toast with a text box from a background
+// PowerShell that waits for the click, so the caller does not block. Errors
+// showing the toast surface there, so the popup is always its fallback.
+func (n *windowsNotifier) startToastListener(toastXML, tag, group, pending, popup string) error {
+	appIDs := []string{n.appID}
+	if !strings.EqualFold(strings.TrimSpace(n.appID), legacyToastAppID) {
+		appIDs = append(appIDs, legacyToastAppID)
+	}
+	script := buildToastListenScript(toastXML, appIDs, tag, group, pending, popup, toastInputWait)
+	if err := n.runner.Start(n.shell, powerShellArgs(script)...); err != nil {
+		return fmt.Errorf("send windows notification (toast): %w", err)
+	}
+	return nil
+}
+
+func (n *windowsNotifier) windowsPath(path string) string {
+	if path == "" || n.hostPath == nil {
+		return path
+	}
+	return n.hostPath(path)
+}
+
+func parseNotifyMode(raw string) notifyMode {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "toast":
+		return modeToast
+	case "popup":
+		return modePopup
+	default:
+		return modeAuto
+	}
+}
+
+func (n *windowsNotifier) runPowerShell(script string) error {
+	return n.runner.Run(n.shell, powerShellArgs(script)...)
+}
+
+func powerShellArgs(script string) []string {
+	return []string{
+		"-NoProfile",
+		"-NonInteractive",
+		"-ExecutionPolicy", "Bypass",
+		"-EncodedCommand", encodePowerShellCommand(script),
+	}
+}
@@ -0,0 +1,240 @@
+//go:build windows || linux
+
+package notifier
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+type captureRunner struct {
+	name string
+	args []string
+	all  [][]string
+	err  error
+	errs []error
+	call int
+	// started records the commands launched without waiting.
+	started [][]string
+}
+
+func (r *captureRunner) Run(name string, args ...string) error {
+	r.name = name
+	r.args = append([]string{}, args...)
+	r.all = append(r.all, append([]string{}, args...))
+	if len(r.errs) > 0 {
+		var err error
+		if r.call < len(r.errs) {
+			err = r.errs[r.call]
+		} else {
+			err = r.errs[len(r.errs)-1]
+		}
+		r.call++
+		return err
+	}
+	r.call++
+	return r.err
+}
+
+func (r *captureRunner) Start(name string, args ...string) error {
+	r.started = append(r.started, append([]string{}, args...))
+	return r.Run(name, args...)
+}
+
+func decodeEncodedCommand(args []string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] != "-EncodedCommand" {
+			continue
+		}
+		raw := args[i+1]
+		decoded, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return ""
+		}
+		if len(decoded)%2 != 0 {
+			return ""
+		}
+		u16 := make([]uint16, 0, len(decoded)/2)
+		for j := 0; j < len(decoded); j += 2 {
+			u16 = append(u16, uint16(decoded[j])|uint16(decoded[j+1])<<8)
+		}
+		return string(utf16.Decode(u16))
+	}
+	return ""
+}
+
+func TestWindowsNotifierNotify_BuildsPowerShellCommand(t *testing.T) {
+	runner := &captureRunner{}
+	n := &windowsNotifier{
+		shell:  "powershell.exe",
+		runner: runner,
+		mode:   modeAuto,
+		appID:  "Windows PowerShell",
+	}
+
+	if err := n.Notify("title", "body"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runner.name != "powershell.exe" {
+		t.Fatalf("unexpected command: %q", runner.name)
+	}
+	joined := strings.Join(runner.args, " ")
+	if !strings.Contains(joined, "-EncodedCommand") {
+		t.Fatalf("expected -EncodedCommand arg, got %q", joined)
+	}
+}
+
+func TestWindowsNotifierNotify_WrapsRunnerError(t *testing.T) {
+	runner := &captureRunner{errs: []error{errors.New("toast boom"), errors.New("legacy toast boom"), errors.New("popup boom")}}
+	n := &windowsNotifier{
+		shell:  "powershell.exe",
+		runner: runner,
+		mode:   modeAuto,
+		appID:  "cc-notify.desktop",
+	}
+
+	err := n.Notify("title", "body")
+	if err == nil {
+		t.Fatalf("expected wrapped error")
+	}
+	if !strings.Contains(err.Error(), "popup fallback failed") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+	if runner.call != 3 {
+		t.Fatalf("expected toast + legacy toast + popup attempts, got %d", runner.call)
+	}
+}
+
+func TestWindowsNotifierNotify_FallbackToLegacyToast(t *testing.T) {
+	runner := &captureRunner{errs: []error{errors.New("toast denied"), nil}}
+	n := &windowsNotifier{
+		shell:  "powershell.exe",
+		runner: runner,
+		mode:   modeAuto,
+		appID:  "cc-notify.desktop",
+	}
+
+	if err := n.Notify("title", "body"); err != nil {
+		t.Fatalf("expected legacy toast fallback to succeed, got %v", err)
+	}
+	if runner.call != 2 {
+		t.Fatalf("expected 2 calls (toast + legacy toast), got %d", runner.call)
+	}
+	script := decodeEncodedCommand(runner.all[1])
+	if !strings.Contains(script, "V2luZG93cyBQb3dlclNoZWxs") {
+		t.Fatalf("expected second toast attempt to use legacy app id, got script=%q", script)
+	}
+}
+
+func TestWindowsNotifierNotify_ToastModeNoFallback(t *testing.T) {
+	runner := &captureRunner{errs: []error{errors.New("toast denied"), nil}}
+	n := &windowsNotifier{
+		shell:  "powershell.exe",
+		runner: runner,
+		mode:   modeToast,
+		appID:  "cc-notify.desktop",
+	}
+
+	if err := n.Notify("title", "body"); err != nil {
+		t.Fatalf("expected legacy fallback success in toast-only mode, got %v", err)
+	}
+	if runner.call != 2 {
+		t.Fatalf("expected two toast attempts in toast-only mode, got %d", runner.call)
+	}
+}
+
+func TestWindowsNotifierNotify_ToastModeAccessDeniedFallsBackToPopup(t *testing.T) {
+	denied := errors.New("Exception from HRESULT: 0x80070005 (E_ACCESSDENIED)")
+	runner := &captureRunner{errs: []error{denied, denied, nil}}
+	n := &windowsNotifier{
+		shell:  "powershell.exe",
+		runner: runner,
+		mode:   modeToast,
+		appID:  "cc-notify.desktop",
+	}
+
+	if err := n.Notify("title", "body"); err != nil {
+		t.Fatalf("expected popup fallback after toast access denied, got %v", err)
+	}
+	if runner.call != 3 {
+		t.Fatalf("expected toast + legacy toast + popup attempts, got %d", runner.call)
+	}
+}
+
+func TestWindowsNotifierDismiss_RemovesFromHistory(t *testing.T) {
+	runner := &captureRunner{}
+	n := &windowsNotifier{shell: "powershell.exe", runner: runner, mode: modeToast, appID: "cc-notify.desktop"}
+
+	if err := n.Dismiss("0123456789abcdef", "fedcba9876543210"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	script := decodeEncodedCommand(runner.args)
+	if script != buildToastRemoveScript("cc-notify.desktop", "0123456789abcdef", "fedcba9876543210") {
+		t.Fatalf("expected history removal script, got %q", script)
+	}
+	if !strings.Contains(script, "History.Remove($tag, $group, $appId)") {
+		t.Fatalf("expected ToastNotificationManager.History removal, got %q", script)
+	}
+
+	n.mode = modePopup
+	if err := n.Dismiss("0123456789abcdef", ""); err != nil || runner.call != 1 {
+		t.Fatalf("expected popup mode to skip dismissal, got err=%v calls=%d", err, runner.call)
+	}
+}
+
+func TestWindowsNotifierNotify_FeedbackToastRunsInBackground(t *testing.T) {
+	runner := &captureRunner{}
+	n := &windowsNotifier{shell: "powershell.exe", runner: runner, mode: modeAuto, appID: "cc-notify.desktop", hostPath: func(path string) string {
+		return `\\wsl.localhost\Ubuntu` + strings.ReplaceAll(path, "/", `\`)
+	}}
+	actions := []Action{
+		{Label: "Yes, proceed", URI: "cc-notify://respond?decision=proceed&id=0123456789abcdef"},
+		{Label: "Yes, don't ask again for `go test ./...`", URI: "cc-notify://respond?decision=proceed-always&id=0123456789abcdef"},
+		{Label: "No, tell Codex to do differently", URI: "cc-notify://respond?decision=reject&id=0123456789abcdef", Input: "What should Codex do instead?"},
+	}
+	msg := Message{Title: "Codex Needs Input", Body: strings.Repeat("Run `go test ./...`? ", 40), Source: "codex", CWD: `C:\src\cc-notify`, Actions: actions, Pending: "/home/dev/.config/cc-notify/approvals/0123456789abcdef.json"}
+
+	if err := n.NotifyMessage(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(runner.started) != 1 || runner.call != 1 {
+		t.Fatalf("expected one background launch, got started=%d calls=%d", len(runner.started), runner.call)
+	}
+	script := decodeEncodedCommand(runner.started[0])
+	for _, want := range []string{"Register-ObjectEvent", "UserInput['feedback']", "[System.Uri]::EscapeDataString($text)", "AddSeconds(900)", "Test-Path -LiteralPath $pending", "$toastNotifier.Hide($toast)"} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("expected listener script to contain %q, got %q", want, script)
+		}
+	}
+	pending := base64.StdEncoding.EncodeToString([]byte(`\\wsl.localhost\Ubuntu\home\dev\.config\cc-notify\approvals\0123456789abcdef.json`))
+	if !strings.Contains(script, "$pending = Decode '"+pending+"'") {
+		t.Fatalf("expected the listener to watch the approval file by its Windows path, got %q", script)
+	}
+	if size := len(strings.Join(runner.started[0], " ")) + len("powershell.exe "); size >= 32767 {
+		t.Fatalf("listener command line is %d characters, over the Windows limit", size)
+	}
+
+	popup := &captureRunner{}
+	n.runner, n.mode = popup, modePopup
+	if err := n.NotifyMessage(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(popup.started) != 0 || !strings.Contains(decodeEncodedCommand(popup.args), "InputBox") {
+		t.Fatalf("expected popup mode to ask for feedback in an input box")
+	}
+}
+
+func TestParseNotifyMode(t *testing.T) {
+	if got := parseNotifyMode("toast"); got != modeToast {
+		t.Fatalf("expected toast mode, got %v", got)
+	}
+	if got := parseNotifyMode("popup"); got != modePopup {
+		t.Fatalf("expected popup mode, got %v", got)
+	}
+	if got := parseNotifyMode("invalid"); got != modeAuto {
+		t.Fatalf("expected auto mode, got %v", got)
+	}
+}
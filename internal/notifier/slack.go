@@ -0,0 +1,213 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const defaultSlackAPIBase = "https://slack.com/api"
+
+const (
+	// Block Kit limits: header text 150 chars, section text 3000 chars, 50 blocks.
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+	slackMaxBlocks    = 50
+)
+
+// NewSlack creates a Slack incoming-webhook channel that renders Block Kit.
+func NewSlack(cfg ChatWebhookConfig) (Service, error) {
+	return newChatWebhook("slack", cfg, func(msg Message) interface{} { return slackDocument(msg) })
+}
+
+// SlackBotConfig configures a Slack app that posts with chat.postMessage
+// instead of an incoming webhook.
+type SlackBotConfig struct {
+	// APIBase defaults to https://slack.com/api.
+	APIBase string
+	// Token is a bot token, usually starting with xoxb-.
+	Token string
+	// Channel is a channel id or #name the bot is a member of.
+	Channel string
+	Timeout time.Duration
+}
+
+type slackBot struct {
+	endpoint string
+	token    string
+	channel  string
+	client   *http.Client
+}
+
+// NewSlackBot creates a Slack channel that posts Block Kit messages as a bot.
+func NewSlackBot(cfg SlackBotConfig) (Service, error) {
+	base := strings.TrimSpace(cfg.APIBase)
+	if base == "" {
+		base = defaultSlackAPIBase
+	}
+	base, err := validateHTTPURL(base)
+	if err != nil {
+		return nil, fmt.Errorf("slack api base: %w", err)
+	}
+	token := strings.TrimSpace(cfg.Token)
+	if token == "" {
+		return nil, fmt.Errorf("slack: bot token is required")
+	}
+	channel := strings.TrimSpace(cfg.Channel)
+	if channel == "" {
+		return nil, fmt.Errorf("slack: channel is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &slackBot{
+		endpoint: strings.TrimRight(base, "/") + "/chat.postMessage",
+		token:    token,
+		channel:  channel,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (b *slackBot) Notify(title, body string) error {
+	return b.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (b *slackBot) NotifyMessage(msg Message) error {
+	if err := b.post(slackBotPayload{Channel: b.channel, slackPayload: slackDocument(msg)}); err != nil {
+		return fmt.Errorf("send slack notification: %w", err)
+	}
+	return nil
+}
+
+type slackBotPayload struct {
+	Channel string `json:"channel"`
+	slackPayload
+}
+
+// post calls chat.postMessage. The Web API answers 200 even for failures and
+// reports them in the ok and error fields.
+func (b *slackBot) post(payload slackBotPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, b.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "cc-notify")
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return checkHTTPStatus(resp)
+	}
+	var out struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if !out.OK {
+		return fmt.Errorf("chat.postMessage: %s", out.Error)
+	}
+	return nil
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackPayload struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+func slackDocument(msg Message) slackPayload {
+	title := strings.TrimSpace(msg.Title)
+	doc := slackPayload{Text: title}
+	if title != "" {
+		doc.Blocks = append(doc.Blocks, slackBlock{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: truncateRunes(title, slackHeaderLimit)},
+		})
+	}
+
+	if facts := chatFacts(msg); len(facts) > 0 {
+		fields := make([]slackText, 0, len(facts))
+		for _, fact := range facts {
+			fields = append(fields, slackText{Type: "mrkdwn", Text: "*" + fact.Name + "*\n" + slackEscape(fact.Value)})
+		}
+		doc.Blocks = append(doc.Blocks, slackBlock{Type: "section", Fields: fields})
+	}
+
+	for _, seg := range splitCodeSegments(chatMessageText(msg)) {
+		if len(doc.Blocks) == slackMaxBlocks {
+			break
+		}
+		var text string
+		if seg.Code {
+			// Slack ignores the fence language; keep room for the fences.
+			text = "```\n" + slackEscapeLimit(seg.Text, slackSectionLimit-8) + "\n```"
+		} else {
+			text = slackEscapeLimit(seg.Text, slackSectionLimit)
+		}
+		doc.Blocks = append(doc.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}})
+	}
+	return doc
+}
+
+// slackEscape escapes the three characters mrkdwn treats as control syntax.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// slackEscapeLimit escapes text and keeps the result within limit runes.
+// The raw text is cut before escaping, so the cut never splits an entity
+// such as &amp;.
+func slackEscapeLimit(text string, limit int) string {
+	escaped := slackEscape(text)
+	if utf8.RuneCountInString(escaped) <= limit {
+		return escaped
+	}
+	// Leave room for the ellipsis that marks the cut.
+	n, end := 1, 0
+	for i, r := range text {
+		width := 1
+		switch r {
+		case '&':
+			width = len("&amp;")
+		case '<', '>':
+			width = len("&lt;")
+		}
+		if n+width > limit {
+			break
+		}
+		n += width
+		end = i + utf8.RuneLen(r)
+	}
+	return slackEscape(text[:end]) + "…"
+}
@@ -0,0 +1,132 @@
+package notifier
+
+import "strings"
+
+// teamsTextLimit keeps a card well below the ~28 KB Teams message limit.
+const teamsTextLimit = 12000
+
+// NewTeams creates a Microsoft Teams webhook channel that renders an
+// Adaptive Card.
+func NewTeams(cfg ChatWebhookConfig) (Service, error) {
+	return newChatWebhook("teams", cfg, func(msg Message) interface{} { return teamsDocument(msg) })
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// teamsElement covers the TextBlock, FactSet and CodeBlock card elements.
+type teamsElement struct {
+	Type        string      `json:"type"`
+	Text        string      `json:"text,omitempty"`
+	Weight      string      `json:"weight,omitempty"`
+	Size        string      `json:"size,omitempty"`
+	Color       string      `json:"color,omitempty"`
+	Wrap        bool        `json:"wrap,omitempty"`
+	Facts       []teamsFact `json:"facts,omitempty"`
+	CodeSnippet string      `json:"codeSnippet,omitempty"`
+	Language    string      `json:"language,omitempty"`
+}
+
+type teamsCard struct {
+	Schema  string            `json:"$schema"`
+	Type    string            `json:"type"`
+	Version string            `json:"version"`
+	Body    []teamsElement    `json:"body"`
+	MSTeams map[string]string `json:"msteams"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+func teamsDocument(msg Message) teamsPayload {
+	var body []teamsElement
+	if title := strings.TrimSpace(msg.Title); title != "" {
+		heading := teamsElement{Type: "TextBlock", Text: title, Weight: "Bolder", Size: "Medium", Wrap: true}
+		if msg.EventType == "agent-turn-paused" {
+			heading.Color = "Warning"
+		}
+		body = append(body, heading)
+	}
+	if facts := chatFacts(msg); len(facts) > 0 {
+		set := teamsElement{Type: "FactSet"}
+		for _, fact := range facts {
+			set.Facts = append(set.Facts, teamsFact{Title: fact.Name, Value: fact.Value})
+		}
+		body = append(body, set)
+	}
+
+	remaining := teamsTextLimit
+	for _, seg := range splitCodeSegments(chatMessageText(msg)) {
+		if remaining <= 0 {
+			break
+		}
+		text := truncateRunes(seg.Text, remaining)
+		remaining -= len([]rune(text))
+		if seg.Code {
+			body = append(body, teamsElement{Type: "CodeBlock", CodeSnippet: text, Language: teamsCodeLanguage(seg.Lang)})
+		} else {
+			body = append(body, teamsElement{Type: "TextBlock", Text: text, Wrap: true})
+		}
+	}
+
+	return teamsPayload{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.5",
+				Body:    body,
+				MSTeams: map[string]string{"width": "Full"},
+			},
+		}},
+	}
+}
+
+// teamsCodeLanguage maps a Markdown fence language to a CodeBlock language.
+func teamsCodeLanguage(lang string) string {
+	switch strings.ToLower(strings.TrimSpace(lang)) {
+	case "bash", "sh", "shell", "zsh":
+		return "Bash"
+	case "c":
+		return "C"
+	case "cpp", "c++":
+		return "C++"
+	case "cs", "csharp", "c#":
+		return "C#"
+	case "css":
+		return "CSS"
+	case "go", "golang":
+		return "Go"
+	case "html":
+		return "HTML"
+	case "java":
+		return "Java"
+	case "js", "javascript":
+		return "JavaScript"
+	case "json":
+		return "JSON"
+	case "powershell", "ps1", "pwsh":
+		return "PowerShell"
+	case "py", "python":
+		return "Python"
+	case "sql":
+		return "SQL"
+	case "ts", "typescript":
+		return "TypeScript"
+	case "xml":
+		return "XML"
+	default:
+		return "PlainText"
+	}
+}
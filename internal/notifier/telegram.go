@@ -0,0 +1,296 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cc-notify/internal/i18n"
+)
+
+const (
+	defaultTelegramAPIBase = "https://api.telegram.org"
+	// telegramPollSeconds is the long-poll window passed to getUpdates.
+	telegramPollSeconds = 25
+	// telegramCallbackLimit is the Bot API limit for callback_data.
+	telegramCallbackLimit = 64
+	telegramRespondPrefix = "respond?"
+)
+
+// TelegramConfig configures the Telegram Bot API channel.
+type TelegramConfig struct {
+	// APIBase defaults to https://api.telegram.org.
+	APIBase string
+	Token   string
+	// ChatID is a numeric chat id or an @channel username.
+	ChatID  string
+	Timeout time.Duration
+	// Language translates the answers to button taps.
+	Language i18n.Language
+}
+
+// Telegram sends messages through a Telegram bot. Approval actions become
+// inline keyboard buttons; taps are collected with PollActions.
+type Telegram struct {
+	endpoint   string
+	chatID     string
+	client     *http.Client
+	pollClient *http.Client
+	lang       i18n.Language
+}
+
+// NewTelegram creates a Telegram Bot API channel.
+func NewTelegram(cfg TelegramConfig) (*Telegram, error) {
+	base := strings.TrimSpace(cfg.APIBase)
+	if base == "" {
+		base = defaultTelegramAPIBase
+	}
+	base, err := validateHTTPURL(base)
+	if err != nil {
+		return nil, fmt.Errorf("telegram api base: %w", err)
+	}
+	token := strings.TrimSpace(cfg.Token)
+	if token == "" || strings.ContainsAny(token, "/?# ") {
+		return nil, fmt.Errorf("telegram: invalid bot token")
+	}
+	chatID := strings.TrimSpace(cfg.ChatID)
+	if chatID == "" {
+		return nil, fmt.Errorf("telegram: chat id is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &Telegram{
+		endpoint:   strings.TrimRight(base, "/") + "/bot" + token + "/",
+		chatID:     chatID,
+		client:     &http.Client{Timeout: timeout},
+		pollClient: &http.Client{Timeout: timeout + telegramPollSeconds*time.Second},
+		lang:       cfg.Language,
+	}, nil
+}
+
+type telegramButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+type telegramKeyboard struct {
+	InlineKeyboard [][]telegramButton `json:"inline_keyboard"`
+}
+
+type telegramSendMessage struct {
+	ChatID                string            `json:"chat_id"`
+	Text                  string            `json:"text"`
+	DisableWebPagePreview bool              `json:"disable_web_page_preview,omitempty"`
+	ReplyMarkup           *telegramKeyboard `json:"reply_markup,omitempty"`
+}
+
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+type telegramUpdate struct {
+	UpdateID      int64                  `json:"update_id"`
+	CallbackQuery *telegramCallbackQuery `json:"callback_query"`
+}
+
+type telegramCallbackQuery struct {
+	ID      string `json:"id"`
+	Data    string `json:"data"`
+	Message *struct {
+		MessageID int64 `json:"message_id"`
+		Chat      struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+func (t *Telegram) Notify(title, body string) error {
+	return t.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (t *Telegram) NotifyWithActions(title, body string, actions []Action) error {
+	return t.NotifyMessage(Message{Title: title, Body: body, Actions: actions})
+}
+
+// Dismiss does nothing: answered prompts are already edited in the chat.
+func (t *Telegram) Dismiss(_, _ string) error {
+	return nil
+}
+
+func (t *Telegram) NotifyMessage(msg Message) error {
+	req := telegramSendMessage{
+		ChatID:                t.chatID,
+		Text:                  telegramText(msg),
+		DisableWebPagePreview: true,
+		ReplyMarkup:           telegramActionKeyboard(msg.Actions),
+	}
+	if err := t.call(t.client, "sendMessage", req, nil); err != nil {
+		return fmt.Errorf("send telegram message: %w", err)
+	}
+	return nil
+}
+
+func telegramText(msg Message) string {
+	title := strings.TrimSpace(msg.Title)
+	body := strings.TrimSpace(msg.Body)
+	switch {
+	case title == "":
+		return body
+	case body == "":
+		return title
+	}
+	return title + "\n\n" + body
+}
+
+// telegramActionKeyboard turns cc-notify://respond actions into one button
+// per row. The callback data carries the respond query, so a tap can be
+// resolved without any server-side state.
+func telegramActionKeyboard(actions []Action) *telegramKeyboard {
+	var rows [][]telegramButton
+	for _, action := range actions {
+		query, ok := respondQuery(action.URI)
+		if !ok || strings.TrimSpace(action.Label) == "" {
+			continue
+		}
+		data := telegramRespondPrefix + query.Encode()
+		if len(data) > telegramCallbackLimit {
+			continue
+		}
+		rows = append(rows, []telegramButton{{Text: action.Label, CallbackData: data}})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &telegramKeyboard{InlineKeyboard: rows}
+}
+
+// PollActions long-polls getUpdates for inline keyboard taps and hands each
+// one to handle as a cc-notify://respond URI. Taps from other chats are
+// ignored. The tapped message loses its keyboard once handle succeeds so a
+// decision cannot be sent twice. PollActions returns when ctx is done or the
+// Bot API fails.
+func (t *Telegram) PollActions(ctx context.Context, handle func(uri string) error) error {
+	var offset int64
+	for {
+		params := map[string]interface{}{
+			"timeout":         telegramPollSeconds,
+			"allowed_updates": []string{"callback_query"},
+		}
+		if offset > 0 {
+			params["offset"] = offset
+		}
+		var updates []telegramUpdate
+		if err := t.callContext(ctx, t.pollClient, "getUpdates", params, &updates); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("poll telegram updates: %w", err)
+		}
+		for _, update := range updates {
+			if update.UpdateID >= offset {
+				offset = update.UpdateID + 1
+			}
+			if update.CallbackQuery != nil {
+				t.handleCallback(update.CallbackQuery, handle)
+			}
+		}
+	}
+}
+
+func (t *Telegram) handleCallback(query *telegramCallbackQuery, handle func(uri string) error) {
+	if query.Message == nil || !t.ownsChat(query.Message.Chat.ID) || !strings.HasPrefix(query.Data, telegramRespondPrefix) {
+		_ = t.call(t.client, "answerCallbackQuery", map[string]interface{}{
+			"callback_query_id": query.ID,
+			"text":              t.lang.T("Unknown action"),
+		}, nil)
+		return
+	}
+
+	answer := t.lang.T("Response sent")
+	err := handle("cc-notify://" + query.Data)
+	if err != nil {
+		answer = t.lang.Sprintf("Unable to apply response: %v", err)
+	}
+	_ = t.call(t.client, "answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": query.ID,
+		"text":              truncateRunes(answer, 200),
+		"show_alert":        err != nil,
+	}, nil)
+	if err == nil {
+		_ = t.call(t.client, "editMessageReplyMarkup", map[string]interface{}{
+			"chat_id":      query.Message.Chat.ID,
+			"message_id":   query.Message.MessageID,
+			"reply_markup": telegramKeyboard{InlineKeyboard: [][]telegramButton{}},
+		}, nil)
+	}
+}
+
+// ownsChat reports whether a callback came from the configured chat. Chats
+// configured by @username cannot be compared and are trusted as-is.
+func (t *Telegram) ownsChat(id int64) bool {
+	want, err := strconv.ParseInt(t.chatID, 10, 64)
+	if err != nil {
+		return true
+	}
+	return want == id
+}
+
+func (t *Telegram) call(client *http.Client, method string, params interface{}, result interface{}) error {
+	return t.callContext(context.Background(), client, method, params, result)
+}
+
+func (t *Telegram) callContext(ctx context.Context, client *http.Client, method string, params interface{}, result interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint+method, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cc-notify")
+	resp, err := client.Do(req)
+	if err != nil {
+		// The request URL embeds the bot token; keep it out of error text.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var out telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return fmt.Errorf("%s: decode response (%s): %w", method, resp.Status, err)
+	}
+	if !out.OK {
+		return fmt.Errorf("%s: %s", method, strings.TrimSpace(out.Description))
+	}
+	if result != nil {
+		if err := json.Unmarshal(out.Result, result); err != nil {
+			return fmt.Errorf("%s: decode result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
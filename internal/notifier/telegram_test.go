@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBotAPI records Bot API calls and serves queued getUpdates batches.
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	calls   map[string][]map[string]interface{}
+	updates [][]map[string]interface{}
+	// drained runs when getUpdates is called with no batches left.
+	drained func()
+}
+
+func newFakeBotAPI(t *testing.T) (*fakeBotAPI, *httptest.Server) {
+	t.Helper()
+	api := &fakeBotAPI{calls: map[string][]map[string]interface{}{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/bot123:abc/") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"ok":false,"description":"Not Found"}`)
+			return
+		}
+		method := strings.TrimPrefix(r.URL.Path, "/bot123:abc/")
+		var params map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+
+		api.mu.Lock()
+		api.calls[method] = append(api.calls[method], params)
+		var result interface{} = true
+		if method == "getUpdates" {
+			result = []interface{}{}
+			if len(api.updates) > 0 {
+				result = api.updates[0]
+				api.updates = api.updates[1:]
+			} else if api.drained != nil {
+				api.drained()
+			}
+		}
+		api.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return api, server
+}
+
+func (f *fakeBotAPI) callsFor(method string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]interface{}{}, f.calls[method]...)
+}
+
+func callbackUpdate(updateID int, chatID int64, data string) map[string]interface{} {
+	return map[string]interface{}{
+		"update_id": updateID,
+		"callback_query": map[string]interface{}{
+			"id":   "cb" + data,
+			"data": data,
+			"message": map[string]interface{}{
+				"message_id": 55,
+				"chat":       map[string]interface{}{"id": chatID},
+			},
+		},
+	}
+}
+
+func TestTelegramNotifyMessage_SendsInlineKeyboard(t *testing.T) {
+	api, server := newFakeBotAPI(t)
+	tg, err := NewTelegram(TelegramConfig{APIBase: server.URL, Token: "123:abc", ChatID: "42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = tg.NotifyMessage(Message{
+		Title:     "Codex Needs Input",
+		Body:      "Run `ls`?",
+		EventType: "agent-turn-paused",
+		Actions: []Action{
+			{Label: "Yes, proceed", URI: "cc-notify://respond?decision=proceed&id=0123456789abcdef"},
+			{Label: "Yes, always", URI: "cc-notify://respond?decision=proceed-always&id=0123456789abcdef"},
+			{Label: "No", URI: "cc-notify://respond?decision=reject&id=0123456789abcdef"},
+			{Label: "Open", URI: "https://example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := api.callsFor("sendMessage")
+	if len(sent) != 1 {
+		t.Fatalf("expected one sendMessage call, got %d", len(sent))
+	}
+	if sent[0]["chat_id"] != "42" || sent[0]["text"] != "Codex Needs Input\n\nRun `ls`?" {
+		t.Fatalf("unexpected message: %v", sent[0])
+	}
+	markup, _ := sent[0]["reply_markup"].(map[string]interface{})
+	rows, _ := markup["inline_keyboard"].([]interface{})
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 keyboard rows, got %v", markup)
+	}
+	button := rows[1].([]interface{})[0].(map[string]interface{})
+	if button["text"] != "Yes, always" || button["callback_data"] != "respond?decision=proceed-always&id=0123456789abcdef" {
+		t.Fatalf("unexpected button: %v", button)
+	}
+}
+
+func TestTelegramNotify_WithoutActionsHasNoKeyboard(t *testing.T) {
+	api, server := newFakeBotAPI(t)
+	tg, err := NewTelegram(TelegramConfig{APIBase: server.URL, Token: "123:abc", ChatID: "@builds"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := tg.Notify("Done", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sent := api.callsFor("sendMessage")
+	if _, ok := sent[0]["reply_markup"]; ok || sent[0]["text"] != "Done" {
+		t.Fatalf("unexpected message: %v", sent[0])
+	}
+}
+
+func TestTelegramPollActions_DispatchesOwnChatCallbacks(t *testing.T) {
+	api, server := newFakeBotAPI(t)
+	api.updates = [][]map[string]interface{}{
+		{
+			callbackUpdate(10, 99, "respond?decision=proceed&id=0123456789abcdef"),
+			callbackUpdate(11, 42, "respond?decision=reject&id=0123456789abcdef"),
+		},
+	}
+	tg, err := NewTelegram(TelegramConfig{APIBase: server.URL, Token: "123:abc", ChatID: "42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	api.drained = cancel
+	var got []string
+	err = tg.PollActions(ctx, func(uri string) error {
+		got = append(got, uri)
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if len(got) != 1 || got[0] != "cc-notify://respond?decision=reject&id=0123456789abcdef" {
+		t.Fatalf("unexpected dispatched uris: %v", got)
+	}
+
+	answers := api.callsFor("answerCallbackQuery")
+	if len(answers) != 2 {
+		t.Fatalf("expected both callbacks to be answered, got %v", answers)
+	}
+	edits := api.callsFor("editMessageReplyMarkup")
+	if len(edits) != 1 || edits[0]["message_id"] != float64(55) {
+		t.Fatalf("expected the answered keyboard to be removed, got %v", edits)
+	}
+	polls := api.callsFor("getUpdates")
+	if len(polls) < 2 || polls[1]["offset"] != float64(12) {
+		t.Fatalf("expected offset to advance past handled updates, got %v", polls)
+	}
+}
+
+func TestTelegramErrors_DoNotLeakToken(t *testing.T) {
+	tg, err := NewTelegram(TelegramConfig{APIBase: "http://127.0.0.1:1", Token: "123:secret", ChatID: "42"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = tg.Notify("title", "body")
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("expected error without token, got %v", err)
+	}
+}
+
+func TestNewTelegram_Validation(t *testing.T) {
+	if _, err := NewTelegram(TelegramConfig{Token: "", ChatID: "1"}); err == nil {
+		t.Fatalf("expected error for missing token")
+	}
+	if _, err := NewTelegram(TelegramConfig{Token: "1:a", ChatID: " "}); err == nil {
+		t.Fatalf("expected error for missing chat id")
+	}
+	if _, err := NewTelegram(TelegramConfig{APIBase: "ftp://x", Token: "1:a", ChatID: "1"}); err == nil {
+		t.Fatalf("expected error for non-http api base")
+	}
+}
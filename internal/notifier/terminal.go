@@ -0,0 +1,147 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Terminal notification styles for mode "terminal".
+const (
+	TerminalStyleAuto   = "auto"
+	TerminalStyleOSC9   = "osc9"
+	TerminalStyleOSC777 = "osc777"
+	TerminalStyleBell   = "bell"
+)
+
+// maxTTYDepth bounds the parent-process walk when looking for a terminal.
+const maxTTYDepth = 16
+
+var errNoTTY = errors.New("no controlling terminal found in the parent process chain")
+
+// terminalNotifier writes a notification escape sequence to the terminal the
+// agent runs in. It works over SSH and without any desktop service.
+type terminalNotifier struct {
+	style   string
+	pid     int
+	getenv  func(string) string
+	findTTY func(pid int) (string, error)
+	open    func(path string) (io.WriteCloser, error)
+}
+
+// IsTerminalMode reports whether mode selects the terminal escape-sequence backend.
+func IsTerminalMode(mode string) bool {
+	return strings.EqualFold(strings.TrimSpace(mode), "terminal")
+}
+
+func newTerminalNotifier(cfg Config) Service {
+	pid := cfg.TerminalPID
+	if pid <= 0 {
+		pid = os.Getppid()
+	}
+	return &terminalNotifier{
+		style:   strings.ToLower(strings.TrimSpace(cfg.TerminalStyle)),
+		pid:     pid,
+		getenv:  os.Getenv,
+		findTTY: controllingTTY,
+		open:    openTTY,
+	}
+}
+
+func openTTY(path string) (io.WriteCloser, error) {
+	return os.OpenFile(path, os.O_WRONLY, 0)
+}
+
+func (n *terminalNotifier) Notify(title, body string) error {
+	path, err := n.findTTY(n.pid)
+	if err != nil {
+		return fmt.Errorf("send terminal notification: %w", err)
+	}
+	tty, err := n.open(path)
+	if err != nil {
+		return fmt.Errorf("send terminal notification: open %s: %w", path, err)
+	}
+	defer tty.Close()
+
+	seq := terminalSequence(n.resolveStyle(), title, body)
+	if n.getenv("TMUX") != "" {
+		seq = tmuxPassthrough(seq)
+	}
+	if _, err := io.WriteString(tty, seq); err != nil {
+		return fmt.Errorf("send terminal notification: write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (n *terminalNotifier) resolveStyle() string {
+	switch n.style {
+	case TerminalStyleOSC9, TerminalStyleOSC777, TerminalStyleBell:
+		return n.style
+	}
+	return detectTerminalStyle(n.getenv)
+}
+
+// detectTerminalStyle guesses the richest sequence the terminal understands
+// from the environment the agent was started in.
+func detectTerminalStyle(getenv func(string) string) string {
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "ghostty":
+		return TerminalStyleOSC9
+	}
+	if getenv("WT_SESSION") != "" {
+		return TerminalStyleOSC9
+	}
+	term := getenv("TERM")
+	for _, name := range []string{"kitty", "foot", "rxvt"} {
+		if strings.Contains(term, name) {
+			return TerminalStyleOSC777
+		}
+	}
+	if getenv("VTE_VERSION") != "" {
+		return TerminalStyleOSC777
+	}
+	return TerminalStyleBell
+}
+
+// terminalSequence builds the escape sequence for style. OSC payloads cannot
+// contain control characters, so title and body are flattened to one line.
+func terminalSequence(style, title, body string) string {
+	title = sanitizeOSCText(title)
+	body = sanitizeOSCText(body)
+	switch style {
+	case TerminalStyleOSC9:
+		text := title
+		if body != "" {
+			text += ": " + body
+		}
+		return "\x1b]9;" + text + "\x07"
+	case TerminalStyleOSC777:
+		return "\x1b]777;notify;" + strings.ReplaceAll(title, ";", ",") + ";" + body + "\x07"
+	default:
+		return "\x07"
+	}
+}
+
+func sanitizeOSCText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteRune(' ')
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) || r == utf8.RuneError:
+			// Drops ESC, BEL, C1 controls such as ST and stray bytes that an
+			// 8-bit terminal could read as C1; any of them could end the OSC early.
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// tmuxPassthrough wraps seq so tmux forwards it to the outer terminal.
+func tmuxPassthrough(seq string) string {
+	return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func newTestTerminalNotifier(style string, env map[string]string, out *bytes.Buffer) (*terminalNotifier, *[]int) {
+	var pids []int
+	return &terminalNotifier{
+		style:  style,
+		pid:    4242,
+		getenv: func(key string) string { return env[key] },
+		findTTY: func(pid int) (string, error) {
+			pids = append(pids, pid)
+			return "/dev/pts/7", nil
+		},
+		open: func(path string) (io.WriteCloser, error) {
+			if path != "/dev/pts/7" {
+				return nil, errors.New("unexpected tty " + path)
+			}
+			return nopWriteCloser{out}, nil
+		},
+	}, &pids
+}
+
+func TestTerminalNotifier_WritesSequenceToAgentTTY(t *testing.T) {
+	cases := []struct {
+		style string
+		env   map[string]string
+		want  string
+	}{
+		{style: "osc9", want: "\x1b]9;Codex Task Complete: fixed tests\x07"},
+		{style: "osc777", want: "\x1b]777;notify;Codex Task Complete;fixed tests\x07"},
+		{style: "bell", want: "\x07"},
+		{style: "auto", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: "\x1b]9;Codex Task Complete: fixed tests\x07"},
+		{style: "", env: map[string]string{"TERM": "xterm-kitty"}, want: "\x1b]777;notify;Codex Task Complete;fixed tests\x07"},
+		{style: "auto", env: map[string]string{"TERM": "xterm-256color"}, want: "\x07"},
+		{style: "osc9", env: map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"}, want: "\x1bPtmux;\x1b\x1b]9;Codex Task Complete: fixed tests\x07\x1b\\"},
+	}
+	for _, tc := range cases {
+		var out bytes.Buffer
+		n, pids := newTestTerminalNotifier(tc.style, tc.env, &out)
+		if err := n.Notify("Codex Task Complete", "fixed\ntests"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.style, err)
+		}
+		if out.String() != tc.want {
+			t.Fatalf("%s %v: wrote %q, want %q", tc.style, tc.env, out.String(), tc.want)
+		}
+		if len(*pids) != 1 || (*pids)[0] != 4242 {
+			t.Fatalf("expected tty lookup from the configured pid, got %v", *pids)
+		}
+	}
+}
+
+func TestTerminalSequence_StripsControlCharacters(t *testing.T) {
+	got := terminalSequence(TerminalStyleOSC777, "a;b\x1b]0;evil\x07", "body\x9cmid\u009cend")
+	if strings.Count(got, "\x1b") != 1 || strings.Count(got, "\x07") != 1 {
+		t.Fatalf("control characters leaked into sequence: %q", got)
+	}
+	if got != "\x1b]777;notify;a,b]0,evil;bodymidend\x07" {
+		t.Fatalf("unexpected sequence: %q", got)
+	}
+}
+
+func TestTerminalNotifier_ReportsMissingTTY(t *testing.T) {
+	n := &terminalNotifier{
+		pid:     1,
+		getenv:  func(string) string { return "" },
+		findTTY: func(int) (string, error) { return "", errNoTTY },
+	}
+	err := n.Notify("title", "body")
+	if err == nil || !errors.Is(err, errNoTTY) {
+		t.Fatalf("expected missing tty error, got %v", err)
+	}
+}
+
+func TestIsTerminalMode(t *testing.T) {
+	if !IsTerminalMode(" Terminal ") || IsTerminalMode("toast") {
+		t.Fatalf("unexpected terminal mode detection")
+	}
+}
@@ -0,0 +1,295 @@
+package notifier
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Toast scenarios. Reminder and urgent toasts stay on screen until the user
+// acts; urgent also breaks through Focus Assist on Windows 11.
+const (
+	ToastScenarioDefault  = "default"
+	ToastScenarioReminder = "reminder"
+	ToastScenarioUrgent   = "urgent"
+	ToastScenarioAlarm    = "alarm"
+)
+
+// ToastAudioSilent mutes a toast.
+const ToastAudioSilent = "silent"
+
+// Toast is a Windows ToastGeneric notification. XML renders it as a document
+// ready for Windows.Data.Xml.Dom.XmlDocument.LoadXml.
+type Toast struct {
+	Title string
+	Body  string
+	// Attribution is the small caption under the body, e.g. "via Codex".
+	Attribution string
+	// Logo replaces the app logo; HeroImage is shown across the top. Both
+	// accept local paths or file:, http(s): and ms-appdata: URIs.
+	Logo       string
+	LogoCircle bool
+	HeroImage  string
+	// Audio is an ms-winsoundevent: URI or a short name such as "Reminder"
+	// or "Looping.Alarm2"; ToastAudioSilent mutes the toast.
+	Audio     string
+	AudioLoop bool
+	// Scenario is one of the ToastScenario constants; empty means default.
+	Scenario string
+	// Header groups toasts that share its ID in Action Center.
+	Header  *ToastHeader
+	Actions []Action
+}
+
+// ToastHeader groups related toasts under a common title.
+type ToastHeader struct {
+	ID    string
+	Title string
+	// Arguments is a URI opened when the header is clicked; optional.
+	Arguments string
+}
+
+type toastXML struct {
+	XMLName  xml.Name         `xml:"toast"`
+	Scenario string           `xml:"scenario,attr,omitempty"`
+	Header   *toastHeaderXML  `xml:"header,omitempty"`
+	Visual   toastVisualXML   `xml:"visual"`
+	Actions  *toastActionsXML `xml:"actions,omitempty"`
+	Audio    *toastAudioXML   `xml:"audio,omitempty"`
+}
+
+type toastHeaderXML struct {
+	ID             string `xml:"id,attr"`
+	Title          string `xml:"title,attr"`
+	Arguments      string `xml:"arguments,attr"`
+	ActivationType string `xml:"activationType,attr,omitempty"`
+}
+
+type toastVisualXML struct {
+	Binding toastBindingXML `xml:"binding"`
+}
+
+type toastBindingXML struct {
+	Template string          `xml:"template,attr"`
+	Texts    []toastTextXML  `xml:"text"`
+	Images   []toastImageXML `xml:"image"`
+}
+
+type toastTextXML struct {
+	Placement string `xml:"placement,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type toastImageXML struct {
+	Placement string `xml:"placement,attr"`
+	Src       string `xml:"src,attr"`
+	HintCrop  string `xml:"hint-crop,attr,omitempty"`
+}
+
+type toastActionsXML struct {
+	Inputs  []toastInputXML  `xml:"input"`
+	Actions []toastActionXML `xml:"action"`
+}
+
+type toastInputXML struct {
+	ID          string `xml:"id,attr"`
+	Type        string `xml:"type,attr"`
+	PlaceHolder string `xml:"placeHolderContent,attr,omitempty"`
+}
+
+type toastActionXML struct {
+	Content        string `xml:"content,attr"`
+	ActivationType string `xml:"activationType,attr"`
+	Arguments      string `xml:"arguments,attr"`
+	HintInputID    string `xml:"hint-inputId,attr,omitempty"`
+}
+
+type toastAudioXML struct {
+	Src    string `xml:"src,attr,omitempty"`
+	Loop   bool   `xml:"loop,attr,omitempty"`
+	Silent bool   `xml:"silent,attr,omitempty"`
+}
+
+// toastMaxActions is the number of buttons Windows shows on a toast.
+const toastMaxActions = 5
+
+// XML renders the toast. Text is escaped by the encoder, so titles and
+// bodies may contain any characters.
+func (t Toast) XML() (string, error) {
+	doc := toastXML{Visual: toastVisualXML{Binding: toastBindingXML{Template: "ToastGeneric"}}}
+
+	switch strings.TrimSpace(t.Scenario) {
+	case "", ToastScenarioDefault:
+	case ToastScenarioReminder, ToastScenarioUrgent, ToastScenarioAlarm:
+		doc.Scenario = strings.TrimSpace(t.Scenario)
+	default:
+		return "", fmt.Errorf("toast: unknown scenario %q", t.Scenario)
+	}
+
+	if t.Header != nil && strings.TrimSpace(t.Header.ID) != "" {
+		header := &toastHeaderXML{ID: t.Header.ID, Title: t.Header.Title, Arguments: t.Header.Arguments}
+		if header.Arguments != "" {
+			header.ActivationType = "protocol"
+		}
+		doc.Header = header
+	}
+
+	binding := &doc.Visual.Binding
+	binding.Texts = append(binding.Texts, toastTextXML{Value: t.Title}, toastTextXML{Value: t.Body})
+	if attribution := strings.TrimSpace(t.Attribution); attribution != "" {
+		binding.Texts = append(binding.Texts, toastTextXML{Placement: "attribution", Value: attribution})
+	}
+	if t.Logo != "" {
+		src, err := toastImageSource(t.Logo)
+		if err != nil {
+			return "", fmt.Errorf("toast logo: %w", err)
+		}
+		image := toastImageXML{Placement: "appLogoOverride", Src: src}
+		if t.LogoCircle {
+			image.HintCrop = "circle"
+		}
+		binding.Images = append(binding.Images, image)
+	}
+	if t.HeroImage != "" {
+		src, err := toastImageSource(t.HeroImage)
+		if err != nil {
+			return "", fmt.Errorf("toast hero image: %w", err)
+		}
+		binding.Images = append(binding.Images, toastImageXML{Placement: "hero", Src: src})
+	}
+
+	// A toast shows a single text box, used by every action with Input.
+	// Those actions are activated in the foreground because protocol
+	// activation does not pass the text on.
+	var inputs []toastInputXML
+	var actions []toastActionXML
+	for _, action := range t.Actions {
+		if strings.TrimSpace(action.Label) == "" || strings.TrimSpace(action.URI) == "" {
+			continue
+		}
+		if len(actions) == toastMaxActions {
+			break
+		}
+		item := toastActionXML{Content: action.Label, ActivationType: "protocol", Arguments: action.URI}
+		if action.Input != "" {
+			if inputs == nil {
+				inputs = append(inputs, toastInputXML{ID: FeedbackParam, Type: "text", PlaceHolder: action.Input})
+			}
+			item.ActivationType = "foreground"
+			item.HintInputID = FeedbackParam
+		}
+		actions = append(actions, item)
+	}
+	if len(actions) > 0 {
+		doc.Actions = &toastActionsXML{Inputs: inputs, Actions: actions}
+	}
+
+	audio, err := toastAudio(t.Audio, t.AudioLoop)
+	if err != nil {
+		return "", err
+	}
+	doc.Audio = audio
+
+	out, err := xml.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("toast: %w", err)
+	}
+	return string(out), nil
+}
+
+// ToastForMessage builds the toast for msg: the agent as attribution, a
+// header per project directory and the approval scenario when msg carries
+// actions.
+func ToastForMessage(msg Message, opts ToastOptions) Toast {
+	t := Toast{
+		Title:      msg.Title,
+		Body:       msg.Body,
+		Logo:       opts.Logo,
+		LogoCircle: opts.LogoCircle,
+		HeroImage:  opts.HeroImage,
+		Audio:      opts.Audio,
+		Actions:    msg.Actions,
+	}
+	if label := sourceLabel(msg.Source); label != "" {
+		t.Attribution = msg.Language.Sprintf("via %s", label)
+	}
+	if len(msg.Actions) > 0 {
+		t.Scenario = opts.ApprovalScenario
+		if t.Scenario == "" {
+			t.Scenario = ToastScenarioReminder
+		}
+	}
+	if name := projectName(msg.CWD); name != "" {
+		t.Header = &ToastHeader{
+			ID:        normalizeDir(msg.CWD),
+			Title:     name,
+			Arguments: folderURI(msg.CWD),
+		}
+	}
+	return t
+}
+
+// folderURI returns a file URI for a Windows directory so clicking a toast
+// header opens it in Explorer. Other paths get no URI.
+func folderURI(dir string) string {
+	dir = strings.TrimSpace(dir)
+	if !isWindowsAbsPath(dir) {
+		return ""
+	}
+	return (&url.URL{Scheme: "file", Path: "/" + strings.ReplaceAll(dir, `\`, "/")}).String()
+}
+
+// toastImageSource turns a local path into a file URI and passes URIs
+// through. Desktop apps cannot use ms-appx: images.
+func toastImageSource(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if isWindowsAbsPath(raw) {
+		return "file:///" + strings.ReplaceAll(raw, `\`, "/"), nil
+	}
+	if strings.HasPrefix(raw, `\\`) {
+		return "file:" + strings.ReplaceAll(raw, `\`, "/"), nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return "", fmt.Errorf("%q is neither an absolute path nor a URI", raw)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "file", "http", "https", "ms-appdata":
+		return raw, nil
+	}
+	return "", fmt.Errorf("unsupported image scheme %q", u.Scheme)
+}
+
+func isWindowsAbsPath(path string) bool {
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/') &&
+		((path[0] >= 'a' && path[0] <= 'z') || (path[0] >= 'A' && path[0] <= 'Z'))
+}
+
+// toastAudio maps a sound name to the audio element; nil keeps the default.
+func toastAudio(sound string, loop bool) (*toastAudioXML, error) {
+	sound = strings.TrimSpace(sound)
+	switch {
+	case sound == "":
+		return nil, nil
+	case strings.EqualFold(sound, ToastAudioSilent):
+		return &toastAudioXML{Silent: true}, nil
+	case strings.HasPrefix(sound, "ms-winsoundevent:"):
+	case strings.Contains(sound, ":"):
+		return nil, fmt.Errorf("toast audio must be an ms-winsoundevent sound, got %q", sound)
+	default:
+		sound = "ms-winsoundevent:Notification." + sound
+	}
+	return &toastAudioXML{Src: sound, Loop: loop}, nil
+}
+
+// sourceLabel is the display name of an agent source.
+func sourceLabel(source string) string {
+	switch strings.ToLower(strings.TrimSpace(source)) {
+	case "codex":
+		return "Codex"
+	case "claude":
+		return "Claude Code"
+	}
+	return strings.TrimSpace(source)
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func assertGoldenXML(t *testing.T, name, doc string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden.xml")
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(doc+"\n"), 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden (run with -update to create): %v", err)
+	}
+	if doc+"\n" != string(want) {
+		t.Fatalf("%s mismatch:\n got: %s\nwant: %s", name, doc, want)
+	}
+}
+
+func TestToastForMessage_Complete(t *testing.T) {
+	toast := ToastForMessage(chatFixture(), ToastOptions{
+		Logo:       `C:\Users\dev\cc-notify\logo.png`,
+		LogoCircle: true,
+		HeroImage:  "https://example.com/hero.png",
+		Audio:      "Mail",
+	})
+	doc, err := toast.XML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenXML(t, "toast_complete", doc)
+}
+
+func TestToastForMessage_PausedUsesReminderScenario(t *testing.T) {
+	msg := pausedChatFixture()
+	msg.Body = `Run "rm -rf build" & <continue>?`
+	msg.Actions = []Action{
+		{Label: "Yes, proceed", URI: "cc-notify://respond?id=0123456789abcdef&decision=proceed"},
+		{Label: "No, tell Claude what to do", URI: "cc-notify://respond?id=0123456789abcdef&decision=reject", Input: "What should Claude do instead?"},
+	}
+	doc, err := ToastForMessage(msg, ToastOptions{Audio: ToastAudioSilent}).XML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertGoldenXML(t, "toast_paused", doc)
+
+	urgent, err := ToastForMessage(msg, ToastOptions{ApprovalScenario: ToastScenarioUrgent}).XML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(urgent, `<toast scenario="urgent">`) {
+		t.Fatalf("expected configured approval scenario, got %s", urgent)
+	}
+}
+
+func TestToastXML_Minimal(t *testing.T) {
+	doc, err := Toast{Title: "title", Body: "body"}.XML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `<toast><visual><binding template="ToastGeneric"><text>title</text><text>body</text></binding></visual></toast>`
+	if doc != want {
+		t.Fatalf("unexpected minimal toast:\n got: %s\nwant: %s", doc, want)
+	}
+}
+
+func TestToastXML_RejectsInvalidOptions(t *testing.T) {
+	cases := []struct {
+		toast Toast
+		want  string
+	}{
+		{toast: Toast{Scenario: "incoming"}, want: "unknown scenario"},
+		{toast: Toast{Logo: "logo.png"}, want: "toast logo"},
+		{toast: Toast{HeroImage: "ms-appx:///hero.png"}, want: "unsupported image scheme"},
+		{toast: Toast{Audio: "https://example.com/ding.wav"}, want: "ms-winsoundevent"},
+	}
+	for _, tc := range cases {
+		_, err := tc.toast.XML()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%+v: expected error mentioning %q, got %v", tc.toast, tc.want, err)
+		}
+	}
+}
+
+func TestToastXML_CapsActions(t *testing.T) {
+	var actions []Action
+	for i := 0; i < 7; i++ {
+		actions = append(actions, Action{Label: "a", URI: "cc-notify://x"})
+	}
+	doc, err := Toast{Title: "t", Body: "b", Actions: actions}.XML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Count(doc, "<action "); got != toastMaxActions {
+		t.Fatalf("expected %d actions, got %d", toastMaxActions, got)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// controllingTTY walks up from pid through /proc and returns the first
+// terminal device attached to a standard stream.
+func controllingTTY(pid int) (string, error) {
+	return procTTY("/proc", pid)
+}
+
+func procTTY(procRoot string, pid int) (string, error) {
+	for depth := 0; pid > 1 && depth < maxTTYDepth; depth++ {
+		for _, fd := range []string{"1", "2", "0"} {
+			target, err := os.Readlink(filepath.Join(procRoot, strconv.Itoa(pid), "fd", fd))
+			if err == nil && isTTYDevice(target) {
+				return target, nil
+			}
+		}
+		parent, err := procParentPID(procRoot, pid)
+		if err != nil {
+			break
+		}
+		pid = parent
+	}
+	return "", errNoTTY
+}
+
+// procParentPID reads the ppid field of /proc/<pid>/stat. The command name
+// may contain spaces or parentheses, so fields are read after the last ')'.
+func procParentPID(procRoot string, pid int) (int, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, err
+	}
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed stat for pid %d", pid)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+func isTTYDevice(path string) bool {
+	return strings.HasPrefix(path, "/dev/pts/") || (strings.HasPrefix(path, "/dev/tty") && path != "/dev/tty")
+}
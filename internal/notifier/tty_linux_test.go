@@ -0,0 +1,51 @@
+//go:build linux
+
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakeProc creates /proc/<pid>/stat and fd links for a fake process.
+func writeFakeProc(t *testing.T, root string, pid, ppid string, fds map[string]string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	stat := pid + " (node (worker) x) S " + ppid + " 1 1 0 -1\n"
+	if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+		t.Fatalf("write stat: %v", err)
+	}
+	for fd, target := range fds {
+		if err := os.Symlink(target, filepath.Join(dir, "fd", fd)); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+	}
+}
+
+func TestProcTTY_WalksParentChain(t *testing.T) {
+	root := t.TempDir()
+	// hook (pipes) -> shell wrapper (/dev/null) -> agent (pts)
+	writeFakeProc(t, root, "300", "200", map[string]string{"0": "pipe:[1]", "1": "pipe:[2]", "2": "pipe:[3]"})
+	writeFakeProc(t, root, "200", "100", map[string]string{"1": "/dev/null", "2": "/dev/tty"})
+	writeFakeProc(t, root, "100", "1", map[string]string{"0": "/dev/pts/3", "1": "/dev/pts/3"})
+
+	got, err := procTTY(root, 300)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "/dev/pts/3" {
+		t.Fatalf("unexpected tty: %q", got)
+	}
+}
+
+func TestProcTTY_NoTerminal(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProc(t, root, "300", "1", map[string]string{"1": "pipe:[2]"})
+	if _, err := procTTY(root, 300); err != errNoTTY {
+		t.Fatalf("expected errNoTTY, got %v", err)
+	}
+}
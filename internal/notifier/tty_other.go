@@ -0,0 +1,36 @@
+//go:build !windows && !linux
+
+package notifier
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// controllingTTY walks up from pid with ps(1) and returns the first process
+// that has a controlling terminal.
+func controllingTTY(pid int) (string, error) {
+	for depth := 0; pid > 1 && depth < maxTTYDepth; depth++ {
+		out, err := exec.Command("ps", "-o", "tty=,ppid=", "-p", strconv.Itoa(pid)).Output()
+		if err != nil {
+			break
+		}
+		fields := strings.Fields(string(out))
+		if len(fields) < 2 {
+			break
+		}
+		if tty := fields[0]; tty != "??" && tty != "?" && tty != "-" {
+			if !strings.HasPrefix(tty, "/dev/") {
+				tty = "/dev/" + tty
+			}
+			return tty, nil
+		}
+		parent, err := strconv.Atoi(fields[1])
+		if err != nil {
+			break
+		}
+		pid = parent
+	}
+	return "", errNoTTY
+}
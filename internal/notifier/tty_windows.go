@@ -0,0 +1,9 @@
+//go:build windows
+
+package notifier
+
+// controllingTTY returns the console of the current process. Hook processes
+// share the agent's console, and Windows Terminal understands OSC 9.
+func controllingTTY(_ int) (string, error) {
+	return "CONOUT$", nil
+}
@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookConfig configures the generic HTTP webhook channel.
+type WebhookConfig struct {
+	URL     string
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+// webhookDocument is the JSON body posted to the webhook URL.
+type webhookDocument struct {
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	Source         string `json:"source,omitempty"`
+	Event          string `json:"event,omitempty"`
+	CWD            string `json:"cwd,omitempty"`
+	Model          string `json:"model,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+}
+
+type webhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhook creates a channel that POSTs each notification as JSON.
+func NewWebhook(cfg WebhookConfig) (Service, error) {
+	target, err := validateHTTPURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &webhookNotifier{
+		url:     target,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (n *webhookNotifier) Notify(title, body string) error {
+	return n.NotifyMessage(Message{Title: title, Body: body})
+}
+
+func (n *webhookNotifier) NotifyMessage(msg Message) error {
+	doc := webhookDocument{
+		Title:          msg.Title,
+		Body:           msg.Body,
+		Source:         msg.Source,
+		Event:          msg.EventType,
+		CWD:            msg.CWD,
+		Model:          msg.Model,
+		TranscriptPath: msg.TranscriptPath,
+	}
+	if err := postJSON(n.client, n.url, n.headers, doc); err != nil {
+		return fmt.Errorf("send webhook notification: %w", err)
+	}
+	return nil
+}
+
+// postJSON sends value as a JSON POST and treats any non-2xx status as an error.
+func postJSON(client *http.Client, target string, headers map[string]string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cc-notify")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Webhook URLs often carry a secret token; keep it out of error text.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	return checkHTTPStatus(resp)
+}
+
+func checkHTTPStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	text := strings.TrimSpace(string(snippet))
+	if text == "" {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return fmt.Errorf("unexpected status %s: %s", resp.Status, text)
+}
+
+func validateHTTPURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("url must use http or https, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("url %q has no host", raw)
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,111 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_PostsJSONDocument(t *testing.T) {
+	var gotDoc map[string]string
+	var gotHeader, gotContentType, gotMethod string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotHeader = r.Header.Get("X-Team")
+		gotContentType = r.Header.Get("Content-Type")
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &gotDoc)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	svc, err := NewWebhook(WebhookConfig{URL: server.URL + "/hook", Headers: map[string]string{"X-Team": "infra"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = Send(svc, Message{
+		Title:          "Codex Task Complete",
+		Body:           "done",
+		Source:         "codex",
+		EventType:      "agent-turn-complete",
+		CWD:            "/work/demo",
+		Model:          "gpt-5",
+		TranscriptPath: "/tmp/rollout.jsonl",
+	})
+	if err != nil {
+		t.Fatalf("unexpected send error: %v", err)
+	}
+
+	if gotMethod != http.MethodPost {
+		t.Fatalf("expected POST, got %s", gotMethod)
+	}
+	if gotContentType != "application/json" {
+		t.Fatalf("unexpected content type: %q", gotContentType)
+	}
+	if gotHeader != "infra" {
+		t.Fatalf("expected custom header, got %q", gotHeader)
+	}
+	want := map[string]string{
+		"title":           "Codex Task Complete",
+		"body":            "done",
+		"source":          "codex",
+		"event":           "agent-turn-complete",
+		"cwd":             "/work/demo",
+		"model":           "gpt-5",
+		"transcript_path": "/tmp/rollout.jsonl",
+	}
+	for key, value := range want {
+		if gotDoc[key] != value {
+			t.Fatalf("document field %s = %q, want %q (doc=%v)", key, gotDoc[key], value, gotDoc)
+		}
+	}
+}
+
+func TestWebhookNotifier_ReportsHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	svc, err := NewWebhook(WebhookConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = svc.Notify("title", "body")
+	if err == nil || !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
+
+func TestWebhookNotifier_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	svc, err := NewWebhook(WebhookConfig{URL: server.URL + "/hooks/secret-token", Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = svc.Notify("title", "body")
+	if err == nil {
+		t.Fatalf("expected timeout error")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("error leaks webhook url: %v", err)
+	}
+}
+
+func TestNewWebhook_ValidatesURL(t *testing.T) {
+	for _, raw := range []string{"", "ftp://example.com", "http://", "://bad"} {
+		if _, err := NewWebhook(WebhookConfig{URL: raw}); err == nil {
+			t.Fatalf("expected error for url %q", raw)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+//go:build linux
+
+package notifier
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// wslPowerShellPath is where powershell.exe lives when the Windows PATH is
+// not appended inside WSL (appendWindowsPath=false in wsl.conf).
+const wslPowerShellPath = "/mnt/c/Windows/System32/WindowsPowerShell/v1.0/powershell.exe"
+
+// WSLHost describes the Windows side of a WSL distribution.
+type WSLHost struct {
+	// Distro is the distribution name from WSL_DISTRO_NAME; empty means
+	// the default distribution.
+	Distro string
+	// PowerShell is the Windows powershell.exe reachable through interop.
+	PowerShell string
+}
+
+// DetectWSL reports the Windows host when running inside WSL. ok is false
+// outside WSL or when Windows interop cannot reach powershell.exe.
+func DetectWSL() (WSLHost, bool) {
+	return detectWSL(os.Getenv, os.ReadFile, exec.LookPath)
+}
+
+func detectWSL(getenv func(string) string, readFile func(string) ([]byte, error), lookPath func(string) (string, error)) (WSLHost, bool) {
+	distro := strings.TrimSpace(getenv("WSL_DISTRO_NAME"))
+	if distro == "" && getenv("WSL_INTEROP") == "" {
+		version, err := readFile("/proc/version")
+		if err != nil {
+			return WSLHost{}, false
+		}
+		lower := strings.ToLower(string(version))
+		if !strings.Contains(lower, "microsoft") && !strings.Contains(lower, "wsl") {
+			return WSLHost{}, false
+		}
+	}
+	for _, candidate := range []string{"powershell.exe", wslPowerShellPath} {
+		if path, err := lookPath(candidate); err == nil {
+			return WSLHost{Distro: distro, PowerShell: path}, true
+		}
+	}
+	return WSLHost{}, false
+}
+
+// newWSLNotifier sends Windows toasts and popups from inside WSL by running
+// the same PowerShell scripts through interop. Action buttons go through the
+// Windows cc-notify:// protocol handler, which `cc-notify install` points
+// back at this distribution.
+func newWSLNotifier(host WSLHost, cfg Config) Service {
+	n := newPowerShellNotifier(host.PowerShell, cfg)
+	n.hostPath = wslWindowsPath
+	return n
+}
+
+// wslWindowsPath returns the Windows path of a file inside WSL, or "" when
+// wslpath cannot convert it.
+func wslWindowsPath(path string) string {
+	output, err := exec.Command("wslpath", "-w", path).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
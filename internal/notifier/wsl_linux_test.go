@@ -0,0 +1,111 @@
+//go:build linux
+
+package notifier
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// fakeWSLEnv stubs the environment, /proc/version and an executable lookup
+// that maps names to resolved paths.
+func fakeWSLEnv(env map[string]string, procVersion string, exes map[string]string) (func(string) string, func(string) ([]byte, error), func(string) (string, error)) {
+	getenv := func(key string) string { return env[key] }
+	readFile := func(path string) ([]byte, error) {
+		if path != "/proc/version" || procVersion == "" {
+			return nil, os.ErrNotExist
+		}
+		return []byte(procVersion), nil
+	}
+	lookPath := func(name string) (string, error) {
+		if path, ok := exes[name]; ok {
+			return path, nil
+		}
+		return "", errors.New("not found")
+	}
+	return getenv, readFile, lookPath
+}
+
+func TestDetectWSL(t *testing.T) {
+	const interopPath = "/mnt/c/WINDOWS/System32/WindowsPowerShell/v1.0/powershell.exe"
+	cases := []struct {
+		name        string
+		env         map[string]string
+		procVersion string
+		exes        map[string]string
+		want        WSLHost
+		ok          bool
+	}{
+		{
+			name: "distro env with windows path",
+			env:  map[string]string{"WSL_DISTRO_NAME": "Ubuntu-24.04"},
+			exes: map[string]string{"powershell.exe": interopPath},
+			want: WSLHost{Distro: "Ubuntu-24.04", PowerShell: interopPath},
+			ok:   true,
+		},
+		{
+			name:        "proc version without windows path falls back to the mount",
+			procVersion: "Linux version 5.15.153.1-microsoft-standard-WSL2 (root@1) #1 SMP",
+			exes:        map[string]string{wslPowerShellPath: wslPowerShellPath},
+			want:        WSLHost{PowerShell: wslPowerShellPath},
+			ok:          true,
+		},
+		{
+			name: "wsl with interop disabled",
+			env:  map[string]string{"WSL_DISTRO_NAME": "Debian"},
+		},
+		{
+			name:        "plain linux",
+			procVersion: "Linux version 6.8.0-45-generic (buildd@lcy02-amd64-075) #45-Ubuntu SMP",
+			exes:        map[string]string{"powershell.exe": interopPath},
+		},
+	}
+	for _, tc := range cases {
+		getenv, readFile, lookPath := fakeWSLEnv(tc.env, tc.procVersion, tc.exes)
+		got, ok := detectWSL(getenv, readFile, lookPath)
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("%s: got %+v, %v; want %+v, %v", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestWSLNotifier_RunsToastScriptThroughInterop(t *testing.T) {
+	host := WSLHost{Distro: "Ubuntu", PowerShell: wslPowerShellPath}
+	n := newWSLNotifier(host, Config{Mode: "toast"}).(*windowsNotifier)
+	runner := &captureRunner{}
+	n.runner = runner
+
+	actions := []Action{{Label: "Yes", URI: "cc-notify://respond?decision=proceed&id=0123456789abcdef"}}
+	if err := n.NotifyWithActions("Codex Needs Approval", "Run `ls`?", actions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runner.name != wslPowerShellPath || runner.call != 1 {
+		t.Fatalf("expected one powershell.exe run through interop, got %q x%d", runner.name, runner.call)
+	}
+	script := decodeEncodedCommand(runner.args)
+	toastXML, err := ToastForMessage(Message{Title: "Codex Needs Approval", Body: "Run `ls`?", Actions: actions}, ToastOptions{}).XML()
+	if err != nil {
+		t.Fatalf("unexpected toast error: %v", err)
+	}
+	want := buildToastXMLScript(toastXML, defaultToastAppID, "", "")
+	if script != want {
+		t.Fatalf("expected the Windows toast script unchanged, got %q", script)
+	}
+}
+
+func TestWSLNotifier_PopupMode(t *testing.T) {
+	n := newWSLNotifier(WSLHost{PowerShell: "powershell.exe"}, Config{Mode: "popup", ToastAppID: "Custom.App"}).(*windowsNotifier)
+	runner := &captureRunner{}
+	n.runner = runner
+
+	if err := n.Notify("title", "body"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if script := decodeEncodedCommand(runner.args); script != buildPopupScript("title", "body") {
+		t.Fatalf("expected popup script, got %q", script)
+	}
+	if n.appID != "Custom.App" {
+		t.Fatalf("expected configured app id, got %q", n.appID)
+	}
+}
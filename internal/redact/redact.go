@@ -0,0 +1,183 @@
+// Package redact hides secrets in notification text before it leaves the
+// machine.
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces every secret found.
+const Mask = "[REDACTED]"
+
+// Level selects how much is hidden.
+type Level int
+
+const (
+	// Off leaves text alone.
+	Off Level = iota
+	// Standard hides known secret formats and the user's patterns.
+	Standard
+	// Strict also hides high-entropy strings and the values of
+	// environment variable assignments.
+	Strict
+)
+
+// ParseLevel parses "off", "standard" or "strict". Empty gives def.
+func ParseLevel(raw string, def Level) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+		return def, nil
+	case "off", "none":
+		return Off, nil
+	case "standard":
+		return Standard, nil
+	case "strict":
+		return Strict, nil
+	}
+	return def, fmt.Errorf("unknown redaction level %q (use off, standard or strict)", raw)
+}
+
+func (l Level) String() string {
+	switch l {
+	case Off:
+		return "off"
+	case Strict:
+		return "strict"
+	default:
+		return "standard"
+	}
+}
+
+// detector finds one kind of secret. Only the submatch group is masked, so
+// names and delimiters around the secret stay readable; group 0 masks the
+// whole match.
+type detector struct {
+	name  string
+	re    *regexp.Regexp
+	group int
+	level Level
+}
+
+// detectors run in order: specific formats first, so that generic rules
+// do not leave half a token behind.
+var detectors = []detector{
+	{name: "private key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----|[\s\S]*)`), level: Standard},
+	{name: "url credentials", re: regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9+.-]*://[^\s:/@]*:([^\s@/]+)@`), group: 1, level: Standard},
+	{name: "aws access key", re: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`), level: Standard},
+	{name: "github token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), level: Standard},
+	{name: "anthropic key", re: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`), level: Standard},
+	{name: "openai key", re: regexp.MustCompile(`\bsk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}`), level: Standard},
+	{name: "slack token", re: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`), level: Standard},
+	{name: "google api key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`), level: Standard},
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`), level: Standard},
+	{name: "authorization header", re: regexp.MustCompile(`(?i)\b(?:bearer|basic)\s+([A-Za-z0-9._~+/=-]{16,})`), group: 1, level: Standard},
+	{name: "secret assignment", re: regexp.MustCompile(`(?i)\b[A-Za-z0-9_.-]*(?:password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key)(?:[_.-][A-Za-z0-9_.-]*)?["']?\s*[:=]\s*("[^"\n]*"|'[^'\n]*'|[^\s"',;]+)`), group: 1, level: Standard},
+	{name: "environment assignment", re: regexp.MustCompile(`\b[A-Z][A-Z0-9_]{2,}=("[^"\n]*"|'[^'\n]*'|[^\s"',;]+)`), group: 1, level: Strict},
+}
+
+// candidate matches the strings the high-entropy check looks at.
+var candidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+// Redactor hides secrets in text.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// New builds a Redactor with user patterns, regular expressions whose
+// matches are hidden at the standard and strict levels. Invalid patterns
+// are skipped and reported together; the Redactor still works without
+// them.
+func New(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	var errs []error
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("redact pattern %q: %w", pattern, err))
+			continue
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, errors.Join(errs...)
+}
+
+// Redact returns text with the secrets found at level replaced by Mask. A
+// nil Redactor applies the built-in detectors only.
+func (r *Redactor) Redact(text string, level Level) string {
+	if level == Off || text == "" {
+		return text
+	}
+	for _, d := range detectors {
+		if d.level <= level {
+			text = maskGroup(d.re, text, d.group)
+		}
+	}
+	if r != nil {
+		for _, re := range r.patterns {
+			text = maskGroup(re, text, 0)
+		}
+	}
+	if level >= Strict {
+		text = candidate.ReplaceAllStringFunc(text, func(s string) string {
+			if looksRandom(s) {
+				return Mask
+			}
+			return s
+		})
+	}
+	return text
+}
+
+// maskGroup replaces submatch group of every match of re with Mask.
+func maskGroup(re *regexp.Regexp, text string, group int) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var out strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 || end <= start {
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(Mask)
+		last = end
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+// looksRandom reports whether s is likely a key rather than a word or path:
+// it mixes letters and digits and has high Shannon entropy.
+func looksRandom(s string) bool {
+	var letters, digits int
+	counts := map[rune]int{}
+	for _, c := range s {
+		counts[c]++
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			letters++
+		}
+	}
+	if letters == 0 || digits == 0 {
+		return false
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 3.5
+}
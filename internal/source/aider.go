@@ -0,0 +1,70 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"cc-notify/internal/config"
+	"cc-notify/internal/event"
+)
+
+// Aider is the adapter for Aider, which runs notifications-command from
+// .aider.conf.yml without arguments whenever it waits for input.
+type Aider struct{}
+
+func (Aider) Name() string        { return "aider" }
+func (Aider) DisplayName() string { return "Aider" }
+func (Aider) HookName() string    { return "notifications command" }
+
+// ParsePayload builds a turn-complete event for the directory Aider runs
+// in. The prompt and reply come from the chat history there, when Aider
+// keeps it in the default place.
+func (Aider) ParsePayload(in Input) (event.Payload, error) {
+	payload := event.Payload{Type: "agent-turn-complete"}
+	if in.Getwd == nil {
+		return payload, nil
+	}
+	cwd, err := in.Getwd()
+	if err != nil {
+		return event.Payload{}, fmt.Errorf("resolve aider directory: %w", err)
+	}
+	payload.CWD = cwd
+
+	history, err := event.ReadAiderHistory(filepath.Join(cwd, event.AiderHistoryFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		warn(in, err)
+	default:
+		payload.LastAssistantMessage = history.LastAssistantMessage
+		payload.LastUserPrompt = history.LastUserPrompt
+	}
+	return payload, nil
+}
+
+func (Aider) NormalizeEvent(eventType string) string { return eventType }
+
+func (Aider) ConfigPath() (string, error) { return config.AiderDefaultPath() }
+
+func (Aider) Install(content string, opts InstallOptions) (string, bool, error) {
+	return config.AiderUpsertNotifications(content, opts.ExePath)
+}
+
+func (Aider) Uninstall(content string) (string, bool, error) {
+	return config.AiderRemoveNotifications(content)
+}
+
+func (Aider) Title(eventType string) string {
+	if eventType == "agent-turn-complete" {
+		return "Aider Is Waiting for You"
+	}
+	return ""
+}
+
+// Detected reports whether the Aider config file exists.
+func (Aider) Detected(configPath string) bool {
+	info, err := os.Stat(configPath)
+	return err == nil && !info.IsDir()
+}
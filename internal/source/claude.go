@@ -0,0 +1,87 @@
+package source
+
+import (
+	"strings"
+
+	"cc-notify/internal/config"
+	"cc-notify/internal/event"
+)
+
+// Claude is the adapter for Claude Code, whose hooks in
+// ~/.claude/settings.json pass their input as JSON on stdin.
+type Claude struct{}
+
+func (Claude) Name() string        { return "claude" }
+func (Claude) DisplayName() string { return "Claude Code" }
+func (Claude) HookName() string    { return "hook" }
+
+// ParsePayload converts the hook input named by hook_event_name, e.g.
+//
+//	{
+//	  "hook_event_name": "Notification",
+//	  "notification_type": "permission_prompt",
+//	  "message": "Claude needs your permission to use Bash",
+//	  "session_id": "...",
+//	  "transcript_path": "...",
+//	  "cwd": "..."
+//	}
+//
+// For Stop, the reply and prompt of the turn are read from the transcript.
+func (Claude) ParsePayload(in Input) (event.Payload, error) {
+	raw, err := readStdin(in, "claude")
+	if err != nil {
+		return event.Payload{}, err
+	}
+	hook, err := event.ParseClaudeHook(raw)
+	if err != nil {
+		return event.Payload{}, err
+	}
+	payload := hook.Payload()
+
+	// The Stop hook does not carry Claude's reply; the transcript does.
+	if payload.Type == "agent-turn-complete" && payload.TranscriptPath != "" {
+		transcript, err := event.ReadClaudeTranscript(payload.TranscriptPath)
+		if err != nil {
+			warn(in, err)
+		} else {
+			payload.LastAssistantMessage = transcript.LastAssistantMessage
+			payload.LastUserPrompt = transcript.LastUserPrompt
+			if strings.TrimSpace(payload.Model) == "" {
+				payload.Model = transcript.Model
+			}
+		}
+	}
+	return payload, nil
+}
+
+// NormalizeEvent accepts the pause spellings of wrappers that relay
+// Claude Code permission requests.
+func (Claude) NormalizeEvent(eventType string) string {
+	switch strings.ToLower(strings.TrimSpace(eventType)) {
+	case "paused", "agent-paused", "permission-request", "approval-required":
+		return "agent-turn-paused"
+	default:
+		return eventType
+	}
+}
+
+func (Claude) ConfigPath() (string, error) { return config.ClaudeDefaultPath() }
+
+func (Claude) Install(content string, opts InstallOptions) (string, bool, error) {
+	events := installEvents(opts, event.DefaultClaudeHookEvents, event.ClaudeUserPromptSubmit)
+	return config.ClaudeUpsertHookWithTimeout(content, opts.ExePath, int(opts.ApprovalTimeout.Seconds()), events...)
+}
+
+func (Claude) Uninstall(content string) (string, bool, error) {
+	return config.ClaudeRemoveHook(content)
+}
+
+// Claude Code adds the stdout of some hooks, such as UserPromptSubmit and
+// SessionStart, to the conversation.
+func (Claude) ReadsStdout() bool { return true }
+
+func (Claude) TurnStartEvent() string { return event.ClaudeUserPromptSubmit }
+
+func (Claude) NormalizeEvents(names []string) ([]string, []string) {
+	return event.NormalizeClaudeHookEvents(names)
+}
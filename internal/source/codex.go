@@ -0,0 +1,70 @@
+package source
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"cc-notify/internal/config"
+	"cc-notify/internal/event"
+)
+
+// Codex is the adapter for Codex CLI, which runs the notify command of
+// ~/.codex/config.toml with the event JSON as its last argument.
+type Codex struct{}
+
+func (Codex) Name() string        { return "codex" }
+func (Codex) DisplayName() string { return "Codex" }
+func (Codex) HookName() string    { return "notify command" }
+
+// ParsePayload reads the payload from the arguments: JSON as is, or from
+// a file with --file <path> or base64 encoded with --b64 <payload>.
+func (Codex) ParsePayload(in Input) (event.Payload, error) {
+	raw, err := resolveCodexPayload(in)
+	if err != nil {
+		return event.Payload{}, err
+	}
+	return event.ParsePayload(raw)
+}
+
+func resolveCodexPayload(in Input) (string, error) {
+	args := in.Args
+	if len(args) == 0 {
+		return "", fmt.Errorf("notify payload argument is required")
+	}
+
+	switch args[0] {
+	case "--file":
+		if len(args) < 2 {
+			return "", fmt.Errorf("notify --file requires a path argument")
+		}
+		data, err := in.ReadFile(args[1])
+		if err != nil {
+			return "", fmt.Errorf("read notify payload file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case "--b64":
+		if len(args) < 2 {
+			return "", fmt.Errorf("notify --b64 requires a base64 payload argument")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(args[1]))
+		if err != nil {
+			return "", fmt.Errorf("decode notify base64 payload: %w", err)
+		}
+		return strings.TrimSpace(string(decoded)), nil
+	default:
+		return strings.TrimSpace(strings.Join(args, " ")), nil
+	}
+}
+
+func (Codex) NormalizeEvent(eventType string) string { return eventType }
+
+func (Codex) ConfigPath() (string, error) { return config.DefaultPath() }
+
+func (Codex) Install(content string, opts InstallOptions) (string, bool, error) {
+	return config.UpsertNotify(content, []string{opts.ExePath, "notify"})
+}
+
+func (Codex) Uninstall(content string) (string, bool, error) {
+	return config.RemoveNotify(content)
+}
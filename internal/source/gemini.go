@@ -0,0 +1,77 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+
+	"cc-notify/internal/config"
+	"cc-notify/internal/event"
+)
+
+// Gemini is the adapter for Gemini CLI, whose hooks in
+// ~/.gemini/settings.json take the Claude Code format and pass their input
+// as JSON on stdin.
+type Gemini struct{}
+
+func (Gemini) Name() string        { return "gemini" }
+func (Gemini) DisplayName() string { return "Gemini CLI" }
+func (Gemini) HookName() string    { return "hook" }
+
+// ParsePayload converts the hook input. AfterAgent carries the prompt and
+// the reply of the turn itself.
+func (Gemini) ParsePayload(in Input) (event.Payload, error) {
+	raw, err := readStdin(in, "gemini")
+	if err != nil {
+		return event.Payload{}, err
+	}
+	hook, err := event.ParseGeminiHook(raw)
+	if err != nil {
+		return event.Payload{}, err
+	}
+	return hook.Payload(), nil
+}
+
+func (Gemini) NormalizeEvent(eventType string) string { return eventType }
+
+func (Gemini) ConfigPath() (string, error) { return config.GeminiDefaultPath() }
+
+func (Gemini) Install(content string, opts InstallOptions) (string, bool, error) {
+	return config.GeminiUpsertHook(content, opts.ExePath, installEvents(opts, event.DefaultGeminiHookEvents, event.GeminiBeforeAgent)...)
+}
+
+func (Gemini) Uninstall(content string) (string, bool, error) {
+	return config.GeminiRemoveHook(content)
+}
+
+// Gemini CLI parses hook stdout as a JSON decision.
+func (Gemini) ReadsStdout() bool { return true }
+
+func (Gemini) TurnStartEvent() string { return event.GeminiBeforeAgent }
+
+func (Gemini) NormalizeEvents(names []string) ([]string, []string) {
+	return event.NormalizeGeminiHookEvents(names)
+}
+
+// geminiTitles name the events Gemini CLI hooks send.
+var geminiTitles = map[string]string{
+	"agent-turn-complete":       "Gemini Task Complete",
+	event.TypeAgentNotification: "Gemini CLI Notification",
+	event.TypeContextCompact:    "Gemini Is Compressing Context",
+	event.TypeSessionStart:      "Gemini Session Started",
+	event.TypeSessionEnd:        "Gemini Session Ended",
+	event.TypePromptSubmit:      "Gemini Prompt Submitted",
+	event.TypePreToolUse:        "Gemini Is Using a Tool",
+	event.TypePostToolUse:       "Gemini Tool Finished",
+}
+
+func (Gemini) Title(eventType string) string { return geminiTitles[eventType] }
+
+// Detected reports whether Gemini CLI has created its settings directory.
+func (Gemini) Detected(configPath string) bool {
+	return dirExists(filepath.Dir(configPath))
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,189 @@
+// Package source describes the coding agents cc-notify takes events from:
+// how each one hands an event to `cc-notify notify`, how its hook is
+// installed and what its events are called.
+package source
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"cc-notify/internal/event"
+)
+
+// Adapter connects cc-notify to one coding agent.
+type Adapter interface {
+	// Name identifies the agent on the command line, in settings, routes
+	// and templates, e.g. "codex" for `notify --codex`.
+	Name() string
+	// DisplayName is the agent's name as shown to the user.
+	DisplayName() string
+	// HookName is what the installed hook is called, e.g. "notify
+	// command" or "hook".
+	HookName() string
+	// ParsePayload reads the event the agent passed to notify.
+	ParsePayload(in Input) (event.Payload, error)
+	// NormalizeEvent maps the agent's spellings of an event type onto the
+	// cc-notify ones, such as agent-turn-paused.
+	NormalizeEvent(eventType string) string
+	// ConfigPath returns the agent config file the hook lives in.
+	ConfigPath() (string, error)
+	// Install adds the hook to the config content. changed is false when
+	// it was configured already.
+	Install(content string, opts InstallOptions) (updated string, changed bool, err error)
+	// Uninstall removes the hook from the config content.
+	Uninstall(content string) (updated string, changed bool, err error)
+}
+
+// Input is what notify received from the agent.
+type Input struct {
+	// Args are the arguments after the adapter flag.
+	Args  []string
+	Stdin io.Reader
+	// ReadFile reads payload files named on the command line.
+	ReadFile func(string) ([]byte, error)
+	// Getwd returns the directory notify runs in, for agents that do not
+	// report theirs.
+	Getwd func() (string, error)
+	// Warnings receives problems that only cost part of the payload, such
+	// as an unreadable transcript.
+	Warnings io.Writer
+}
+
+// InstallOptions controls how a hook is installed.
+type InstallOptions struct {
+	// ExePath is the absolute path of the cc-notify executable.
+	ExePath string
+	// Events are the hook events to register with agents that have
+	// several; empty means the adapter's defaults.
+	Events []string
+	// TurnStart also registers the event of a TurnStarter, for the
+	// snapshot the change summary is taken from.
+	TurnStart bool
+	// ApprovalTimeout is how long hooks that wait for the user to approve
+	// a tool call may run; zero keeps the agent's default.
+	ApprovalTimeout time.Duration
+}
+
+// Titler is implemented by adapters that name events differently from
+// Codex, whose titles are the defaults.
+type Titler interface {
+	// Title returns the English title of eventType, or "" for the default.
+	Title(eventType string) string
+}
+
+// StdoutReader is implemented by adapters whose agent reads the stdout of
+// the hook, e.g. to add it to the conversation. notify reports on stderr
+// for them.
+type StdoutReader interface {
+	ReadsStdout() bool
+}
+
+// EventSelector is implemented by adapters whose hook can be registered
+// for a choice of events.
+type EventSelector interface {
+	// NormalizeEvents returns the known events of names, canonically
+	// spelled, and the unknown ones.
+	NormalizeEvents(names []string) (events []string, unknown []string)
+}
+
+// TurnStarter is implemented by adapters whose agent has a hook event for
+// the start of a turn, sent as a prompt-submit event. Without one, the
+// turn is taken to start with the first event seen after the last one
+// completed.
+type TurnStarter interface {
+	// TurnStartEvent returns the name of the hook event.
+	TurnStartEvent() string
+}
+
+// Detector is implemented by adapters that a bare `install` only sets up
+// when the agent appears to be in use.
+type Detector interface {
+	// Detected reports whether the agent is in use, given its config path.
+	Detected(configPath string) bool
+}
+
+// adapters lists every supported agent; the first is the default.
+var adapters = []Adapter{Codex{}, Claude{}, Gemini{}, Aider{}}
+
+// All returns every adapter in display order.
+func All() []Adapter {
+	return append([]Adapter(nil), adapters...)
+}
+
+// Default returns the adapter used when notify names none.
+func Default() Adapter {
+	return adapters[0]
+}
+
+// Lookup returns the adapter named name, ignoring case.
+func Lookup(name string) (Adapter, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, a := range adapters {
+		if a.Name() == name {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of every adapter.
+func Names() []string {
+	names := make([]string, 0, len(adapters))
+	for _, a := range adapters {
+		names = append(names, a.Name())
+	}
+	return names
+}
+
+// Title returns the English title a gives eventType, or "" for the default.
+func Title(a Adapter, eventType string) string {
+	if t, ok := a.(Titler); ok {
+		return t.Title(eventType)
+	}
+	return ""
+}
+
+// ReadsStdout reports whether the agent behind a reads the hook's stdout.
+func ReadsStdout(a Adapter) bool {
+	r, ok := a.(StdoutReader)
+	return ok && r.ReadsStdout()
+}
+
+// installEvents returns the hook events Install registers: the chosen
+// ones or defaults, with start added when opts asks for it.
+func installEvents(opts InstallOptions, defaults []string, start string) []string {
+	events := opts.Events
+	if len(events) == 0 {
+		events = defaults
+	}
+	if opts.TurnStart && !slices.Contains(events, start) {
+		events = append(slices.Clone(events), start)
+	}
+	return events
+}
+
+// readStdin reads hook input from in.Stdin for agent name.
+func readStdin(in Input, name string) (string, error) {
+	if in.Stdin == nil {
+		return "", fmt.Errorf("empty %s hook input", name)
+	}
+	data, err := io.ReadAll(in.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("read %s hook stdin: %w", name, err)
+	}
+	raw := strings.TrimSpace(string(data))
+	if raw == "" {
+		return "", fmt.Errorf("empty %s hook input", name)
+	}
+	return raw, nil
+}
+
+// warn reports a problem that only costs part of the payload.
+func warn(in Input, err error) {
+	if in.Warnings != nil {
+		fmt.Fprintf(in.Warnings, "warning: %v\n", err)
+	}
+}
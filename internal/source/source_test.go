@@ -0,0 +1,175 @@
+package source
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cc-notify/internal/event"
+)
+
+func TestLookup(t *testing.T) {
+	if !reflect.DeepEqual(Names(), []string{"codex", "claude", "gemini", "aider"}) {
+		t.Fatalf("unexpected adapters: %v", Names())
+	}
+	if a, ok := Lookup(" Gemini "); !ok || a.Name() != "gemini" {
+		t.Fatalf("expected gemini adapter, got %v", a)
+	}
+	if _, ok := Lookup("cursor"); ok {
+		t.Fatalf("expected unknown adapter")
+	}
+	if Default().Name() != "codex" {
+		t.Fatalf("expected codex as default, got %s", Default().Name())
+	}
+}
+
+func TestCodexParsePayload(t *testing.T) {
+	raw := `{"type":"agent-turn-complete","summary":"done"}`
+	path := filepath.Join(t.TempDir(), "payload.json")
+	if err := os.WriteFile(path, []byte(raw), 0o644); err != nil {
+		t.Fatalf("write payload: %v", err)
+	}
+	for _, args := range [][]string{
+		{raw},
+		{"--file", path},
+		{"--b64", base64.StdEncoding.EncodeToString([]byte(raw))},
+	} {
+		payload, err := Codex{}.ParsePayload(Input{Args: args, ReadFile: os.ReadFile})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if payload.Type != "agent-turn-complete" || payload.Summary != "done" {
+			t.Fatalf("%v: unexpected payload %+v", args, payload)
+		}
+	}
+	if _, err := (Codex{}).ParsePayload(Input{}); err == nil {
+		t.Fatalf("expected error without payload")
+	}
+}
+
+func TestClaudeParsePayload(t *testing.T) {
+	in := Input{Stdin: strings.NewReader(`{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs your permission to use Bash","cwd":"/home/dev/api"}`)}
+	payload, err := Claude{}.ParsePayload(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Type != "agent-turn-paused" || payload.CWD != "/home/dev/api" {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	var warnings bytes.Buffer
+	in = Input{
+		Stdin:    strings.NewReader(`{"hook_event_name":"Stop","session_id":"s-1","transcript_path":"` + filepath.ToSlash(filepath.Join(t.TempDir(), "missing.jsonl")) + `"}`),
+		Warnings: &warnings,
+	}
+	payload, err = Claude{}.ParsePayload(in)
+	if err != nil || payload.Type != "agent-turn-complete" {
+		t.Fatalf("expected Stop payload despite missing transcript, got %+v, %v", payload, err)
+	}
+	if !strings.Contains(warnings.String(), "warning: read claude transcript") {
+		t.Fatalf("expected transcript warning, got %q", warnings.String())
+	}
+
+	if _, err := (Claude{}).ParsePayload(Input{Stdin: strings.NewReader("  ")}); err == nil {
+		t.Fatalf("expected error for empty input")
+	}
+	if got := (Claude{}).NormalizeEvent("Permission-Request"); got != "agent-turn-paused" {
+		t.Fatalf("unexpected normalized event %q", got)
+	}
+	if got := (Codex{}).NormalizeEvent("permission-request"); got != "permission-request" {
+		t.Fatalf("codex events should be kept, got %q", got)
+	}
+}
+
+func TestAiderParsePayload(t *testing.T) {
+	dir := t.TempDir()
+	history := "#### fix the flaky test\n\nThe test raced on the shared map.\n\n> Applied edit to cache_test.go\n"
+	if err := os.WriteFile(filepath.Join(dir, event.AiderHistoryFile), []byte(history), 0o644); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+	payload, err := Aider{}.ParsePayload(Input{Getwd: func() (string, error) { return dir, nil }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := event.Payload{
+		Type:                 "agent-turn-complete",
+		CWD:                  dir,
+		LastAssistantMessage: "The test raced on the shared map.",
+		LastUserPrompt:       "fix the flaky test",
+	}
+	if payload != want {
+		t.Fatalf("unexpected payload:\n got %+v\nwant %+v", payload, want)
+	}
+
+	empty := t.TempDir()
+	payload, err = Aider{}.ParsePayload(Input{Getwd: func() (string, error) { return empty, nil }})
+	if err != nil || payload.CWD != empty || payload.LastAssistantMessage != "" {
+		t.Fatalf("expected payload without history, got %+v, %v", payload, err)
+	}
+}
+
+func TestAdapters_InstallAndUninstall(t *testing.T) {
+	exe := "/opt/cc-notify/cc-notify"
+	for _, a := range All() {
+		installed, changed, err := a.Install("", InstallOptions{ExePath: exe})
+		if err != nil || !changed {
+			t.Fatalf("%s: install changed=%v err=%v", a.Name(), changed, err)
+		}
+		if !strings.Contains(installed, exe) {
+			t.Fatalf("%s: expected command in config: %s", a.Name(), installed)
+		}
+		removed, changed, err := a.Uninstall(installed)
+		if err != nil || !changed {
+			t.Fatalf("%s: uninstall changed=%v err=%v", a.Name(), changed, err)
+		}
+		if strings.Contains(removed, "cc-notify") {
+			t.Fatalf("%s: expected command removed: %s", a.Name(), removed)
+		}
+	}
+}
+
+func TestGeminiInstall_Events(t *testing.T) {
+	installed, _, err := Gemini{}.Install("", InstallOptions{ExePath: "/opt/cc-notify/cc-notify", Events: []string{"sessionend"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(installed, `"SessionEnd"`) || strings.Contains(installed, `"AfterAgent"`) {
+		t.Fatalf("expected only SessionEnd hook: %s", installed)
+	}
+	events, unknown := Gemini{}.NormalizeEvents([]string{"afteragent", "Stop"})
+	if !reflect.DeepEqual(events, []string{"AfterAgent"}) || !reflect.DeepEqual(unknown, []string{"Stop"}) {
+		t.Fatalf("unexpected events %v / %v", events, unknown)
+	}
+}
+
+func TestInstall_TurnStart(t *testing.T) {
+	installed, _, err := Claude{}.Install("", InstallOptions{ExePath: "/opt/cc-notify/cc-notify", TurnStart: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{`"Stop"`, `"Notification"`, `"UserPromptSubmit"`} {
+		if !strings.Contains(installed, name) {
+			t.Fatalf("expected %s hook next to the defaults: %s", name, installed)
+		}
+	}
+	installed, _, err = Gemini{}.Install("", InstallOptions{ExePath: "/opt/cc-notify/cc-notify", Events: []string{"AfterAgent", "BeforeAgent"}, TurnStart: true})
+	if err != nil || strings.Count(installed, `"BeforeAgent"`) != 1 {
+		t.Fatalf("expected a single BeforeAgent hook, got %v: %s", err, installed)
+	}
+}
+
+func TestTitlesAndStdout(t *testing.T) {
+	if got := Title(Gemini{}, "agent-turn-complete"); got != "Gemini Task Complete" {
+		t.Fatalf("unexpected gemini title %q", got)
+	}
+	if got := Title(Codex{}, "agent-turn-complete"); got != "" {
+		t.Fatalf("codex should use default titles, got %q", got)
+	}
+	if !ReadsStdout(Claude{}) || !ReadsStdout(Gemini{}) || ReadsStdout(Codex{}) || ReadsStdout(Aider{}) {
+		t.Fatalf("unexpected stdout readers")
+	}
+}